@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// BotProvider is the provider of the identities of bots, which authenticate with an API key.
+const BotProvider = "bot"
+
+const apiKeySize = 32
+
+// BotStore holds the bots (implemented by the persisters).
+type BotStore interface {
+	GetBot(*types.Bot) error
+}
+
+// NewAPIKey returns a new API key for the bot user and its hash, which is stored instead of the key (see
+// types.Bot.KeyHash). The key starts with the encoded user id, so that the bot can be looked up.
+func NewAPIKey(userId string) (key, hash string, err error) {
+	secret := make([]byte, apiKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = base64.RawURLEncoding.EncodeToString([]byte(userId)) + "." + base64.RawURLEncoding.EncodeToString(secret)
+	return key, hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// AuthenticateAPIKey verifies the API key of a bot. It returns the identity of the bot, or nil if there is no key.
+func (r *Registry) AuthenticateAPIKey(key string) (*Identity, error) {
+	if r == nil || key == "" {
+		return nil, nil
+	}
+	if r.bots == nil {
+		return nil, fmt.Errorf("bots require persistence")
+	}
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed api key")
+	}
+	userId, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed api key: %w", err)
+	}
+	bot := &types.Bot{UserId: string(userId)}
+	if err := r.bots.GetBot(bot); err != nil {
+		return nil, fmt.Errorf("unknown bot %s: %w", bot.UserId, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(bot.KeyHash), []byte(hashAPIKey(key))) != 1 {
+		return nil, fmt.Errorf("invalid api key for bot %s", bot.UserId)
+	}
+	return &Identity{Provider: BotProvider, UserId: bot.UserId, Bot: bot}, nil
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// fakeStore holds the revoked tokens and the bots.
+type fakeStore struct {
+	fakeDenyList
+	bots map[string]types.Bot
+}
+
+func (f fakeStore) GetBot(bot *types.Bot) error {
+	b, ok := f.bots[bot.UserId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	*bot = b
+	return nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	key, hash, err := NewAPIKey("alerts")
+	if !assert.NoError(t, err) {
+		return
+	}
+	otherKey, _, err := NewAPIKey("alerts")
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+	store := fakeStore{bots: map[string]types.Bot{"alerts": {UserId: "alerts", KeyHash: hash, Events: "alert"}}}
+	registry, err := NewRegistry(&config.Config{}, store)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	identity, err := registry.AuthenticateAPIKey(key)
+	if assert.NoError(t, err) && assert.NotNil(t, identity) {
+		assert.Equal(t, BotProvider, identity.Provider)
+		assert.Equal(t, "alerts", identity.UserId)
+		if assert.NotNil(t, identity.Bot) {
+			assert.True(t, identity.Bot.AllowsEvent("alert"))
+			assert.False(t, identity.Bot.AllowsEvent(types.EventTypeChat))
+		}
+	}
+
+	_, err = registry.AuthenticateAPIKey(otherKey)
+	assert.Error(t, err, "the key was replaced")
+	_, err = registry.AuthenticateAPIKey(strings.Replace(key, ".", "", 1))
+	assert.Error(t, err, "malformed")
+	otherBot, _, err := NewAPIKey("other")
+	assert.NoError(t, err)
+	_, err = registry.AuthenticateAPIKey(otherBot)
+	assert.Error(t, err, "unknown bot")
+	identity, err = registry.AuthenticateAPIKey("")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+
+	registry, err = NewRegistry(&config.Config{}, nil)
+	assert.NoError(t, err)
+	_, err = registry.AuthenticateAPIKey(key)
+	assert.Error(t, err, "no bots without persistence")
+	_, err = NewRegistry(&config.Config{JWTConfigs: []config.JWTConfig{{Name: BotProvider, Secret: "secret"}}}, nil)
+	assert.Error(t, err, "reserved provider name")
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	// AllRooms is the room id in the roles claim of a local token which applies to all rooms.
+	AllRooms = "*"
+
+	jwtLeeway = time.Minute
+)
+
+// DenyList is the list of revoked tokens (implemented by the persisters).
+type DenyList interface {
+	IsTokenRevoked(string) (bool, error)
+}
+
+// TokenClaims are the claims of a local token in addition to the registered claims (sub is the user id, exp is
+// required, jti is used to revoke the token).
+type TokenClaims struct {
+	Nick     string            `json:"nick,omitempty"`
+	Language string            `json:"language,omitempty"`
+	Roles    map[string]string `json:"roles,omitempty"` // room id (or AllRooms) -> role
+}
+
+// A JWTProvider verifies local tokens which are signed by the embedding application with a configured key.
+type JWTProvider struct {
+	cfg       config.JWTConfig
+	algorithm jose.SignatureAlgorithm
+	verifyKey interface{}
+	signKey   interface{} // nil if the provider cannot mint tokens
+	denyList  DenyList
+}
+
+// NewJWTProvider reads the keys of the provider. denyList may be nil, then tokens cannot be revoked.
+func NewJWTProvider(cfg config.JWTConfig, denyList DenyList) (*JWTProvider, error) {
+	p := &JWTProvider{cfg: cfg, denyList: denyList}
+	switch cfg.Algorithm {
+	case "", string(jose.HS256):
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("jwt provider %s: missing secret", cfg.Name)
+		}
+		p.algorithm = jose.HS256
+		p.verifyKey = []byte(cfg.Secret)
+		p.signKey = []byte(cfg.Secret)
+	case string(jose.RS256):
+		p.algorithm = jose.RS256
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt provider %s: %w", cfg.Name, err)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt provider %s: %w", cfg.Name, err)
+		}
+		if _, ok := key.(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("jwt provider %s: no RSA public key", cfg.Name)
+		}
+		p.verifyKey = key
+		if cfg.PrivateKeyFile != "" {
+			block, err := readPEM(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("jwt provider %s: %w", cfg.Name, err)
+			}
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				pkcs8Key, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				if pkcs8Err != nil {
+					return nil, fmt.Errorf("jwt provider %s: %w", cfg.Name, err)
+				}
+				var ok bool
+				if key, ok = pkcs8Key.(*rsa.PrivateKey); !ok {
+					return nil, fmt.Errorf("jwt provider %s: no RSA private key", cfg.Name)
+				}
+			}
+			p.signKey = key
+		}
+	default:
+		return nil, fmt.Errorf("jwt provider %s: unsupported algorithm %s", cfg.Name, cfg.Algorithm)
+	}
+	return p, nil
+}
+
+func readPEM(fileName string) (*pem.Block, error) {
+	if fileName == "" {
+		return nil, fmt.Errorf("missing key file")
+	}
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", fileName)
+	}
+	return block, nil
+}
+
+// Authenticate verifies the signature, the expiry (and issuer and audience, if configured) of the token and checks
+// that it is not revoked.
+func (p *JWTProvider) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(p.algorithm) {
+		return nil, fmt.Errorf("unexpected signing algorithm")
+	}
+	claims := jwt.Claims{}
+	tokenClaims := TokenClaims{}
+	err = parsed.Claims(p.verifyKey, &claims, &tokenClaims)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("token without expiry")
+	}
+	expected := jwt.Expected{Issuer: p.cfg.Issuer, Time: time.Now()}
+	if p.cfg.Audience != "" {
+		expected.Audience = jwt.Audience{p.cfg.Audience}
+	}
+	err = claims.ValidateWithLeeway(expected, jwtLeeway)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token without subject")
+	}
+	id := tokenId(claims, token)
+	if err := p.checkRevoked(id); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider:  p.cfg.Name,
+		UserId:    p.cfg.Name + ":" + claims.Subject,
+		Nick:      tokenClaims.Nick,
+		TokenId:   id,
+		ExpiresAt: claims.Expiry.Time(),
+	}
+	if p.cfg.PlainIds {
+		identity.UserId = claims.Subject
+	}
+	if len(tokenClaims.Language) >= 2 {
+		identity.Language = strings.ToLower(tokenClaims.Language[:2])
+	}
+	for roomId, role := range tokenClaims.Roles {
+		if !assignableRole(role) {
+			return nil, fmt.Errorf("invalid role %s for room %s", role, roomId)
+		}
+	}
+	identity.RoomRoles = tokenClaims.Roles
+	return identity, nil
+}
+
+// checkRevoked returns an error if the token with the given id (see tokenId) is in the deny list.
+func (p *JWTProvider) checkRevoked(id string) error {
+	if p.denyList == nil {
+		return nil
+	}
+	revoked, err := p.denyList.IsTokenRevoked(id)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return fmt.Errorf("token revoked")
+	}
+	return nil
+}
+
+// Mint returns a new token for the user which expires after ttl.
+func (p *JWTProvider) Mint(userId string, tokenClaims TokenClaims, ttl time.Duration) (string, error) {
+	if p.signKey == nil {
+		return "", fmt.Errorf("jwt provider %s cannot sign tokens (no private key)", p.cfg.Name)
+	}
+	for roomId, role := range tokenClaims.Roles {
+		if !assignableRole(role) {
+			return "", fmt.Errorf("invalid role %s for room %s", role, roomId)
+		}
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: p.algorithm, Key: p.signKey}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := jwt.Claims{
+		Issuer:   p.cfg.Issuer,
+		Subject:  userId,
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt: jwt.NewNumericDate(now),
+		ID:       hex.EncodeToString(id),
+	}
+	if p.cfg.Audience != "" {
+		claims.Audience = jwt.Audience{p.cfg.Audience}
+	}
+	return jwt.Signed(signer).Claims(claims).Claims(tokenClaims).CompactSerialize()
+}
+
+// RevokedToken returns the deny list entry for the token. The signature of the token is not verified.
+func RevokedToken(token string) (types.RevokedToken, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return types.RevokedToken{}, err
+	}
+	claims := jwt.Claims{}
+	err = parsed.UnsafeClaimsWithoutVerification(&claims)
+	if err != nil {
+		return types.RevokedToken{}, err
+	}
+	revoked := types.RevokedToken{Id: tokenId(claims, token)}
+	if claims.Expiry != nil {
+		revoked.ExpiresAt = claims.Expiry.Time().Add(jwtLeeway)
+	}
+	return revoked, nil
+}
+
+// tokenId returns the id of the token in the deny list: the jti claim, or the hash of tokens without jti.
+func tokenId(claims jwt.Claims, token string) string {
+	if claims.ID != "" {
+		return claims.ID
+	}
+	hash := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// assignableRole reports whether the role can be assigned by an identity provider (the owner of a room and guests are
+// determined by the chat server).
+func assignableRole(role string) bool {
+	return role == types.RoleModerator || role == types.RoleMember || role == types.RoleBanned
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type fakeDenyList map[string]bool
+
+func (f fakeDenyList) IsTokenRevoked(id string) (bool, error) {
+	return f[id], nil
+}
+
+func TestJWTProvider(t *testing.T) {
+	denyList := fakeDenyList{}
+	registry, err := NewRegistry(&config.Config{JWTConfigs: []config.JWTConfig{
+		{Name: "site", Secret: "secret", Issuer: "site", Audience: "chat"},
+	}}, fakeStore{fakeDenyList: denyList})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+	provider := registry.JWTProvider("site")
+	assert.NotNil(t, provider)
+
+	token, err := provider.Mint("42", TokenClaims{Nick: "alice", Language: "de-AT", Roles: map[string]string{"room": types.RoleModerator}}, time.Hour)
+	assert.NoError(t, err)
+	identity, err := registry.Authenticate(ctx, token, "site")
+	assert.NoError(t, err)
+	assert.Equal(t, "site:42", identity.UserId)
+	assert.Equal(t, "alice", identity.Nick)
+	assert.Equal(t, "de", identity.Language)
+	assert.Equal(t, types.RoleModerator, identity.RoleIn("room"))
+	assert.Equal(t, "", identity.RoleIn("other"))
+	assert.NoError(t, registry.CheckIdentity(identity))
+
+	revoked, err := RevokedToken(token)
+	assert.NoError(t, err)
+	assert.True(t, revoked.ExpiresAt.After(time.Now().Add(time.Hour)))
+	denyList[revoked.Id] = true
+	_, err = registry.Authenticate(ctx, token, "site")
+	assert.Error(t, err, "revoked")
+	assert.Error(t, registry.CheckIdentity(identity), "revoked")
+
+	_, err = provider.Mint("42", TokenClaims{Roles: map[string]string{AllRooms: types.RoleOwner}}, time.Hour)
+	assert.Error(t, err, "the owner cannot be assigned")
+	expired, err := provider.Mint("42", TokenClaims{}, -time.Hour)
+	assert.NoError(t, err)
+	_, err = registry.Authenticate(ctx, expired, "site")
+	assert.Error(t, err, "expired")
+
+	sign := func(key interface{}, algorithm jose.SignatureAlgorithm, claims jwt.Claims) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := jwt.Claims{Subject: "42", Issuer: "site", Audience: jwt.Audience{"chat"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	identity, err = registry.Authenticate(ctx, sign([]byte("secret"), jose.HS256, valid), "site")
+	assert.NoError(t, err, "tokens without jti are accepted")
+	assert.Equal(t, "site:42", identity.UserId)
+	_, err = registry.Authenticate(ctx, sign([]byte("wrong"), jose.HS256, valid), "site")
+	assert.Error(t, err, "wrong secret")
+	_, err = registry.Authenticate(ctx, sign([]byte("secret"), jose.HS512, valid), "site")
+	assert.Error(t, err, "wrong algorithm")
+	noExpiry := valid
+	noExpiry.Expiry = nil
+	_, err = registry.Authenticate(ctx, sign([]byte("secret"), jose.HS256, noExpiry), "site")
+	assert.Error(t, err, "no expiry")
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.Audience{"other"}
+	_, err = registry.Authenticate(ctx, sign([]byte("secret"), jose.HS256, wrongAudience), "site")
+	assert.Error(t, err, "wrong audience")
+
+	_, err = NewRegistry(&config.Config{
+		JWTConfigs:  []config.JWTConfig{{Name: "site", Secret: "secret"}},
+		OIDCConfigs: []config.OIDCConfig{{Name: "site", ProviderUrl: "http://site"}},
+	}, nil)
+	assert.Error(t, err, "duplicate provider name")
+	_, err = NewRegistry(&config.Config{JWTConfigs: []config.JWTConfig{{Name: "site"}}}, nil)
+	assert.Error(t, err, "missing secret")
+}
+
+func TestJWTProviderRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyFile := filepath.Join(dir, "public.pem")
+	privateKeyFile := filepath.Join(dir, "private.pem")
+	assert.NoError(t, ioutil.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0600))
+	assert.NoError(t, ioutil.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+
+	verifier, err := NewJWTProvider(config.JWTConfig{Name: "site", Algorithm: "RS256", PublicKeyFile: publicKeyFile, PlainIds: true}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = verifier.Mint("42", TokenClaims{}, time.Hour)
+	assert.Error(t, err, "no private key")
+
+	signer, err := NewJWTProvider(config.JWTConfig{Name: "site", Algorithm: "RS256", PublicKeyFile: publicKeyFile, PrivateKeyFile: privateKeyFile}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	token, err := signer.Mint("42", TokenClaims{Roles: map[string]string{AllRooms: types.RoleBanned}}, time.Hour)
+	assert.NoError(t, err)
+	identity, err := verifier.Authenticate(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", identity.UserId)
+	assert.Equal(t, types.RoleBanned, identity.RoleIn("room"))
+
+	hs256, err := NewJWTProvider(config.JWTConfig{Name: "site", Secret: string(publicKey)}, nil)
+	assert.NoError(t, err)
+	forged, err := hs256.Mint("42", TokenClaims{}, time.Hour)
+	assert.NoError(t, err)
+	_, err = verifier.Authenticate(context.Background(), forged)
+	assert.Error(t, err, "HS256 tokens signed with the public key are rejected")
+}
@@ -2,63 +2,283 @@ package auth
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/tcriess/lightspeed-chat/config"
 	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
 )
 
-// Authenticate verifies a given OIDC ID-Token using the configured OIDC provider.
-// It returns the user's id if verification was successful (or an empty string if no provider was configured).
-// TODO: Currently, the userId is set to the "email" property of the claim, this could be made configurable. But: ensure that this is unique across the user base!
-func Authenticate(idToken, oidcProvider string, cfg *config.Config) (string, error) {
-	globals.AppLogger.Info("in authenticate")
-	userId := ""
-	if idToken == "" || len(cfg.OIDCConfigs) == 0 {
-		return "", nil
-	}
-	globals.AppLogger.Debug("checking config")
-	var oidcConf *config.OIDCConfig
-	for _, c := range cfg.OIDCConfigs {
-		if c.Name == oidcProvider {
-			oidcConf = &c
-			break
+const (
+	defaultIdClaim       = "sub"
+	defaultNickClaim     = "preferred_username"
+	defaultLanguageClaim = "locale"
+)
+
+// groupRolePrecedence decides which role is used if the groups of a user map to more than one role.
+var groupRolePrecedence = []string{types.RoleBanned, types.RoleModerator, types.RoleMember}
+
+// Identity is the result of a successful authentication.
+type Identity struct {
+	Provider  string            // name of the provider
+	UserId    string            // user id, namespaced with the provider name ("<provider>:<id claim>")
+	Nick      string            // nick claim, empty if not present
+	Language  string            // language claim (alpha-2), empty if not present
+	Role      string            // room role derived from the group claim, empty if no group is mapped to a role
+	RoomRoles map[string]string // room roles of local tokens (room id or AllRooms -> role)
+	TokenId   string            // id of the token in the deny list of local tokens, the jti claim of ID tokens
+	ExpiresAt time.Time         // expiry of the token, zero for bots
+	Bot       *types.Bot        `json:"-"` // the bot authenticated with an API key (see BotProvider), nil for humans
+}
+
+// RoleIn returns the role of the identity in the room assigned by the provider, or the empty string.
+func (i *Identity) RoleIn(roomId string) string {
+	if role, ok := i.RoomRoles[roomId]; ok {
+		return role
+	}
+	if role, ok := i.RoomRoles[AllRooms]; ok {
+		return role
+	}
+	return i.Role
+}
+
+// An Authenticator verifies tokens of one provider.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// A Provider verifies the ID tokens of one configured OIDC provider. The provider metadata is discovered once on first
+// use, the verifier caches the keys of the provider.
+type Provider struct {
+	cfg config.OIDCConfig
+
+	lock     sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// Registry holds all configured OIDC and local token providers and the bots. It is built once on startup, a nil
+// Registry authenticates nobody.
+type Registry struct {
+	providers map[string]Authenticator
+	bots      BotStore
+}
+
+// Store is the persisted state used by the registry (implemented by the persisters).
+type Store interface {
+	DenyList
+	BotStore
+}
+
+// NewRegistry builds the registry from the OIDC and JWT configuration blocks and checks them. Missing claim names are
+// set to their defaults. Local tokens are checked against the deny list of store, bots are looked up in store (may be
+// nil, then tokens cannot be revoked and there are no bots).
+func NewRegistry(globalConfig *config.Config, store Store) (*Registry, error) {
+	r := &Registry{providers: make(map[string]Authenticator), bots: store}
+	for _, cfg := range globalConfig.JWTConfigs {
+		if err := r.checkName(cfg.Name); err != nil {
+			return nil, err
+		}
+		provider, err := NewJWTProvider(cfg, store)
+		if err != nil {
+			return nil, err
 		}
+		r.providers[cfg.Name] = provider
+	}
+	for _, cfg := range globalConfig.OIDCConfigs {
+		if err := r.checkName(cfg.Name); err != nil {
+			return nil, err
+		}
+		if cfg.ProviderUrl == "" {
+			return nil, fmt.Errorf("oidc provider %s: missing provider_url", cfg.Name)
+		}
+		for group, role := range cfg.GroupRoles {
+			if !assignableRole(role) {
+				return nil, fmt.Errorf("oidc provider %s: invalid role %s for group %s", cfg.Name, role, group)
+			}
+		}
+		if cfg.IdClaim == "" {
+			cfg.IdClaim = defaultIdClaim
+		}
+		if cfg.NickClaim == "" {
+			cfg.NickClaim = defaultNickClaim
+		}
+		if cfg.LanguageClaim == "" {
+			cfg.LanguageClaim = defaultLanguageClaim
+		}
+		r.providers[cfg.Name] = &Provider{cfg: cfg}
+	}
+	return r, nil
+}
+
+func (r *Registry) checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("provider without name")
+	}
+	if strings.Contains(name, ":") {
+		return fmt.Errorf("provider %s: the name must not contain ':'", name)
+	}
+	if name == BotProvider {
+		return fmt.Errorf("provider %s: the name is reserved for bots", name)
+	}
+	if _, ok := r.providers[name]; ok {
+		return fmt.Errorf("duplicate provider %s", name)
+	}
+	return nil
+}
+
+// JWTProvider returns the local token provider with the given name, or nil.
+func (r *Registry) JWTProvider(name string) *JWTProvider {
+	if r == nil {
+		return nil
+	}
+	provider, _ := r.providers[name].(*JWTProvider)
+	return provider
+}
+
+// Authenticate verifies the token (an OIDC ID token or a local token) using the provider with the given name. It
+// returns the identity of the user, or nil if there is no token or no such provider.
+func (r *Registry) Authenticate(ctx context.Context, idToken, providerName string) (*Identity, error) {
+	if r == nil || idToken == "" {
+		return nil, nil
 	}
-	if oidcConf == nil {
-		globals.AppLogger.Debug("no oidc config found for provider", "provider", oidcProvider)
-		return "", nil
+	provider, ok := r.providers[providerName]
+	if !ok {
+		globals.AppLogger.Debug("no config found for provider", "provider", providerName)
+		return nil, nil
 	}
-	globals.AppLogger.Debug("found oidc config")
-	provider, err := oidc.NewProvider(context.Background(), oidcConf.ProviderUrl)
+	return provider.Authenticate(ctx, idToken)
+}
+
+// CheckIdentity checks that an identity restored from a session (instead of a token) is still valid: its provider is
+// configured, its token has not expired and, for local tokens, has not been revoked.
+func (r *Registry) CheckIdentity(identity *Identity) error {
+	if r == nil {
+		return fmt.Errorf("no providers")
+	}
+	provider, ok := r.providers[identity.Provider]
+	if !ok {
+		return fmt.Errorf("unknown provider %s", identity.Provider)
+	}
+	if !time.Now().Before(identity.ExpiresAt) {
+		return fmt.Errorf("token expired")
+	}
+	if jwtProvider, ok := provider.(*JWTProvider); ok {
+		return jwtProvider.checkRevoked(identity.TokenId)
+	}
+	return nil
+}
+
+// Authenticate verifies the ID token and maps its claims to the identity of the user.
+func (p *Provider) Authenticate(ctx context.Context, idToken string) (*Identity, error) {
+	verifier, err := p.getVerifier()
+	if err != nil {
+		return nil, err
+	}
+	verifiedIdToken, err := verifier.Verify(ctx, idToken)
 	if err != nil {
-		return "", err
+		globals.AppLogger.Error("could not verify token", "provider", p.cfg.Name, "error", err)
+		return nil, err
+	}
+	claims := make(map[string]interface{})
+	err = verifiedIdToken.Claims(&claims)
+	if err != nil {
+		globals.AppLogger.Error("could not unmarshal claims", "error", err)
+		return nil, err
+	}
+	identity, err := p.identity(claims)
+	if err != nil {
+		return nil, err
+	}
+	identity.TokenId = stringClaim(claims, "jti")
+	identity.ExpiresAt = verifiedIdToken.Expiry
+	return identity, nil
+}
+
+// getVerifier returns the verifier of the provider, discovering the provider on first use. A failed discovery is
+// retried on the next call.
+func (p *Provider) getVerifier() (*oidc.IDTokenVerifier, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.verifier != nil {
+		return p.verifier, nil
+	}
+	// the context is kept by the provider to fetch the keys later on, so it must not be canceled
+	provider, err := oidc.NewProvider(context.Background(), p.cfg.ProviderUrl)
+	if err != nil {
+		return nil, err
 	}
 	conf := oidc.Config{}
-	if oidcConf.ClientId == "" {
+	if p.cfg.ClientId == "" {
 		conf.SkipClientIDCheck = true
 	} else {
-		conf.ClientID = oidcConf.ClientId
+		conf.ClientID = p.cfg.ClientId
 	}
-	verifier := provider.Verifier(&conf)
-	verifiedIdToken, err := verifier.Verify(context.Background(), idToken)
-	if err != nil {
-		globals.AppLogger.Error("could not verify token", "error", err)
-		return "", err
+	p.verifier = provider.Verifier(&conf)
+	return p.verifier, nil
+}
+
+// identity maps the claims of a verified ID token to the identity of the user.
+func (p *Provider) identity(claims map[string]interface{}) (*Identity, error) {
+	id := stringClaim(claims, p.cfg.IdClaim)
+	if id == "" {
+		return nil, fmt.Errorf("missing claim %s", p.cfg.IdClaim)
+	}
+	identity := &Identity{
+		Provider: p.cfg.Name,
+		UserId:   p.cfg.Name + ":" + id,
+		Nick:     stringClaim(claims, p.cfg.NickClaim),
+	}
+	if p.cfg.PlainIds {
+		identity.UserId = id
+	}
+	if language := stringClaim(claims, p.cfg.LanguageClaim); len(language) >= 2 {
+		identity.Language = strings.ToLower(language[:2])
+	}
+	if p.cfg.GroupsClaim != "" {
+		roles := make(map[string]bool)
+		for _, group := range stringsClaim(claims, p.cfg.GroupsClaim) {
+			if role, ok := p.cfg.GroupRoles[group]; ok {
+				roles[role] = true
+			}
+		}
+		for _, role := range groupRolePrecedence {
+			if roles[role] {
+				identity.Role = role
+				break
+			}
+		}
 	}
+	return identity, nil
+}
 
-	claims := struct {
-		Sub string `json:"sub"`
-		PreferredUsername string `json:"preferred_username"`
-	}{}
-	err = verifiedIdToken.Claims(&claims)
-	if err != nil {
-		globals.AppLogger.Error("could not unmarshal claims", "error", err)
-		return "", err
+// stringClaim returns the claim as a string (numbers are formatted), or the empty string.
+func stringClaim(claims map[string]interface{}, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
 	}
-	userId = claims.Sub
-	if claims.PreferredUsername != "" {
-		userId = claims.PreferredUsername
+	return ""
+}
+
+// stringsClaim returns the claim which is either a list of strings or a single string.
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
 	}
-	return userId, nil
+	return nil
 }
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// fakeIssuer is a local OIDC provider which serves the discovery document and the keys, and issues ID tokens.
+type fakeIssuer struct {
+	*httptest.Server
+	key         *rsa.PrivateKey
+	discoveries int32
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&issuer.discoveries, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/auth",
+			"token_endpoint":                        issuer.URL + "/token",
+			"jwks_uri":                              issuer.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// token returns a signed ID token for the client id with the given additional claims.
+func (i *fakeIssuer) token(t *testing.T, clientId string, claims map[string]interface{}) string {
+	payload := map[string]interface{}{
+		"iss": i.URL,
+		"aud": clientId,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestRegistry(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	other := newFakeIssuer(t)
+	registry, err := NewRegistry(&config.Config{OIDCConfigs: []config.OIDCConfig{
+		{Name: "test", ClientId: "chat", ProviderUrl: issuer.URL, GroupsClaim: "groups", GroupRoles: map[string]string{
+			"mods":   types.RoleModerator,
+			"trolls": types.RoleBanned,
+		}},
+		{Name: "other", ProviderUrl: other.URL, IdClaim: "email", NickClaim: "name", PlainIds: true},
+	}}, nil)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	identity, err := registry.Authenticate(ctx, issuer.token(t, "chat", map[string]interface{}{
+		"sub":                "1234",
+		"preferred_username": "alice",
+		"locale":             "de-DE",
+		"groups":             []string{"users", "mods"},
+		"jti":                "token-1",
+	}), "test")
+	if assert.NoError(t, err) {
+		assert.WithinDuration(t, time.Now().Add(time.Hour), identity.ExpiresAt, time.Minute)
+		identity.ExpiresAt = time.Time{}
+	}
+	assert.Equal(t, &Identity{Provider: "test", UserId: "test:1234", Nick: "alice", Language: "de", Role: types.RoleModerator, TokenId: "token-1"}, identity)
+
+	identity, err = registry.Authenticate(ctx, issuer.token(t, "chat", map[string]interface{}{
+		"sub":    "5678",
+		"groups": []string{"mods", "trolls"},
+	}), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, types.RoleBanned, identity.Role, "banned takes precedence")
+	assert.Equal(t, "", identity.Nick)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&issuer.discoveries), "the provider is discovered only once")
+
+	identity, err = registry.Authenticate(ctx, other.token(t, "any", map[string]interface{}{
+		"sub":   "1234",
+		"email": "bob@example.com",
+		"name":  "Bob",
+	}), "other")
+	if assert.NoError(t, err) {
+		identity.ExpiresAt = time.Time{}
+	}
+	assert.Equal(t, &Identity{Provider: "other", UserId: "bob@example.com", Nick: "Bob"}, identity)
+
+	_, err = registry.Authenticate(ctx, issuer.token(t, "someone-else", map[string]interface{}{"sub": "1234"}), "test")
+	assert.Error(t, err, "wrong audience")
+	_, err = registry.Authenticate(ctx, other.token(t, "chat", map[string]interface{}{"sub": "1234"}), "test")
+	assert.Error(t, err, "wrong issuer")
+	_, err = registry.Authenticate(ctx, other.token(t, "chat", map[string]interface{}{"sub": "1234"}), "other")
+	assert.Error(t, err, "missing id claim")
+
+	identity, err = registry.Authenticate(ctx, "token", "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+	var none *Registry
+	identity, err = none.Authenticate(ctx, "token", "test")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+}
+
+func TestNewRegistry(t *testing.T) {
+	_, err := NewRegistry(&config.Config{OIDCConfigs: []config.OIDCConfig{{Name: "a", ProviderUrl: "http://a"}, {Name: "a", ProviderUrl: "http://b"}}}, nil)
+	assert.Error(t, err)
+	_, err = NewRegistry(&config.Config{OIDCConfigs: []config.OIDCConfig{{Name: "a"}}}, nil)
+	assert.Error(t, err)
+	_, err = NewRegistry(&config.Config{OIDCConfigs: []config.OIDCConfig{{Name: "a:b", ProviderUrl: "http://a"}}}, nil)
+	assert.Error(t, err)
+	_, err = NewRegistry(&config.Config{OIDCConfigs: []config.OIDCConfig{{Name: "a", ProviderUrl: "http://a", GroupRoles: map[string]string{"admins": types.RoleOwner}}}}, nil)
+	assert.Error(t, err)
+	registry, err := NewRegistry(&config.Config{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, registry.providers)
+}
@@ -2,7 +2,7 @@
 
 set -e -u -o pipefail
 
-readonly BINARIES=(cmd/lightspeed-chat cmd/lightspeed-chat-admin plugins/lightspeed-chat-base-commands-plugin plugins/lightspeed-chat-google-translate-plugin)
+readonly BINARIES=(cmd/lightspeed-chat cmd/lightspeed-chat-admin plugins/lightspeed-chat-base-commands-plugin plugins/lightspeed-chat-google-translate-plugin plugins/lightspeed-chat-automod-plugin)
 
 go mod vendor
 
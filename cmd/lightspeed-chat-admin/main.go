@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,12 +11,15 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/tcriess/lightspeed-chat/auth"
 	"github.com/tcriess/lightspeed-chat/config"
 	"github.com/tcriess/lightspeed-chat/globals"
 	"github.com/tcriess/lightspeed-chat/persistence"
@@ -110,12 +114,15 @@ func main() {
 		for _, pluginCfg := range globalConfig.PluginConfigs {
 			if pluginCfg.Name == pluginName {
 				globals.AppLogger.Debug("found config", "config", pluginCfg.RawPluginConfig)
-				cronSpec, eventFilter, err := eventHandler.Configure(pluginCfg.RawPluginConfig)
+				pluginSpec.Timeout = pluginCfg.Timeout
+				pluginConfiguration, err := pluginSpec.Configure(context.Background(), pluginCfg.RawPluginConfig)
 				if err != nil {
 					panic(fmt.Sprintf("could not configure plugin %s: %s", pluginName, err))
 				}
-				pluginSpec.CronSpec = cronSpec
-				pluginSpec.EventFilter = eventFilter
+				pluginSpec.CronSpec = pluginConfiguration.CronSpec
+				pluginSpec.EventFilter = pluginConfiguration.EventsFilter
+				pluginSpec.Priority = pluginConfiguration.Priority
+				pluginSpec.Kind = pluginConfiguration.Kind
 				break
 			}
 		}
@@ -210,6 +217,44 @@ func main() {
 			fmt.Println(string(u))
 		},
 	}
+	var cmdShowMembers = &cobra.Command{
+		Use:   "members [room id]",
+		Short: "Show room members",
+		Long:  `show members lists all users with an explicit role in the room with the given id.`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			memberships, err := persister.GetMemberships(&types.Room{Id: args[0]})
+			if err != nil {
+				globals.AppLogger.Error("could not get memberships", "error", err)
+				return
+			}
+			m, err := json.Marshal(memberships)
+			if err != nil {
+				globals.AppLogger.Error("could not marshal memberships", "error", err)
+				return
+			}
+			fmt.Println(string(m))
+		},
+	}
+	var cmdShowBots = &cobra.Command{
+		Use:   "bots",
+		Short: "Show bots",
+		Long:  `show bots lists all bots with their settings.`,
+		Args:  cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			bots, err := persister.GetBots()
+			if err != nil {
+				globals.AppLogger.Error("could not get bots", "error", err)
+				return
+			}
+			b, err := json.Marshal(bots)
+			if err != nil {
+				globals.AppLogger.Error("could not marshal bots", "error", err)
+				return
+			}
+			fmt.Println(string(b))
+		},
+	}
 	var cmdDelete = &cobra.Command{
 		Use:   "delete",
 		Short: "delete room or user",
@@ -247,6 +292,20 @@ func main() {
 			}
 		},
 	}
+	var cmdDeleteMember = &cobra.Command{
+		Use:   "member [room id] [user id]",
+		Short: "Delete room member",
+		Long:  `delete member removes the explicit role of the user in the room, the user falls back to the member role.`,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			membership := types.Membership{RoomId: args[0], UserId: args[1]}
+			err := persister.DeleteMembership(&membership)
+			if err != nil {
+				globals.AppLogger.Error("could not delete membership", "error", err)
+				return
+			}
+		},
+	}
 	var cmdSet = &cobra.Command{
 		Use:   "set",
 		Short: "create/update room or user",
@@ -337,12 +396,247 @@ func main() {
 			}
 		},
 	}
+	var cmdSetRole = &cobra.Command{
+		Use:   "role [room id] [user id] [role]",
+		Short: "Set role",
+		Long:  `set role assigns the role (moderator, member or banned) to the user in the room with the given id.`,
+		Args:  cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			role := args[2]
+			if !types.ValidRole(role) || role == types.RoleOwner || role == types.RoleGuest {
+				globals.AppLogger.Error("invalid role", "role", role)
+				return
+			}
+			membership := types.Membership{RoomId: args[0], UserId: args[1], Role: role}
+			err := persister.StoreMembership(membership)
+			if err != nil {
+				globals.AppLogger.Error("could not store membership", "error", err)
+				return
+			}
+		},
+	}
+	var cmdToken = &cobra.Command{
+		Use:   "token",
+		Short: "create or revoke local tokens",
+		Long:  `token creates or revokes tokens for the local token providers (jwt blocks).`,
+		Args:  cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("Token: " + strings.Join(args, " "))
+		},
+	}
+	var cmdTokenCreate = &cobra.Command{
+		Use:   "create [provider] [user id] [option=value...]",
+		Short: "Create token",
+		Long: `token create prints a new token of the local token provider for the user. Options are nick=<nick>,
+language=<language>, ttl=<duration> (default 24h) and role.<room id>=<role> (room id "*" for all rooms).`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			registry, err := auth.NewRegistry(globalConfig, persister)
+			if err != nil {
+				globals.AppLogger.Error("could not create auth registry", "error", err)
+				return
+			}
+			provider := registry.JWTProvider(args[0])
+			if provider == nil {
+				globals.AppLogger.Error("no such local token provider", "provider", args[0])
+				return
+			}
+			ttl := 24 * time.Hour
+			claims := auth.TokenClaims{Roles: make(map[string]string)}
+			for _, option := range args[2:] {
+				parts := strings.SplitN(option, "=", 2)
+				if len(parts) != 2 {
+					globals.AppLogger.Error("invalid option", "option", option)
+					return
+				}
+				switch {
+				case parts[0] == "nick":
+					claims.Nick = parts[1]
+				case parts[0] == "language":
+					claims.Language = parts[1]
+				case parts[0] == "ttl":
+					ttl, err = time.ParseDuration(parts[1])
+					if err != nil {
+						globals.AppLogger.Error("invalid ttl", "error", err)
+						return
+					}
+				case strings.HasPrefix(parts[0], "role."):
+					claims.Roles[strings.TrimPrefix(parts[0], "role.")] = parts[1]
+				default:
+					globals.AppLogger.Error("unknown option", "option", option)
+					return
+				}
+			}
+			token, err := provider.Mint(args[1], claims, ttl)
+			if err != nil {
+				globals.AppLogger.Error("could not create token", "error", err)
+				return
+			}
+			fmt.Println(token)
+		},
+	}
+	var cmdTokenRevoke = &cobra.Command{
+		Use:   "revoke [token or token id]",
+		Short: "Revoke token",
+		Long: `token revoke adds the token to the deny list. Instead of the token, its id (jti claim) can be given, the
+entry is kept forever then.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			revokedToken, err := auth.RevokedToken(args[0])
+			if err != nil {
+				revokedToken = types.RevokedToken{Id: args[0]}
+			}
+			err = persister.RevokeToken(revokedToken)
+			if err != nil {
+				globals.AppLogger.Error("could not revoke token", "error", err)
+				return
+			}
+		},
+	}
+	var cmdBot = &cobra.Command{
+		Use:   "bot",
+		Short: "create, update or delete bots",
+		Long:  `bot manages the bot users, which authenticate with an API key.`,
+		Args:  cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("Bot: " + strings.Join(args, " "))
+		},
+	}
+	var cmdBotCreate = &cobra.Command{
+		Use:   "create [user id] [option=value...]",
+		Short: "Create bot",
+		Long: `bot create prints a new API key for the bot user, the user is created if it does not exist. An existing bot
+gets a new key (the old key stops working), its settings are kept unless given. Options are nick=<nick>,
+language=<language>, events=<event names> (comma separated, default chat) and rate=<messages>/<interval> (f.e.
+rate=10/1m, default the global limits).`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			user := types.User{Id: args[0]}
+			err := persister.GetUser(&user)
+			if err != nil {
+				globals.AppLogger.Info("user does not exist, creating")
+				user = types.User{Id: args[0], Nick: args[0], Language: "en", Tags: make(map[string]string)}
+			}
+			bot := types.Bot{UserId: user.Id}
+			_ = persister.GetBot(&bot)
+			err = applyBotOptions(&bot, &user, args[1:])
+			if err != nil {
+				globals.AppLogger.Error("invalid option", "error", err)
+				return
+			}
+			key, hash, err := auth.NewAPIKey(user.Id)
+			if err != nil {
+				globals.AppLogger.Error("could not create api key", "error", err)
+				return
+			}
+			bot.KeyHash = hash
+			err = persister.StoreUser(user)
+			if err != nil {
+				globals.AppLogger.Error("could not store user", "error", err)
+				return
+			}
+			err = persister.StoreBot(bot)
+			if err != nil {
+				globals.AppLogger.Error("could not store bot", "error", err)
+				return
+			}
+			fmt.Println(key)
+		},
+	}
+	var cmdBotUpdate = &cobra.Command{
+		Use:   "update [user id] [option=value...]",
+		Short: "Update bot",
+		Long:  `bot update changes the settings of the bot, the API key is kept. The options are those of bot create.`,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			bot := types.Bot{UserId: args[0]}
+			err := persister.GetBot(&bot)
+			if err != nil {
+				globals.AppLogger.Error("could not get bot", "error", err)
+				return
+			}
+			user := types.User{Id: args[0]}
+			err = persister.GetUser(&user)
+			if err != nil {
+				globals.AppLogger.Error("could not get user", "error", err)
+				return
+			}
+			err = applyBotOptions(&bot, &user, args[1:])
+			if err != nil {
+				globals.AppLogger.Error("invalid option", "error", err)
+				return
+			}
+			err = persister.StoreUser(user)
+			if err != nil {
+				globals.AppLogger.Error("could not store user", "error", err)
+				return
+			}
+			err = persister.StoreBot(bot)
+			if err != nil {
+				globals.AppLogger.Error("could not store bot", "error", err)
+				return
+			}
+		},
+	}
+	var cmdBotDelete = &cobra.Command{
+		Use:   "delete [user id]",
+		Short: "Delete bot",
+		Long:  `bot delete removes the bot, its API key stops working. The user is kept.`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := persister.DeleteBot(&types.Bot{UserId: args[0]})
+			if err != nil {
+				globals.AppLogger.Error("could not delete bot", "error", err)
+				return
+			}
+		},
+	}
 	var rootCmd = &cobra.Command{Use: "lightspeed-chat-admin"}
 	rootCmd.AddCommand(cmdShow)
 	rootCmd.AddCommand(cmdDelete)
 	rootCmd.AddCommand(cmdSet)
-	cmdShow.AddCommand(cmdShowRooms, cmdShowRoom, cmdShowUsers, cmdShowUser)
-	cmdDelete.AddCommand(cmdDeleteRoom, cmdDeleteUser)
-	cmdSet.AddCommand(cmdSetRoom, cmdSetUser)
+	rootCmd.AddCommand(cmdToken)
+	rootCmd.AddCommand(cmdBot)
+	cmdShow.AddCommand(cmdShowRooms, cmdShowRoom, cmdShowUsers, cmdShowUser, cmdShowMembers, cmdShowBots)
+	cmdDelete.AddCommand(cmdDeleteRoom, cmdDeleteUser, cmdDeleteMember)
+	cmdSet.AddCommand(cmdSetRoom, cmdSetUser, cmdSetRole)
+	cmdToken.AddCommand(cmdTokenCreate, cmdTokenRevoke)
+	cmdBot.AddCommand(cmdBotCreate, cmdBotUpdate, cmdBotDelete)
 	rootCmd.Execute()
 }
+
+// applyBotOptions applies the options of the bot commands (option=value) to the bot and its user.
+func applyBotOptions(bot *types.Bot, user *types.User, options []string) error {
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid option %s", option)
+		}
+		switch parts[0] {
+		case "nick":
+			user.Nick = parts[1]
+		case "language":
+			user.Language = parts[1]
+		case "events":
+			bot.Events = parts[1]
+		case "rate":
+			rate := strings.SplitN(parts[1], "/", 2)
+			if len(rate) != 2 {
+				return fmt.Errorf("invalid rate %s (<messages>/<interval>)", parts[1])
+			}
+			messages, err := strconv.Atoi(rate[0])
+			if err != nil {
+				return fmt.Errorf("invalid rate %s: %w", parts[1], err)
+			}
+			interval, err := time.ParseDuration(rate[1])
+			if err != nil {
+				return fmt.Errorf("invalid rate %s: %w", parts[1], err)
+			}
+			bot.Messages = messages
+			bot.Interval = interval
+		default:
+			return fmt.Errorf("unknown option %s", option)
+		}
+	}
+	return nil
+}
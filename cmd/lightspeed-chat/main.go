@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"expvar"
 	"fmt"
 	"gorm.io/gorm"
 	"log"
@@ -15,9 +18,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/folkengine/goname"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	"github.com/spf13/pflag"
@@ -31,6 +32,13 @@ import (
 	"github.com/tidwall/buntdb"
 )
 
+const (
+	revokedTokensCleanupInterval = time.Hour
+
+	// roomPath is the path of the websocket endpoint of a room
+	roomPath = "/chat/{room:[a-z][a-z0-9_-]+}"
+)
+
 var (
 	configPath          = pflag.StringP("config", "c", "", "path to config file or directory")
 	eventHandlerPlugins = pflag.StringSliceP("plugin", "p", nil, "path(s) to event handler plugin(s)")
@@ -38,9 +46,6 @@ var (
 	sslCert             = pflag.String("ssl-cert", "", "SSL cert for websocket (optional)")
 	sslKey              = pflag.String("ssl-key", "", "SSL key for websocket (optional)")
 
-	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool { return true },
-	}
 	hubs          map[string]*ws.Hub = make(map[string]*ws.Hub)
 	hubsLock      sync.RWMutex
 	globalPlugins map[string]plugins.PluginSpec = make(map[string]plugins.PluginSpec)
@@ -70,6 +75,11 @@ func main() {
 
 	globals.AppLogger.SetLevel(hclog.LevelFromString(globalConfig.LogLevel))
 
+	if policy := globalConfig.WebsocketConfig.SlowConsumer.Policy; !ws.ValidSlowConsumerPolicy(policy) {
+		panic(fmt.Sprintf("invalid slow consumer policy: %s", policy))
+	}
+	expvar.Publish("ws_clients", expvar.Func(clientMetrics))
+
 	persister, err := persistence.NewPersister(globalConfig)
 	if err != nil {
 		panic(err)
@@ -117,27 +127,39 @@ func main() {
 			globals.AppLogger.Warn(`"main" is not a valid plugin name, skipping`)
 			continue
 		}
-		pluginSpec := plugins.PluginSpec{
-			Name:   pluginName,
-			Plugin: eventHandler,
-		}
 		globals.AppLogger.Debug("pluginName", "pluginName", pluginName)
-		for _, pluginCfg := range globalConfig.PluginConfigs {
-			if pluginCfg.Name == pluginName {
+		var pluginCfg *config.PluginConfig
+		for i := range globalConfig.PluginConfigs {
+			if globalConfig.PluginConfigs[i].Name == pluginName {
+				pluginCfg = &globalConfig.PluginConfigs[i]
 				globals.AppLogger.Debug("found config", "config", pluginCfg.RawPluginConfig)
-				cronSpec, eventFilter, err := eventHandler.Configure(pluginCfg.RawPluginConfig)
-				if err != nil {
-					panic(fmt.Sprintf("could not configure plugin %s: %s", pluginName, err))
-				}
-				pluginSpec.CronSpec = cronSpec
-				pluginSpec.EventFilter = eventFilter
 				break
 			}
 		}
+		// plugins without configuration block are configured as well, they register their commands
+		pluginSpec, err := plugins.NewPluginSpec(context.Background(), pluginName, eventHandler, pluginCfg)
+		if err != nil {
+			panic(err)
+		}
 		globalPlugins[pluginName] = pluginSpec
 	}
 	defer plugin.CleanupClients()
 
+	authRegistry, err := auth.NewRegistry(globalConfig, persister)
+	if err != nil {
+		panic(err)
+	}
+	if persister != nil {
+		// entries of expired tokens are not needed in the deny list any more
+		go func() {
+			for now := range time.Tick(revokedTokensCleanupInterval) {
+				if err := persister.DeleteExpiredRevokedTokens(now); err != nil {
+					globals.AppLogger.Error("could not delete expired revoked tokens", "error", err)
+				}
+			}
+		}()
+	}
+
 	var rooms []*types.Room
 	if persister != nil {
 		var err error
@@ -167,7 +189,7 @@ func main() {
 			}
 			// no room in the db, create a default room
 			tags := make(map[string]string)
-			tags["_allow_guests"] = "true"
+			tags[types.PermissionTag(types.RoleGuest, types.PermissionPost)] = "true"
 			room := &types.Room{
 				Id:    "default",
 				Owner: &adminUser,
@@ -181,7 +203,7 @@ func main() {
 		}
 	} else {
 		tags := make(map[string]string)
-		tags["_allow_guests"] = "true"
+		tags[types.PermissionTag(types.RoleGuest, types.PermissionPost)] = "true"
 		room := &types.Room{
 			Id:    "default",
 			Owner: &types.User{Id: globalConfig.AdminUser, Nick: globalConfig.AdminUser, Language: "en", Tags: make(map[string]string)},
@@ -192,7 +214,7 @@ func main() {
 
 	for _, room := range rooms {
 		globals.AppLogger.Debug("creating room", "id", room.Id, "room", *room)
-		hub := ws.NewHub(room, globalConfig, persister, globalPlugins)
+		hub := ws.NewHub(room, globalConfig, authRegistry, persister, globalPlugins)
 		hubs[room.Id] = hub
 		go hub.Run()
 	}
@@ -208,53 +230,123 @@ func main() {
 
 func setupRoutes() {
 	router := mux.NewRouter()
-	router.HandleFunc("/chat/{room:[a-z][a-z0-9_-]+}", websocketHandler).Methods(http.MethodGet)
+	router.HandleFunc(roomPath, websocketHandler).Methods(http.MethodGet)
+	router.HandleFunc(roomPath+"/events", sseHandler).Methods(http.MethodGet)
+	router.HandleFunc(roomPath+"/events", postHandler).Methods(http.MethodPost)
+	router.HandleFunc(roomPath+"/events", preflightHandler).Methods(http.MethodOptions)
 	http.Handle("/", router)
 }
 
-// Handle incoming websockets
-func websocketHandler(w http.ResponseWriter, r *http.Request) {
-	globals.AppLogger.Info("in websocketHandler")
-
-	vars := mux.Vars(r)
-	roomName := vars["room"]
+// roomHub returns the hub of the room of the request, nil if there is none.
+func roomHub(r *http.Request) *ws.Hub {
+	roomName := mux.Vars(r)["room"]
 	if roomName == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil
 	}
 	globals.AppLogger.Debug("looking for room", "room", roomName)
-	var hub *ws.Hub
 	hubsLock.RLock()
-	if h, ok := hubs[roomName]; !ok {
+	defer hubsLock.RUnlock()
+	hub, ok := hubs[roomName]
+	if !ok {
 		globals.AppLogger.Debug("room not found")
-		hubsLock.RUnlock()
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	} else {
-		globals.AppLogger.Debug("room found")
-		hubsLock.RUnlock()
-		hub = h
+		return nil
 	}
 	globals.AppLogger.Debug("found room!")
+	return hub
+}
+
+// apiKey returns the API key of a bot passed in the Authorization header ("Bearer <key>"), or the empty string.
+func apiKey(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) > len("Bearer ") && strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(authorization[len("Bearer "):])
+	}
+	return ""
+}
 
-	userId := ""
+// connectingUser returns the user of a client connecting to the room and the result of the authentication (nil for
+// guests). Guests receive the cookie keeping their identity in header. A bot must pass a valid API key (see apiKey).
+// If the user cannot be loaded, the error status is written and ok is false.
+func connectingUser(hub *ws.Hub, w http.ResponseWriter, r *http.Request, header http.Header) (user *types.User, identity *auth.Identity, ok bool) {
 	vals := r.URL.Query()
-	globals.AppLogger.Debug("checking id token")
-	if idToken := vals.Get("id_token"); idToken != "" {
-		globals.AppLogger.Debug("token", "idtoken", idToken)
-		if provider := vals.Get("provider"); provider != "" {
-			var err error
-			globals.AppLogger.Debug("found oidc provider", "provider", provider)
-			userId, err = auth.Authenticate(idToken, provider, hub.Cfg)
-			if err != nil {
-				globals.AppLogger.Error("could not authenticate", "error", err)
+	var session *ws.Session
+	if key := apiKey(r); key != "" {
+		var err error
+		identity, err = hub.Auth.AuthenticateAPIKey(key)
+		if err != nil || identity == nil {
+			globals.AppLogger.Error("could not authenticate bot", "error", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil, nil, false
+		}
+	} else {
+		globals.AppLogger.Debug("checking id token")
+		if idToken := vals.Get("id_token"); idToken != "" {
+			globals.AppLogger.Debug("token", "idtoken", idToken)
+			if provider := vals.Get("provider"); provider != "" {
+				var err error
+				globals.AppLogger.Debug("found oidc provider", "provider", provider)
+				identity, err = hub.Auth.Authenticate(r.Context(), idToken, provider)
+				if err != nil {
+					globals.AppLogger.Error("could not authenticate", "error", err)
+				}
+			}
+		}
+
+		// a client which reconnects resumes its session, unless it authenticates again
+		if token := vals.Get("session"); token != "" {
+			session = hub.ParseSession(token)
+			if identity == nil && session != nil {
+				identity = session.Identity
 			}
 		}
 	}
-	language := vals.Get("language")
+
+	if identity != nil {
+		u, err := hub.LoadUser(identity)
+		if err != nil {
+			globals.AppLogger.Error("could not load user", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, nil, false
+		}
+		user = u
+	} else {
+		// guests keep their identity across connections via a signed cookie
+		var guest *types.User
+		if session != nil {
+			guest = session.Guest()
+		}
+		if guest == nil {
+			guest = ws.GuestFromRequest(r, hub.Cfg)
+		}
+		u := *guest
+		user = &u
+		header.Add("Set-Cookie", ws.GuestCookie(user, hub.Cfg, r.TLS != nil).String())
+	}
+	for k := range user.Tags {
+		if strings.HasPrefix(k, "_") { // remove internal tags
+			delete(user.Tags, k)
+		}
+	}
+	return user, identity, true
+}
+
+// Handle incoming websockets
+func websocketHandler(w http.ResponseWriter, r *http.Request) {
+	globals.AppLogger.Info("in websocketHandler")
+
+	hub := roomHub(r)
+	if hub == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	responseHeader := http.Header{}
+	user, identity, ok := connectingUser(hub, w, r, responseHeader)
+	if !ok {
+		return
+	}
 
 	// Upgrade HTTP request to Websocket
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := hub.Upgrader().Upgrade(w, r, responseHeader)
 	if err != nil {
 		globals.AppLogger.Error("websocket upgrade error", "error", err)
 		return
@@ -263,48 +355,127 @@ func websocketHandler(w http.ResponseWriter, r *http.Request) {
 	// When this frame returns close the Websocket
 	defer conn.Close()
 
-	doneChan := make(chan struct{})
+	serveClient(hub, hub.WebsocketTransport(conn), user, identity, r)
+}
 
-	nick := userId
-	if nick == "" {
-		nick = goname.New(goname.FantasyMap).FirstLast() + " (guest)"
-		if ag, ok := hub.Room.Tags["_allow_guests"]; ok {
-			if allowGuests, err := strconv.ParseBool(ag); err == nil && allowGuests {
-				userId = nick
-			}
-		}
+// sseHandler streams the events of the room as Server-Sent Events, the client posts its messages via postHandler.
+func sseHandler(w http.ResponseWriter, r *http.Request) {
+	globals.AppLogger.Info("in sseHandler")
+
+	hub := roomHub(r)
+	if hub == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
-	user := types.User{
-		Id:         userId,
-		Nick:       nick,
-		Language:   "",
-		Tags:       make(map[string]string),
-		LastOnline: time.Time{},
-	}
-	if userId != "" && hub.Persister != nil {
-		err = hub.Persister.GetUser(&user)
-		if err == gorm.ErrRecordNotFound || err == buntdb.ErrNotFound || err == sql.ErrNoRows {
-			user.Language = "en"
-			user.LastOnline = time.Now()
-			err := hub.Persister.StoreUser(user)
-			if err != nil {
-				globals.AppLogger.Error("could not store user", "error", err)
-				return
-			}
-		} else {
-			if err != nil {
-				globals.AppLogger.Error("could not get user", "error", err)
-				return
-			}
-			nick = user.Nick
-		}
+	if !hub.AllowsOrigin(r.Header.Get("Origin")) {
+		w.WriteHeader(http.StatusForbidden)
+		return
 	}
-	for k := range user.Tags {
-		if strings.HasPrefix(k, "_") { // remove internal tags
-			delete(user.Tags, k)
-		}
+	user, identity, ok := connectingUser(hub, w, r, w.Header())
+	if !ok {
+		return
+	}
+	transport, err := hub.SSETransport(w, r)
+	if err != nil {
+		globals.AppLogger.Error("could not start event stream", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	serveClient(hub, transport, user, identity, r)
+}
+
+// postHandler handles a message of a client connected via Server-Sent Events, the connection id is passed in the
+// "connection" query parameter. The answers are sent via the event stream. Bots may post without a connection (see
+// postBotMessage).
+func postHandler(w http.ResponseWriter, r *http.Request) {
+	hub := roomHub(r)
+	if hub == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	origin := r.Header.Get("Origin")
+	if !hub.AllowsOrigin(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+	connection := r.URL.Query().Get("connection")
+	if connection == "" && apiKey(r) != "" {
+		postBotMessage(hub, w, r)
+		return
 	}
-	c := ws.NewClient(hub, conn, &user, language, doneChan)
+	switch err := hub.PostMessage(connection, r.Body); err {
+	case nil:
+		w.WriteHeader(http.StatusAccepted)
+	case ws.ErrUnknownConnection:
+		w.WriteHeader(http.StatusNotFound)
+	case ws.ErrMessageTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case ws.ErrMalformedMessage:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		globals.AppLogger.Error("could not read posted message", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// postBotMessage handles a message posted by a bot without a connection. The answer (an error, or the ack of a message
+// with a request id) is written as JSON.
+func postBotMessage(hub *ws.Hub, w http.ResponseWriter, r *http.Request) {
+	user, identity, ok := connectingUser(hub, w, r, w.Header())
+	if !ok {
+		return
+	}
+	answer, err := hub.PostBotMessage(user, identity, r.Body)
+	switch err {
+	case nil:
+	case ws.ErrMessageTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	case ws.ErrMalformedMessage:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	default:
+		globals.AppLogger.Error("could not handle posted bot message", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if answer == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(ws.PostStatus(answer))
+	if err := json.NewEncoder(w).Encode(answer); err != nil {
+		globals.AppLogger.Error("could not write answer", "error", err)
+	}
+}
+
+// preflightHandler allows browsers from the allowed origins to post messages with a JSON content type.
+func preflightHandler(w http.ResponseWriter, r *http.Request) {
+	hub := roomHub(r)
+	origin := r.Header.Get("Origin")
+	if hub == nil || origin == "" || !hub.AllowsOrigin(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Methods", http.MethodPost)
+	header.Set("Access-Control-Allow-Headers", "Content-Type")
+	header.Add("Vary", "Origin")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveClient registers the client of the connection with the hub, sends the session and the history (or the events
+// it missed) and returns when the connection is closed.
+func serveClient(hub *ws.Hub, transport ws.Transport, user *types.User, identity *auth.Identity, r *http.Request) {
+	vals := r.URL.Query()
+	doneChan := make(chan struct{})
+	c := ws.NewClient(hub, transport, user, identity, vals.Get("language"), doneChan)
 	go c.PluginLoop()
 
 	// Add to the hub
@@ -325,12 +496,18 @@ func websocketHandler(w http.ResponseWriter, r *http.Request) {
 	c.Add(2)
 	go c.ReadLoop()
 	go c.WriteLoop()
+	if c.Protocol() >= ws.ProtocolV1 {
+		// the protocol version was negotiated via the websocket subprotocol (or the version query parameter)
+		c.SendHello()
+	}
+	c.SendConnection()
+	c.SendSession()
 
 	wg := &sync.WaitGroup{}
 	if user.Id != "" {
 		wg.Add(2)
 		source := &types.Source{
-			User:       &user,
+			User:       user,
 			PluginName: "main",
 		}
 
@@ -348,11 +525,27 @@ func websocketHandler(w http.ResponseWriter, r *http.Request) {
 		}(userEvent, wg)
 	}
 	wg.Add(1)
-	go c.SendHistory(hub.GetHistory(), wg)
+	if lastSeq, err := strconv.ParseUint(vals.Get("last_seq"), 10, 64); err == nil {
+		// the client reconnects and only receives the events it missed
+		go c.Resume(lastSeq, wg)
+	} else {
+		go c.SendHistory(hub.GetHistory(), wg)
+	}
 	// make sure those 3 are done before closing the send channel
 	globals.AppLogger.Debug("wait for client wg chan")
 	wg.Wait()
 	globals.AppLogger.Debug("done waiting for client wg chan, waiting for doneChan")
 	<-doneChan
-	globals.AppLogger.Info("doneChan closed, exiting ws handler")
+	globals.AppLogger.Info("doneChan closed, exiting client handler")
+}
+
+// clientMetrics returns the statistics of the send queues of all clients by room, which are published via expvar.
+func clientMetrics() interface{} {
+	hubsLock.RLock()
+	defer hubsLock.RUnlock()
+	metrics := make(map[string][]ws.ClientMetrics, len(hubs))
+	for roomId, hub := range hubs {
+		metrics[roomId] = hub.ClientMetrics()
+	}
+	return metrics
 }
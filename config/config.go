@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -14,6 +15,23 @@ import (
 
 const (
 	defaultAdminUser = "admin"
+
+	defaultGuestCookieName       = "lightspeed_guest"
+	defaultGuestCookieMaxAge     = 30 * 24 * time.Hour
+	defaultGuestMessages         = 5
+	defaultGuestInterval         = time.Minute
+	defaultGuestMaxMessageLength = 200
+	defaultInterval              = time.Minute
+	defaultSessionTTL            = time.Hour
+	defaultSessionMaxReplay      = 500
+	defaultSlowConsumerPolicy    = "disconnect"
+	defaultMaxMessageSize        = 4096
+	defaultPongWait              = 2 * time.Minute
+	defaultPingPeriod            = time.Minute
+	defaultWriteWait             = 10 * time.Second
+	defaultQueueSize             = 1000
+	defaultCompressionLevel      = 1
+	defaultCompressionThreshold  = 512
 )
 
 // Config is the global configuration object which is filled via the configuration file
@@ -21,10 +39,77 @@ const (
 type Config struct {
 	HistoryConfig     HistoryConfig     `mapstructure:"history"`
 	OIDCConfigs       []OIDCConfig      `mapstructure:"oidc"`
+	JWTConfigs        []JWTConfig       `mapstructure:"jwt"`
 	PersistenceConfig PersistenceConfig `mapstructure:"persistence"`
 	PluginConfigs     []PluginConfig    `mapstructure:"plugin"`
 	LogLevel          string            `mapstructure:"log_level"`
 	AdminUser         string            `mapstructure:"admin_user"`
+	Limits            LimitsConfig      `mapstructure:"limits"`
+	GuestConfig       GuestConfig       `mapstructure:"guests"`
+	SessionConfig     SessionConfig     `mapstructure:"session"`
+	WebsocketConfig   WebsocketConfig   `mapstructure:"websocket"`
+}
+
+// LimitsConfig limits the chat messages and events a user may send: at most Messages per Interval (0: unlimited) with
+// at most MaxMessageLength characters each (0: unlimited).
+type LimitsConfig struct {
+	Messages         int           `mapstructure:"messages"`
+	Interval         time.Duration `mapstructure:"interval"`
+	MaxMessageLength int           `mapstructure:"max_message_length"`
+}
+
+// GuestConfig configures unauthenticated users. Guests get a generated identity which is stored in a cookie signed
+// with Secret (if empty, a random secret is used and guests cannot resume their identity after a restart), so that
+// they keep their identity when they reconnect. Limits apply to guests instead of the global limits. Whether guests
+// may post at all is a permission of the room (see types.Room.Allows).
+type GuestConfig struct {
+	Secret       string        `mapstructure:"secret"`
+	CookieName   string        `mapstructure:"cookie_name"`
+	CookieMaxAge time.Duration `mapstructure:"cookie_max_age"`
+	Limits       LimitsConfig  `mapstructure:"limits"`
+}
+
+// SessionConfig configures the session tokens which let clients resume their session when they reconnect. The tokens
+// are signed with Secret (if empty, a random secret is used and sessions cannot be resumed after a restart) and are
+// valid for TTL. A reconnecting client receives at most MaxReplay missed events, if it missed more, it receives the
+// history instead.
+type SessionConfig struct {
+	Secret    string        `mapstructure:"secret"`
+	TTL       time.Duration `mapstructure:"ttl"`
+	MaxReplay int           `mapstructure:"max_replay"`
+}
+
+// WebsocketConfig configures the connections of the clients. Messages of the clients are limited to MaxMessageSize
+// bytes. The server sends a ping every PingPeriod and expects an answer within PongWait, writing a message must not
+// take longer than WriteWait. The queue sizes limit the messages queued per client (SendQueueSize, PluginQueueSize)
+// and per room (BroadcastQueueSize, HistoryQueueSize). Only browsers from AllowedOrigins may connect (all if empty, the
+// room tag "_allowed_origins" overrides the list per room). With Compression, messages of at least
+// CompressionThreshold bytes are compressed with CompressionLevel (1-9), if the client supports it. SlowConsumer
+// defines what happens if a client does not read its messages fast enough.
+type WebsocketConfig struct {
+	MaxMessageSize       int64              `mapstructure:"max_message_size"`
+	PongWait             time.Duration      `mapstructure:"pong_wait"`
+	PingPeriod           time.Duration      `mapstructure:"ping_period"`
+	WriteWait            time.Duration      `mapstructure:"write_wait"`
+	SendQueueSize        int                `mapstructure:"send_queue_size"`
+	PluginQueueSize      int                `mapstructure:"plugin_queue_size"`
+	BroadcastQueueSize   int                `mapstructure:"broadcast_queue_size"`
+	HistoryQueueSize     int                `mapstructure:"history_queue_size"`
+	AllowedOrigins       []string           `mapstructure:"allowed_origins"`
+	Compression          bool               `mapstructure:"compression"`
+	CompressionLevel     int                `mapstructure:"compression_level"`
+	CompressionThreshold int                `mapstructure:"compression_threshold"`
+	SlowConsumer         SlowConsumerConfig `mapstructure:"slow_consumer"`
+}
+
+// SlowConsumerConfig defines what happens if the send queue of a client is full: with the Policy "disconnect", new
+// messages are dropped and the client is disconnected once more than MaxDropped messages were dropped, "drop_oldest"
+// drops the oldest queued message, "drop_non_chat" drops events which are no chat messages (f.e. info events) before
+// the queue is full. The room tags "_slow_consumer.policy" and "_slow_consumer.max_dropped" override the policy per
+// room.
+type SlowConsumerConfig struct {
+	Policy     string `mapstructure:"policy"`
+	MaxDropped int    `mapstructure:"max_dropped"`
 }
 
 // HistoryConfig configures the size of the immediate event history that is kept in memory in a ring buffer and
@@ -35,10 +120,35 @@ type HistoryConfig struct {
 
 // An OIDCConfig  object configures an OpenID Connect provider that is used to authenticate users. Users provide
 // an ID token and the name of the provider, the authentication is then performed via verification of the token.
+// The claims of the token named by IdClaim, NickClaim and LanguageClaim are used as the user id, nick and language.
+// User ids are prefixed with the provider name ("<name>:<id>"), unless PlainIds is set (only safe with a single
+// provider). The groups in GroupsClaim are mapped to room roles via GroupRoles.
 type OIDCConfig struct {
-	Name        string `mapstructure:"name"`
-	ClientId    string `mapstructure:"client_id"`
-	ProviderUrl string `mapstructure:"provider_url"` // f.e. "https://accounts.google.com", this is used to construct the discovery url and subsequently discover the openid endpoints
+	Name          string            `mapstructure:"name"`
+	ClientId      string            `mapstructure:"client_id"`
+	ProviderUrl   string            `mapstructure:"provider_url"`   // f.e. "https://accounts.google.com", this is used to construct the discovery url and subsequently discover the openid endpoints
+	IdClaim       string            `mapstructure:"id_claim"`       // default: "sub"
+	NickClaim     string            `mapstructure:"nick_claim"`     // default: "preferred_username"
+	LanguageClaim string            `mapstructure:"language_claim"` // default: "locale"
+	GroupsClaim   string            `mapstructure:"groups_claim"`   // optional, f.e. "groups"
+	GroupRoles    map[string]string `mapstructure:"group_roles"`    // group -> role (moderator, member or banned)
+	PlainIds      bool              `mapstructure:"plain_ids"`
+}
+
+// A JWTConfig configures the authentication with tokens signed by an embedding application (f.e. a site with its own
+// session system) instead of an OpenID Connect provider. The tokens are signed with HS256 using Secret or with RS256
+// using the private key matching PublicKeyFile (PEM). PrivateKeyFile is only needed to mint RS256 tokens with
+// lightspeed-chat-admin. If set, the iss and aud claims must match Issuer and Audience. User ids are prefixed with the
+// name, unless PlainIds is set.
+type JWTConfig struct {
+	Name           string `mapstructure:"name"`
+	Algorithm      string `mapstructure:"algorithm"` // HS256 (default) or RS256
+	Secret         string `mapstructure:"secret"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	Issuer         string `mapstructure:"issuer"`
+	Audience       string `mapstructure:"audience"`
+	PlainIds       bool   `mapstructure:"plain_ids"`
 }
 
 // BundDBConfig configures the BuntDB file storage backed database.
@@ -68,9 +178,16 @@ type PersistenceConfig struct {
 }
 
 // Each named PluginConfig block configures a plugin. The raw configuration RawPluginConfig is passed on to the plugin which
-// parses its own configuration.
+// parses its own configuration. The remaining attributes are used by the main process and are not passed on:
+// Timeout is the deadline for every call to the plugin (f.e. "2s"), QueueSize and Concurrency define the size of the
+// event queue and the number of concurrent calls per room, OverflowPolicy ("drop" or "block") what happens if the queue
+// is full.
 type PluginConfig struct {
 	Name            string                 `mapstructure:"name"`
+	Timeout         time.Duration          `mapstructure:"timeout"`
+	QueueSize       int                    `mapstructure:"queue_size"`
+	Concurrency     int                    `mapstructure:"concurrency"`
+	OverflowPolicy  string                 `mapstructure:"overflow_policy"`
 	RawPluginConfig map[string]interface{} `mapstructure:",remain"`
 }
 
@@ -95,6 +212,25 @@ func ReadConfiguration(configPath string, flagSet *pflag.FlagSet) (*Config, erro
 	cfg := Config{}
 	flagSet.SetNormalizeFunc(wordSepNormalizeFunc)
 	viper.SetDefault("admin_user", defaultAdminUser)
+	viper.SetDefault("limits.interval", defaultInterval)
+	viper.SetDefault("guests.cookie_name", defaultGuestCookieName)
+	viper.SetDefault("guests.cookie_max_age", defaultGuestCookieMaxAge)
+	viper.SetDefault("guests.limits.messages", defaultGuestMessages)
+	viper.SetDefault("guests.limits.interval", defaultGuestInterval)
+	viper.SetDefault("guests.limits.max_message_length", defaultGuestMaxMessageLength)
+	viper.SetDefault("session.ttl", defaultSessionTTL)
+	viper.SetDefault("session.max_replay", defaultSessionMaxReplay)
+	viper.SetDefault("websocket.max_message_size", defaultMaxMessageSize)
+	viper.SetDefault("websocket.pong_wait", defaultPongWait)
+	viper.SetDefault("websocket.ping_period", defaultPingPeriod)
+	viper.SetDefault("websocket.write_wait", defaultWriteWait)
+	viper.SetDefault("websocket.send_queue_size", defaultQueueSize)
+	viper.SetDefault("websocket.plugin_queue_size", defaultQueueSize)
+	viper.SetDefault("websocket.broadcast_queue_size", defaultQueueSize)
+	viper.SetDefault("websocket.history_queue_size", defaultQueueSize)
+	viper.SetDefault("websocket.compression_level", defaultCompressionLevel)
+	viper.SetDefault("websocket.compression_threshold", defaultCompressionThreshold)
+	viper.SetDefault("websocket.slow_consumer.policy", defaultSlowConsumerPolicy)
 	err := viper.BindPFlags(flagSet)
 	if err != nil {
 		globals.AppLogger.Error("could not bind flags (ignored)", "error", err)
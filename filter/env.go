@@ -17,6 +17,7 @@ type User struct {
 	Language   string
 	Tags       map[string]string
 	LastOnline int64
+	IsGuest    bool
 }
 
 // Room is the representation of types.Room inside the Env
@@ -30,6 +31,8 @@ type Room struct {
 type Source struct {
 	User
 	PluginName string
+	Role       string // role of the user in the room (see types.Role*)
+	Bot        bool   // sent by a bot user (see types.Bot)
 }
 
 // Client is the representation of the connected client ws.Client inside the Env
@@ -41,6 +44,7 @@ type Client struct {
 type Target struct {
 	User
 	Client
+	Role string // role of the user in the room (see types.Role*)
 }
 
 // Env is the complete environment of input data for target or plugin filters
@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func userEnv(user *types.User) User {
+	if user == nil {
+		return User{}
+	}
+	return User{
+		Id:         user.Id,
+		Nick:       user.Nick,
+		Language:   user.Language,
+		Tags:       user.Tags,
+		LastOnline: user.LastOnline.Unix(),
+		IsGuest:    user.IsGuest,
+	}
+}
+
+// PluginEnv returns the Env for evaluating a plugin filter on an event in the given room. There is no target.
+func PluginEnv(room *types.Room, event *types.Event) Env {
+	env := Env{
+		Created:       event.Created.Unix(),
+		Language:      event.Language,
+		Name:          event.Name,
+		Tags:          event.Tags,
+		AsInt:         AsInt,
+		AsFloat:       AsFloat,
+		AsStringSlice: AsStringSlice,
+		AsIntSlice:    AsIntSlice,
+		AsFloatSlice:  AsFloatSlice,
+	}
+	if room != nil {
+		env.Room = Room{
+			Id:    room.Id,
+			Owner: userEnv(room.Owner),
+			Tags:  room.Tags,
+		}
+	}
+	if event.Source != nil {
+		env.Source = Source{
+			User:       userEnv(event.Source.User),
+			PluginName: event.Source.PluginName,
+			Role:       event.Source.Role,
+			Bot:        event.Source.Bot,
+		}
+	}
+	return env
+}
@@ -22,7 +22,6 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/mitchellh/go-testing-interface v1.14.1 // indirect
 	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
-	github.com/mitchellh/hashstructure/v2 v2.0.1
 	github.com/mitchellh/mapstructure v1.4.1
 	github.com/notti/nocgo v0.0.0-20190619201224-fc443047424c // indirect
 	github.com/oklog/run v1.1.0 // indirect
@@ -44,6 +43,7 @@ require (
 	google.golang.org/grpc v1.35.0
 	google.golang.org/protobuf v1.25.0
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
+	gopkg.in/square/go-jose.v2 v2.5.1
 	gorm.io/datatypes v1.0.0
 	gorm.io/driver/postgres v1.0.8
 	gorm.io/driver/sqlite v1.1.4
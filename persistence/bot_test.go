@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestBots(t *testing.T) {
+	for name, newPersister := range testPersisters(t) {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPersister()
+			if !assert.NoError(t, err) || !assert.NotNil(t, p) {
+				return
+			}
+			defer p.Close()
+			alerts := types.User{Id: "alerts", Nick: "alerts", Language: "en"}
+			assert.NoError(t, p.StoreUser(alerts))
+			assert.NoError(t, p.StoreBot(types.Bot{UserId: "alerts", KeyHash: "old", Events: "chat,alert", Messages: 10, Interval: time.Minute}))
+			assert.NoError(t, p.StoreBot(types.Bot{UserId: "alerts", KeyHash: "new", Events: "chat,alert", Messages: 10, Interval: time.Minute}))
+
+			bot := types.Bot{UserId: "alerts"}
+			assert.NoError(t, p.GetBot(&bot))
+			assert.Equal(t, "new", bot.KeyHash, "the key is replaced")
+			assert.Equal(t, "chat,alert", bot.Events)
+			assert.Equal(t, 10, bot.Messages)
+			assert.Equal(t, time.Minute, bot.Interval)
+			assert.False(t, bot.CreatedAt.IsZero())
+			assert.Error(t, p.GetBot(&types.Bot{UserId: "unknown"}))
+
+			bots, err := p.GetBots()
+			if assert.NoError(t, err) && assert.Len(t, bots, 1) {
+				assert.Equal(t, "alerts", bots[0].UserId)
+				assert.Equal(t, "new", bots[0].KeyHash)
+			}
+
+			// the bot flag of the events is kept
+			room := types.Room{Id: "room", Owner: &alerts}
+			assert.NoError(t, p.StoreRoom(room))
+			event := types.NewEvent(&room, &types.Source{User: &alerts, Bot: true}, "", "en", "alert", nil)
+			event.Seq = 1
+			assert.NoError(t, p.StoreEvents(&room, []*types.Event{event}))
+			events, err := p.GetEventsAfterSeq(&room, 0, 10)
+			if assert.NoError(t, err) && assert.Len(t, events, 1) {
+				assert.True(t, events[0].Source.Bot)
+			}
+
+			assert.NoError(t, p.DeleteBot(&types.Bot{UserId: "alerts"}))
+			assert.Error(t, p.GetBot(&types.Bot{UserId: "alerts"}))
+			user := types.User{Id: "alerts"}
+			assert.NoError(t, p.GetUser(&user), "the user is kept")
+		})
+	}
+}
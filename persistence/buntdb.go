@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"text/template"
 	"time"
@@ -104,7 +105,7 @@ func setupBuntDB(cfg *config.Config) (*buntdb.DB, map[string]*buntdb.DB, *templa
 			if err != nil {
 				return nil, nil, nil, err
 			}
-			err = roomDb.CreateIndex("eventsts", "event:*", buntdb.IndexJSON("created"))
+			err = createEventIndexes(roomDb)
 			if err != nil {
 				db.Close()
 				for _, rDb := range roomDbs {
@@ -307,7 +308,7 @@ func (p *BuntDBPersist) StoreRoom(room types.Room) error {
 			}
 			return err
 		}
-		err = roomDb.CreateIndex("eventsts", "event:*", buntdb.IndexJSON("created"))
+		err = createEventIndexes(roomDb)
 		if err != nil {
 			roomDb.Close()
 			if replaced {
@@ -451,6 +452,230 @@ func (p *BuntDBPersist) UpdateRoomTags(room *types.Room, updates []*types.TagUpd
 	return resOk, nil
 }
 
+func membershipKey(roomId, userId string) string {
+	return "membership:" + roomId + ":" + userId
+}
+
+func (p *BuntDBPersist) StoreMembership(membership types.Membership) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	m, err := json.Marshal(membership)
+	if err != nil {
+		return err
+	}
+	return p.db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(membershipKey(membership.RoomId, membership.UserId), string(m), nil)
+		return err
+	})
+}
+
+func (p *BuntDBPersist) GetMembership(membership *types.Membership) error {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	if membership.RoomId == "" || membership.UserId == "" {
+		return fmt.Errorf("no room or user id")
+	}
+	return p.db.View(func(tx *buntdb.Tx) error {
+		m, err := tx.Get(membershipKey(membership.RoomId, membership.UserId))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(m), membership)
+	})
+}
+
+func (p *BuntDBPersist) GetMemberships(room *types.Room) ([]*types.Membership, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	memberships := make([]*types.Membership, 0)
+	err := p.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(membershipKey(room.Id, "*"), func(key, val string) bool {
+			membership := &types.Membership{}
+			if err := json.Unmarshal([]byte(val), membership); err == nil && membership.RoomId == room.Id {
+				memberships = append(memberships, membership)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (p *BuntDBPersist) DeleteMembership(membership *types.Membership) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	return p.db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(membershipKey(membership.RoomId, membership.UserId))
+		return err
+	})
+}
+
+func botKey(userId string) string {
+	return "bot:" + userId
+}
+
+// storedBot includes the key hash, which is not part of the JSON representation of a bot.
+type storedBot struct {
+	types.Bot
+	KeyHash string `json:"key_hash"`
+}
+
+func (p *BuntDBPersist) StoreBot(bot types.Bot) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	return p.db.Update(func(tx *buntdb.Tx) error {
+		// the creation time of an existing bot is kept
+		bot.CreatedAt = time.Now()
+		if b, err := tx.Get(botKey(bot.UserId)); err == nil {
+			oldBot := types.Bot{}
+			if err := json.Unmarshal([]byte(b), &oldBot); err == nil {
+				bot.CreatedAt = oldBot.CreatedAt
+			}
+		}
+		b, err := json.Marshal(storedBot{Bot: bot, KeyHash: bot.KeyHash})
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(botKey(bot.UserId), string(b), nil)
+		return err
+	})
+}
+
+func (p *BuntDBPersist) GetBot(bot *types.Bot) error {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	return p.db.View(func(tx *buntdb.Tx) error {
+		b, err := tx.Get(botKey(bot.UserId))
+		if err != nil {
+			return err
+		}
+		stored := storedBot{}
+		if err := json.Unmarshal([]byte(b), &stored); err != nil {
+			return err
+		}
+		*bot = stored.Bot
+		bot.KeyHash = stored.KeyHash
+		return nil
+	})
+}
+
+func (p *BuntDBPersist) GetBots() ([]*types.Bot, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	bots := make([]*types.Bot, 0)
+	err := p.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(botKey("*"), func(key, val string) bool {
+			stored := storedBot{}
+			if err := json.Unmarshal([]byte(val), &stored); err == nil {
+				bot := stored.Bot
+				bot.KeyHash = stored.KeyHash
+				bots = append(bots, &bot)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
+func (p *BuntDBPersist) DeleteBot(bot *types.Bot) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	return p.db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(botKey(bot.UserId))
+		return err
+	})
+}
+
+func revokedTokenKey(id string) string {
+	return "revoked_token:" + id
+}
+
+// RevokeToken stores the deny list entry with a TTL, so that BuntDB removes it once the token has expired.
+func (p *BuntDBPersist) RevokeToken(token types.RevokedToken) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	token.CreatedAt = time.Now()
+	t, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	var opts *buntdb.SetOptions
+	if !token.ExpiresAt.IsZero() {
+		ttl := time.Until(token.ExpiresAt)
+		if ttl <= 0 {
+			return nil
+		}
+		opts = &buntdb.SetOptions{Expires: true, TTL: ttl}
+	}
+	return p.db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(revokedTokenKey(token.Id), string(t), opts)
+		return err
+	})
+}
+
+func (p *BuntDBPersist) IsTokenRevoked(id string) (bool, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	err := p.db.View(func(tx *buntdb.Tx) error {
+		_, err := tx.Get(revokedTokenKey(id))
+		return err
+	})
+	if err == buntdb.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteExpiredRevokedTokens does nothing, expired entries are removed by BuntDB itself.
+func (p *BuntDBPersist) DeleteExpiredRevokedTokens(time.Time) error {
+	return nil
+}
+
 func (p *BuntDBPersist) StoreEvents(room *types.Room, events []*types.Event) error {
 	if len(events) == 0 {
 		return nil
@@ -489,7 +714,7 @@ func (p *BuntDBPersist) StoreEvents(room *types.Room, events []*types.Event) err
 
 // GetEventHistory returns a slice of events from db.
 //
-// Use fromTs/toTs to restrict the time range, and fromIdx/maxCount for pagination.
+// Use fromTs/toTs to restrict the time range, and fromIdx/maxCount for pagination. The newest events come first.
 // Important: the resulting events are expected to have the "History" flag set!
 func (p *BuntDBPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time, fromIdx, maxCount int) ([]*types.Event, error) {
 	if room == nil {
@@ -503,23 +728,25 @@ func (p *BuntDBPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time
 	}
 	events := make([]*types.Event, 0)
 
-	fromCond := fmt.Sprintf(`{"created":"%s"}`, fromTs.In(time.UTC).Format(time.RFC3339))
-	toCond := fmt.Sprintf(`{"created":"%s"}`, toTs.In(time.UTC).Format(time.RFC3339))
-
 	if roomDb, ok := p.roomDbs[room.Id]; ok {
 		err := roomDb.View(func(tx *buntdb.Tx) error {
 			currentNo := -1
 			count := 0
-			return tx.DescendRange("eventsts", toCond, fromCond, func(key, val string) bool {
+			// the events are ordered by their sequence number, the RFC 3339 timestamps do not sort correctly as strings
+			return tx.Descend("eventsseq", func(key, val string) bool {
+				event := &types.Event{}
+				if err := json.Unmarshal([]byte(val), event); err != nil {
+					return true
+				}
+				if event.Created.Before(fromTs) || !event.Created.Before(toTs) {
+					return true
+				}
 				currentNo++
 				if currentNo < fromIdx {
 					return true
 				}
-				event := &types.Event{}
-				if err := json.Unmarshal([]byte(val), event); err == nil {
-					event.History = true
-					events = append(events, event)
-				}
+				event.History = true
+				events = append(events, event)
 				count++
 				return maxCount <= 0 || count < maxCount
 			})
@@ -530,6 +757,126 @@ func (p *BuntDBPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time
 	}
 }
 
+// GetEventsAfterSeq returns at most maxCount events of the room with a sequence number greater than seq, ordered by
+// their sequence number.
+func (p *BuntDBPersist) GetEventsAfterSeq(room *types.Room, seq uint64, maxCount int) ([]*types.Event, error) {
+	if room == nil {
+		return nil, fmt.Errorf("no room")
+	}
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	events := make([]*types.Event, 0)
+	roomDb, ok := p.roomDbs[room.Id]
+	if !ok {
+		return nil, fmt.Errorf("no room db")
+	}
+	err := roomDb.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("eventsseq", fmt.Sprintf(`{"seq":%d}`, seq+1), func(key, val string) bool {
+			event := &types.Event{}
+			if err := json.Unmarshal([]byte(val), event); err == nil {
+				event.History = true
+				events = append(events, event)
+			}
+			return maxCount <= 0 || len(events) < maxCount
+		})
+	})
+	return events, err
+}
+
+// GetLastEventSeq returns the highest sequence number of the stored events of the room (0 if there are none).
+func (p *BuntDBPersist) GetLastEventSeq(room *types.Room) (uint64, error) {
+	if room == nil {
+		return 0, fmt.Errorf("no room")
+	}
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	roomDb, ok := p.roomDbs[room.Id]
+	if !ok {
+		return 0, fmt.Errorf("no room db")
+	}
+	var seq uint64
+	err := roomDb.View(func(tx *buntdb.Tx) error {
+		return tx.Descend("eventsseq", func(key, val string) bool {
+			event := types.Event{}
+			if err := json.Unmarshal([]byte(val), &event); err == nil {
+				seq = event.Seq
+			}
+			return false
+		})
+	})
+	return seq, err
+}
+
+// AssignEventSeqs numbers the stored events of the room which have no sequence number yet (events stored before
+// sequence numbers were introduced). Those are older than the numbered events, so all events of the room are
+// renumbered: first the unnumbered ones by their creation time, then the numbered ones in their order.
+func (p *BuntDBPersist) AssignEventSeqs(room *types.Room) error {
+	if room == nil {
+		return fmt.Errorf("no room")
+	}
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	roomDb, ok := p.roomDbs[room.Id]
+	if !ok {
+		return fmt.Errorf("no room db")
+	}
+	return roomDb.Update(func(tx *buntdb.Tx) error {
+		events := make([]*types.Event, 0)
+		unnumbered := false
+		err := tx.Ascend("eventsseq", func(key, val string) bool {
+			event := &types.Event{}
+			if err := json.Unmarshal([]byte(val), event); err != nil {
+				globals.AppLogger.Error("could not unmarshal event", "key", key, "error", err)
+				return true
+			}
+			unnumbered = unnumbered || event.Seq == 0
+			events = append(events, event)
+			return true
+		})
+		if err != nil || !unnumbered {
+			return err
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			if (events[i].Seq == 0) != (events[j].Seq == 0) {
+				return events[i].Seq == 0
+			}
+			if events[i].Seq != events[j].Seq {
+				return events[i].Seq < events[j].Seq
+			}
+			return events[i].Created.Before(events[j].Created)
+		})
+		for i, event := range events {
+			event.Seq = uint64(i + 1)
+			msg, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			_, _, err = tx.Set("event:"+event.Id, string(msg), nil)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createEventIndexes creates the index of the events in a room database (by sequence number).
+func createEventIndexes(roomDb *buntdb.DB) error {
+	return roomDb.CreateIndex("eventsseq", "event:*", buntdb.IndexJSON("seq"))
+}
+
 func (p *BuntDBPersist) Close() error {
 	p.RWMutex.Lock()
 	defer p.RWMutex.Unlock()
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestEventsAfterSeq(t *testing.T) {
+	for name, newPersister := range testPersisters(t) {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPersister()
+			if !assert.NoError(t, err) || !assert.NotNil(t, p) {
+				return
+			}
+			defer p.Close()
+			owner := types.User{Id: "owner", Nick: "owner", Language: "en"}
+			assert.NoError(t, p.StoreUser(owner))
+			room := types.Room{Id: "room", Owner: &owner}
+			assert.NoError(t, p.StoreRoom(room))
+
+			seq, err := p.GetLastEventSeq(&room)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(0), seq)
+
+			events := make([]*types.Event, 0)
+			for i := 0; i <= 3; i++ {
+				event := types.NewEvent(&room, &types.Source{User: &owner}, "", "en", types.EventTypeChat, map[string]string{"message": "hi"})
+				event.Seq = uint64(i) // the first event is stored without sequence number
+				events = append(events, event)
+			}
+			assert.NoError(t, p.StoreEvents(&room, events))
+
+			seq, err = p.GetLastEventSeq(&room)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(3), seq)
+
+			after, err := p.GetEventsAfterSeq(&room, 1, 10)
+			if assert.NoError(t, err) && assert.Len(t, after, 2) {
+				assert.Equal(t, events[2].Id, after[0].Id)
+				assert.Equal(t, uint64(2), after[0].Seq)
+				assert.Equal(t, uint64(3), after[1].Seq)
+				assert.True(t, after[0].History)
+			}
+			after, err = p.GetEventsAfterSeq(&room, 0, 1)
+			if assert.NoError(t, err) && assert.Len(t, after, 1) {
+				assert.Equal(t, uint64(1), after[0].Seq)
+			}
+			after, err = p.GetEventsAfterSeq(&room, 3, 10)
+			assert.NoError(t, err)
+			assert.Empty(t, after)
+		})
+	}
+}
+
+func TestAssignEventSeqs(t *testing.T) {
+	for name, newPersister := range testPersisters(t) {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPersister()
+			if !assert.NoError(t, err) || !assert.NotNil(t, p) {
+				return
+			}
+			defer p.Close()
+			owner := types.User{Id: "owner", Nick: "owner", Language: "en"}
+			assert.NoError(t, p.StoreUser(owner))
+			room := types.Room{Id: "room", Owner: &owner}
+			assert.NoError(t, p.StoreRoom(room))
+
+			// two events stored before sequence numbers were introduced (within the same second) and a numbered one
+			created := time.Date(2021, 3, 1, 12, 0, 0, 500, time.UTC)
+			events := make([]*types.Event, 0)
+			for i := 0; i < 3; i++ {
+				event := types.NewEvent(&room, &types.Source{User: &owner}, "", "en", types.EventTypeChat, map[string]string{"message": "hi"})
+				event.Created = created.Add(time.Duration(2-i) * time.Millisecond)
+				events = append(events, event)
+			}
+			events[0].Seq = 1
+			assert.NoError(t, p.StoreEvents(&room, events))
+
+			assert.NoError(t, p.AssignEventSeqs(&room))
+			assert.NoError(t, p.AssignEventSeqs(&room), "numbering is done only once")
+			history, err := p.GetEventHistory(&room, time.Time{}, time.Now(), 0, 10)
+			if assert.NoError(t, err) && assert.Len(t, history, 3) {
+				assert.Equal(t, []string{events[0].Id, events[1].Id, events[2].Id}, []string{history[0].Id, history[1].Id, history[2].Id}, "newest first")
+				assert.Equal(t, []uint64{3, 2, 1}, []uint64{history[0].Seq, history[1].Seq, history[2].Seq})
+				assert.True(t, events[1].Created.Equal(history[1].Created), "%s != %s", events[1].Created, history[1].Created)
+			}
+			seq, err := p.GetLastEventSeq(&room)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(3), seq)
+		})
+	}
+}
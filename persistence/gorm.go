@@ -53,7 +53,7 @@ func setupGormDB(cfg *config.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.Migrator().AutoMigrate(&types.User{}, &types.Room{}, &types.Event{})
+	err = db.Migrator().AutoMigrate(&types.User{}, &types.Room{}, &types.Event{}, &types.Membership{}, &types.RevokedToken{}, &types.Bot{})
 	if err != nil {
 		return nil, err
 	}
@@ -130,13 +130,63 @@ func (p *GormPersist) UpdateRoomTags(room *types.Room, updates []*types.TagUpdat
 	return res, nil
 }
 
+func (p *GormPersist) StoreMembership(membership types.Membership) error {
+	return p.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&membership).Error
+}
+
+func (p *GormPersist) GetMembership(membership *types.Membership) error {
+	return p.db.Where("room_id = ? AND user_id = ?", membership.RoomId, membership.UserId).First(membership).Error
+}
+
+func (p *GormPersist) GetMemberships(room *types.Room) ([]*types.Membership, error) {
+	memberships := make([]*types.Membership, 0)
+	err := p.db.Where("room_id = ?", room.Id).Find(&memberships).Error
+	return memberships, err
+}
+
+func (p *GormPersist) DeleteMembership(membership *types.Membership) error {
+	return p.db.Where("room_id = ? AND user_id = ?", membership.RoomId, membership.UserId).Delete(&types.Membership{}).Error
+}
+
+func (p *GormPersist) RevokeToken(token types.RevokedToken) error {
+	return p.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&token).Error
+}
+
+func (p *GormPersist) IsTokenRevoked(id string) (bool, error) {
+	var count int64
+	err := p.db.Model(&types.RevokedToken{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (p *GormPersist) DeleteExpiredRevokedTokens(now time.Time) error {
+	return p.db.Where("expires_at > ? AND expires_at < ?", time.Time{}, now).Delete(&types.RevokedToken{}).Error
+}
+
+func (p *GormPersist) StoreBot(bot types.Bot) error {
+	return p.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&bot).Error
+}
+
+func (p *GormPersist) GetBot(bot *types.Bot) error {
+	return p.db.Where("user_id = ?", bot.UserId).First(bot).Error
+}
+
+func (p *GormPersist) GetBots() ([]*types.Bot, error) {
+	bots := make([]*types.Bot, 0)
+	err := p.db.Find(&bots).Error
+	return bots, err
+}
+
+func (p *GormPersist) DeleteBot(bot *types.Bot) error {
+	return p.db.Where("user_id = ?", bot.UserId).Delete(&types.Bot{}).Error
+}
+
 func (p *GormPersist) StoreEvents(_ *types.Room, events []*types.Event) error {
 	return p.db.Create(&events).Error
 }
 
 func (p *GormPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time, fromIdx, maxCount int) ([]*types.Event, error) {
 	events := make([]*types.Event, 0)
-	err := p.db.Where("created BETWEEN ? AND ?", fromTs, toTs).Order("created DESC").Limit(maxCount).Offset(fromIdx).Find(&events).Error
+	err := p.db.Where("room_id = ? AND created BETWEEN ? AND ?", room.Id, fromTs, toTs).Order("seq DESC, created DESC").Limit(maxCount).Offset(fromIdx).Find(&events).Error
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +196,58 @@ func (p *GormPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time,
 	return events, nil
 }
 
+func (p *GormPersist) GetEventsAfterSeq(room *types.Room, seq uint64, maxCount int) ([]*types.Event, error) {
+	if room == nil {
+		return nil, fmt.Errorf("no room")
+	}
+	events := make([]*types.Event, 0)
+	err := p.db.Where("room_id = ? AND seq > ?", room.Id, seq).Order("seq").Limit(maxCount).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		event.History = true
+	}
+	return events, nil
+}
+
+func (p *GormPersist) GetLastEventSeq(room *types.Room) (uint64, error) {
+	if room == nil {
+		return 0, fmt.Errorf("no room")
+	}
+	var seq uint64
+	err := p.db.Model(&types.Event{}).Where("room_id = ?", room.Id).Select("COALESCE(MAX(seq),0)").Scan(&seq).Error
+	return seq, err
+}
+
+// AssignEventSeqs numbers the stored events of the room which have no sequence number yet (events stored before
+// sequence numbers were introduced). Those are older than the numbered events, so all events of the room are
+// renumbered: first the unnumbered ones by their creation time, then the numbered ones in their order.
+func (p *GormPersist) AssignEventSeqs(room *types.Room) error {
+	if room == nil {
+		return fmt.Errorf("no room")
+	}
+	var count int64
+	err := p.db.Model(&types.Event{}).Where("room_id = ? AND seq = 0", room.Id).Count(&count).Error
+	if err != nil || count == 0 {
+		return err
+	}
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		ids := make([]string, 0)
+		err := tx.Model(&types.Event{}).Where("room_id = ?", room.Id).Order("seq > 0, seq, created, id").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		for i, id := range ids {
+			err = tx.Model(&types.Event{}).Where("id = ?", id).UpdateColumn("seq", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (p *GormPersist) Close() error {
 	return nil
 }
\ No newline at end of file
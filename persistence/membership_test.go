@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// testPersisters returns the constructors of the persisters which can be tested without a database server, each
+// configured with a database in a new temporary directory.
+func testPersisters(t *testing.T) map[string]func() (Persister, error) {
+	dir := t.TempDir()
+	gormCfg := config.Config{}
+	gormCfg.PersistenceConfig.Type = "sqlite"
+	gormCfg.PersistenceConfig.DSN = filepath.Join(dir, "gorm.db")
+	sqliteCfg := config.Config{}
+	sqliteCfg.PersistenceConfig.SQLiteConfig.DSN = "file:" + filepath.Join(dir, "sqlite.db") + "?_fk=true"
+	buntCfg := config.Config{}
+	buntCfg.PersistenceConfig.BuntDBConfig.GlobalName = filepath.Join(dir, "global.buntdb")
+	buntCfg.PersistenceConfig.BuntDBConfig.RoomNameTemplate = filepath.Join(dir, "room_{{ .RoomId }}.buntdb")
+
+	return map[string]func() (Persister, error){
+		"gorm":   func() (Persister, error) { return NewGormPersister(&gormCfg) },
+		"sqlite": func() (Persister, error) { return NewSQLitePersister(&sqliteCfg) },
+		"buntdb": func() (Persister, error) { return NewBuntPersister(&buntCfg) },
+	}
+}
+
+func TestMemberships(t *testing.T) {
+	for name, newPersister := range testPersisters(t) {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPersister()
+			if !assert.NoError(t, err) || !assert.NotNil(t, p) {
+				return
+			}
+			defer p.Close()
+			owner := types.User{Id: "owner", Nick: "owner", Language: "en"}
+			bob := types.User{Id: "bob", Nick: "bob", Language: "en"}
+			alice := types.User{Id: "alice", Nick: "alice", Language: "en"}
+			for _, user := range []types.User{owner, bob, alice} {
+				assert.NoError(t, p.StoreUser(user))
+			}
+			room := types.Room{Id: "room", Owner: &owner}
+			assert.NoError(t, p.StoreRoom(room))
+
+			assert.NoError(t, p.StoreMembership(types.Membership{RoomId: "room", UserId: "bob", Role: types.RoleMember}))
+			assert.NoError(t, p.StoreMembership(types.Membership{RoomId: "room", UserId: "bob", Role: types.RoleModerator}))
+			assert.NoError(t, p.StoreMembership(types.Membership{RoomId: "room", UserId: "alice", Role: types.RoleBanned}))
+
+			membership := types.Membership{RoomId: "room", UserId: "bob"}
+			assert.NoError(t, p.GetMembership(&membership))
+			assert.Equal(t, types.RoleModerator, membership.Role)
+			assert.Error(t, p.GetMembership(&types.Membership{RoomId: "room", UserId: "owner"}))
+
+			memberships, err := p.GetMemberships(&room)
+			assert.NoError(t, err)
+			roles := make([]string, 0, len(memberships))
+			for _, m := range memberships {
+				roles = append(roles, m.UserId+":"+m.Role)
+			}
+			sort.Strings(roles)
+			assert.Equal(t, []string{"alice:banned", "bob:moderator"}, roles)
+
+			assert.NoError(t, p.DeleteMembership(&types.Membership{RoomId: "room", UserId: "alice"}))
+			assert.Error(t, p.GetMembership(&types.Membership{RoomId: "room", UserId: "alice"}))
+		})
+	}
+}
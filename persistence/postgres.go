@@ -54,6 +54,40 @@ id TEXT PRIMARY KEY,
 owner_id TEXT NOT NULL,
 tags JSONB DEFAULT '{}'::jsonb NOT NULL,
 FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS memberships (
+room_id TEXT NOT NULL,
+user_id TEXT NOT NULL,
+role TEXT NOT NULL,
+PRIMARY KEY (room_id, user_id),
+FOREIGN KEY (room_id) REFERENCES rooms (id) ON DELETE CASCADE ON UPDATE CASCADE,
+FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS revoked_tokens (
+id TEXT PRIMARY KEY,
+expires_at TIMESTAMP WITH TIME ZONE,
+created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS bots (
+user_id TEXT PRIMARY KEY,
+key_hash TEXT NOT NULL,
+events TEXT DEFAULT '' NOT NULL,
+messages INTEGER DEFAULT 0 NOT NULL,
+interval_ns BIGINT DEFAULT 0 NOT NULL,
+created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
 );`
 	_, err = db.Exec(query)
 	if err != nil {
@@ -70,6 +104,8 @@ tags JSONB DEFAULT '{}'::jsonb NOT NULL,
 target_filter TEXT DEFAULT '' NOT NULL,
 created TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
 sent TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+seq BIGINT DEFAULT 0 NOT NULL,
+bot BOOLEAN DEFAULT false NOT NULL,
 FOREIGN KEY (room_id) REFERENCES rooms (id) ON DELETE CASCADE ON UPDATE CASCADE,
 FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE CASCADE
 );`
@@ -77,7 +113,24 @@ FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE CASCADE
 	if err != nil {
 		return nil, err
 	}
-	query = `CREATE INDEX IF NOT EXISTS events_created_idx ON events (created, created_sort);`
+	query = `CREATE INDEX IF NOT EXISTS events_created_idx ON events (created);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	// databases created before events had sequence numbers lack the seq column
+	query = `ALTER TABLE events ADD COLUMN IF NOT EXISTS seq BIGINT DEFAULT 0 NOT NULL;`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE INDEX IF NOT EXISTS events_seq_idx ON events (room_id, seq);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	// databases created before bots were introduced lack the bot column
+	query = `ALTER TABLE events ADD COLUMN IF NOT EXISTS bot BOOLEAN DEFAULT false NOT NULL;`
 	_, err = db.Exec(query)
 	if err != nil {
 		return nil, err
@@ -291,12 +344,115 @@ func (p *PostgresPersist) UpdateRoomTags(room *types.Room, updates []*types.TagU
 	return resOk, nil
 }
 
+func (p *PostgresPersist) StoreMembership(membership types.Membership) error {
+	query := `INSERT INTO memberships (room_id,user_id,role) VALUES (?,?,?) ON CONFLICT (room_id,user_id) DO UPDATE SET role=EXCLUDED.role;`
+	_, err := p.db.Exec(query, membership.RoomId, membership.UserId, membership.Role)
+	return err
+}
+
+func (p *PostgresPersist) GetMembership(membership *types.Membership) error {
+	query := `SELECT role FROM memberships WHERE room_id=? AND user_id=?;`
+	return p.db.QueryRow(query, membership.RoomId, membership.UserId).Scan(&membership.Role)
+}
+
+func (p *PostgresPersist) GetMemberships(room *types.Room) ([]*types.Membership, error) {
+	memberships := make([]*types.Membership, 0)
+	query := `SELECT user_id,role FROM memberships WHERE room_id=?;`
+	rows, err := p.db.Query(query, room.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		membership := types.Membership{RoomId: room.Id}
+		err = rows.Scan(&membership.UserId, &membership.Role)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, &membership)
+	}
+	return memberships, rows.Err()
+}
+
+func (p *PostgresPersist) DeleteMembership(membership *types.Membership) error {
+	query := `DELETE FROM memberships WHERE room_id=? AND user_id=?;`
+	_, err := p.db.Exec(query, membership.RoomId, membership.UserId)
+	return err
+}
+
+func (p *PostgresPersist) RevokeToken(token types.RevokedToken) error {
+	var expiresAt sql.NullTime // NULL: never
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: token.ExpiresAt, Valid: true}
+	}
+	query := `INSERT INTO revoked_tokens (id,expires_at) VALUES (?,?) ON CONFLICT (id) DO UPDATE SET expires_at=EXCLUDED.expires_at;`
+	_, err := p.db.Exec(query, token.Id, expiresAt)
+	return err
+}
+
+func (p *PostgresPersist) IsTokenRevoked(id string) (bool, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM revoked_tokens WHERE id=?;`
+	err := p.db.QueryRow(query, id).Scan(&count)
+	return count > 0, err
+}
+
+func (p *PostgresPersist) DeleteExpiredRevokedTokens(now time.Time) error {
+	query := `DELETE FROM revoked_tokens WHERE expires_at < ?;`
+	_, err := p.db.Exec(query, now)
+	return err
+}
+
+func (p *PostgresPersist) StoreBot(bot types.Bot) error {
+	query := `INSERT INTO bots (user_id,key_hash,events,messages,interval_ns) VALUES (?,?,?,?,?) ON CONFLICT (user_id) DO UPDATE SET key_hash=EXCLUDED.key_hash,events=EXCLUDED.events,messages=EXCLUDED.messages,interval_ns=EXCLUDED.interval_ns;`
+	_, err := p.db.Exec(query, bot.UserId, bot.KeyHash, bot.Events, bot.Messages, int64(bot.Interval))
+	return err
+}
+
+func (p *PostgresPersist) GetBot(bot *types.Bot) error {
+	var interval int64
+	query := `SELECT key_hash,events,messages,interval_ns,created_at FROM bots WHERE user_id=?;`
+	err := p.db.QueryRow(query, bot.UserId).Scan(&bot.KeyHash, &bot.Events, &bot.Messages, &interval, &bot.CreatedAt)
+	if err != nil {
+		return err
+	}
+	bot.Interval = time.Duration(interval)
+	return nil
+}
+
+func (p *PostgresPersist) GetBots() ([]*types.Bot, error) {
+	bots := make([]*types.Bot, 0)
+	query := `SELECT user_id,key_hash,events,messages,interval_ns,created_at FROM bots;`
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		bot := types.Bot{}
+		var interval int64
+		err = rows.Scan(&bot.UserId, &bot.KeyHash, &bot.Events, &bot.Messages, &interval, &bot.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bot.Interval = time.Duration(interval)
+		bots = append(bots, &bot)
+	}
+	return bots, rows.Err()
+}
+
+func (p *PostgresPersist) DeleteBot(bot *types.Bot) error {
+	query := `DELETE FROM bots WHERE user_id=?;`
+	_, err := p.db.Exec(query, bot.UserId)
+	return err
+}
+
 func (p *PostgresPersist) StoreEvents(_ *types.Room, events []*types.Event) error {
 	tx, err := p.db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return err
 	}
-	query := `INSERT INTO events (id,room_id,user_id,plugin_name,name,language,tags,target_filter,created,sent) VALUES (?,?,?,?,?,?,?,?,?,?) ON CONFLICT (id) DO NOTHING;`
+	query := `INSERT INTO events (id,room_id,user_id,plugin_name,bot,name,language,tags,target_filter,created,sent,seq) VALUES (?,?,?,?,?,?,?,?,?,?,?,?) ON CONFLICT (id) DO NOTHING;`
 	for _, event := range events {
 		if event.Tags == nil {
 			event.Tags = make(map[string]string)
@@ -311,7 +467,7 @@ func (p *PostgresPersist) StoreEvents(_ *types.Room, events []*types.Event) erro
 			uid.Valid = true
 			uid.String = event.Source.User.Id
 		}
-		_, err = tx.Exec(query, event.Id, event.Room.Id, uid, event.Source.PluginName, event.Name, event.Language, tags, event.TargetFilter, event.Created, event.Sent)
+		_, err = tx.Exec(query, event.Id, event.Room.Id, uid, event.Source.PluginName, event.Source.Bot, event.Name, event.Language, tags, event.TargetFilter, event.Created, event.Sent, event.Seq)
 		if err != nil {
 			_ = tx.Rollback()
 			return err
@@ -329,14 +485,74 @@ func (p *PostgresPersist) GetEventHistory(room *types.Room, fromTs, toTs time.Ti
 	if room == nil {
 		return nil, fmt.Errorf("no room")
 	}
-	events := make([]*types.Event, 0)
 	from := fromTs.Unix()
 	to := toTs.Unix()
-	query := `SELECT e.id,e.room_id,e.user_id,e.plugin_name,e.name,e.language,e.tags,e.target_filter,e.created,e.sent,r.owner_id,r.tags,
+	query := postgresEventsQuery + `
+WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.seq DESC, e.created DESC LIMIT ? OFFSET ?;`
+	return p.queryEvents(query, room.Id, from, to, maxCount, fromIdx)
+}
+
+// GetEventsAfterSeq returns at most maxCount events of the room with a sequence number greater than seq, ordered by
+// their sequence number.
+func (p *PostgresPersist) GetEventsAfterSeq(room *types.Room, seq uint64, maxCount int) ([]*types.Event, error) {
+	if room == nil {
+		return nil, fmt.Errorf("no room")
+	}
+	query := postgresEventsQuery + `
+WHERE r.id=? AND e.seq > ? ORDER BY e.seq LIMIT ?;`
+	return p.queryEvents(query, room.Id, seq, maxCount)
+}
+
+// GetLastEventSeq returns the highest sequence number of the stored events of the room (0 if there are none).
+func (p *PostgresPersist) GetLastEventSeq(room *types.Room) (uint64, error) {
+	if room == nil {
+		return 0, fmt.Errorf("no room")
+	}
+	var seq uint64
+	err := p.db.QueryRow(`SELECT COALESCE(MAX(seq),0) FROM events WHERE room_id=?;`, room.Id).Scan(&seq)
+	return seq, err
+}
+
+// AssignEventSeqs numbers the stored events of the room which have no sequence number yet (events stored before
+// sequence numbers were introduced). Those are older than the numbered events, so all events of the room are
+// renumbered: first the unnumbered ones by their creation time, then the numbered ones in their order.
+func (p *PostgresPersist) AssignEventSeqs(room *types.Room) error {
+	if room == nil {
+		return fmt.Errorf("no room")
+	}
+	var count int64
+	err := p.db.QueryRow(`SELECT COUNT(*) FROM events WHERE room_id=? AND seq=0;`, room.Id).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+	tx, err := p.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	ids, err := queryIds(tx, `SELECT id FROM events WHERE room_id=? ORDER BY seq>0, seq, created, id;`, room.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for i, id := range ids {
+		_, err = tx.Exec(`UPDATE events SET seq=? WHERE id=?;`, i+1, id)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const postgresEventsQuery = `SELECT e.id,e.room_id,e.user_id,e.plugin_name,e.bot,e.name,e.language,e.tags,e.target_filter,e.created,e.sent,e.seq,r.owner_id,r.tags,
        u.nick,u.language,u.last_online,u.tags,o.nick,o.language,o.last_online,o.tags
-FROM events AS e INNER JOIN (rooms AS r INNER JOIN users AS o ON o.id=r.owner_id) ON r.id=e.room_id LEFT JOIN users AS u ON u.id=e.user_id
-WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.created, e.created_sort DESC LIMIT ? OFFSET ?;`
-	rows, err := p.db.Query(query, room.Id, from, to, maxCount, fromIdx)
+FROM events AS e INNER JOIN (rooms AS r INNER JOIN users AS o ON o.id=r.owner_id) ON r.id=e.room_id LEFT JOIN users AS u ON u.id=e.user_id`
+
+// queryEvents runs the query (postgresEventsQuery with conditions) and returns the resulting events, flagged as
+// history.
+func (p *PostgresPersist) queryEvents(query string, args ...interface{}) ([]*types.Event, error) {
+	events := make([]*types.Event, 0)
+	rows, err := p.db.Query(query, args...)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
@@ -350,7 +566,7 @@ WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.created, e.created_
 		var sourceUserLastOnline sql.NullTime
 		var event types.Event
 		event.Source = &types.Source{}
-		err = rows.Scan(&event.Id, &newRoom.Id, &sourceUserId, &event.Source.PluginName, &event.Name, &event.Language, &rawEventTags, &event.TargetFilter, &event.Created, &event.Sent, &owner.Id, &rawRoomTags, &sourceUserNick, &sourceUserLanguage, &sourceUserLastOnline, &rawSourceUserTags, &owner.Nick, &owner.Language, &owner.LastOnline, &rawRoomOwnerTags)
+		err = rows.Scan(&event.Id, &newRoom.Id, &sourceUserId, &event.Source.PluginName, &event.Source.Bot, &event.Name, &event.Language, &rawEventTags, &event.TargetFilter, &event.Created, &event.Sent, &event.Seq, &owner.Id, &rawRoomTags, &sourceUserNick, &sourceUserLanguage, &sourceUserLastOnline, &rawSourceUserTags, &owner.Nick, &owner.Language, &owner.LastOnline, &rawRoomOwnerTags)
 		if err != nil {
 			return nil, err
 		}
@@ -61,6 +61,40 @@ id TEXT PRIMARY KEY,
 owner_id TEXT NOT NULL,
 tags TEXT DEFAULT "{}" NOT NULL,
 FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS memberships (
+room_id TEXT NOT NULL,
+user_id TEXT NOT NULL,
+role TEXT NOT NULL,
+PRIMARY KEY (room_id, user_id),
+FOREIGN KEY (room_id) REFERENCES rooms (id) ON DELETE CASCADE ON UPDATE CASCADE,
+FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS revoked_tokens (
+id TEXT PRIMARY KEY,
+expires_at INTEGER DEFAULT 0 NOT NULL,
+created_at INTEGER DEFAULT 0 NOT NULL
+);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE TABLE IF NOT EXISTS bots (
+user_id TEXT PRIMARY KEY,
+key_hash TEXT NOT NULL,
+events TEXT DEFAULT "" NOT NULL,
+messages INTEGER DEFAULT 0 NOT NULL,
+interval_ns INTEGER DEFAULT 0 NOT NULL,
+created_at INTEGER DEFAULT 0 NOT NULL,
+FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
 );`
 	_, err = db.Exec(query)
 	if err != nil {
@@ -78,6 +112,8 @@ target_filter TEXT DEFAULT "" NOT NULL,
 created INTEGER DEFAULT 0 NOT NULL,
 created_sort INTEGER DEFAULT 0 NOT NULL,
 sent INTEGER DEFAULT 0 NOT NULL,
+seq INTEGER DEFAULT 0 NOT NULL,
+bot INTEGER DEFAULT 0 NOT NULL,
 FOREIGN KEY (room_id) REFERENCES rooms (id) ON DELETE CASCADE ON UPDATE CASCADE,
 FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE CASCADE
 );`
@@ -90,9 +126,47 @@ FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE CASCADE
 	if err != nil {
 		return nil, err
 	}
+	// databases created before events had sequence numbers lack the seq column
+	err = addSQLiteColumn(db, "events", "seq", "INTEGER DEFAULT 0 NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	query = `CREATE INDEX IF NOT EXISTS events_seq_idx ON events (room_id, seq);`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	// databases created before bots were introduced lack the bot column
+	err = addSQLiteColumn(db, "events", "bot", "INTEGER DEFAULT 0 NOT NULL")
+	if err != nil {
+		return nil, err
+	}
 	return db, err
 }
 
+// addSQLiteColumn adds the column to the table if it does not exist yet.
+func addSQLiteColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?);`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s;`, table, column, definition))
+	return err
+}
+
 func (p *SQLitePersist) StoreUser(user types.User) error {
 	p.RWMutex.Lock()
 	defer p.RWMutex.Unlock()
@@ -363,6 +437,177 @@ func (p *SQLitePersist) UpdateRoomTags(room *types.Room, updates []*types.TagUpd
 	return resOk, nil
 }
 
+func (p *SQLitePersist) StoreMembership(membership types.Membership) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	query := `INSERT INTO memberships (room_id,user_id,role) VALUES (?,?,?) ON CONFLICT (room_id,user_id) DO UPDATE SET role=EXCLUDED.role;`
+	_, err := p.db.Exec(query, membership.RoomId, membership.UserId, membership.Role)
+	return err
+}
+
+func (p *SQLitePersist) GetMembership(membership *types.Membership) error {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	query := `SELECT role FROM memberships WHERE room_id=? AND user_id=?;`
+	return p.db.QueryRow(query, membership.RoomId, membership.UserId).Scan(&membership.Role)
+}
+
+func (p *SQLitePersist) GetMemberships(room *types.Room) ([]*types.Membership, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	memberships := make([]*types.Membership, 0)
+	query := `SELECT user_id,role FROM memberships WHERE room_id=?;`
+	rows, err := p.db.Query(query, room.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		membership := types.Membership{RoomId: room.Id}
+		err = rows.Scan(&membership.UserId, &membership.Role)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, &membership)
+	}
+	return memberships, rows.Err()
+}
+
+func (p *SQLitePersist) DeleteMembership(membership *types.Membership) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	query := `DELETE FROM memberships WHERE room_id=? AND user_id=?;`
+	_, err := p.db.Exec(query, membership.RoomId, membership.UserId)
+	return err
+}
+
+func (p *SQLitePersist) RevokeToken(token types.RevokedToken) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	var expiresAt int64 // 0: never
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = token.ExpiresAt.Unix()
+	}
+	query := `INSERT INTO revoked_tokens (id,expires_at,created_at) VALUES (?,?,?) ON CONFLICT (id) DO UPDATE SET expires_at=EXCLUDED.expires_at;`
+	_, err := p.db.Exec(query, token.Id, expiresAt, time.Now().Unix())
+	return err
+}
+
+func (p *SQLitePersist) IsTokenRevoked(id string) (bool, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	var count int64
+	query := `SELECT COUNT(*) FROM revoked_tokens WHERE id=?;`
+	err := p.db.QueryRow(query, id).Scan(&count)
+	return count > 0, err
+}
+
+func (p *SQLitePersist) DeleteExpiredRevokedTokens(now time.Time) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	query := `DELETE FROM revoked_tokens WHERE expires_at > 0 AND expires_at < ?;`
+	_, err := p.db.Exec(query, now.Unix())
+	return err
+}
+
+func (p *SQLitePersist) StoreBot(bot types.Bot) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	query := `INSERT INTO bots (user_id,key_hash,events,messages,interval_ns,created_at) VALUES (?,?,?,?,?,?) ON CONFLICT (user_id) DO UPDATE SET key_hash=EXCLUDED.key_hash,events=EXCLUDED.events,messages=EXCLUDED.messages,interval_ns=EXCLUDED.interval_ns;`
+	_, err := p.db.Exec(query, bot.UserId, bot.KeyHash, bot.Events, bot.Messages, int64(bot.Interval), time.Now().Unix())
+	return err
+}
+
+func (p *SQLitePersist) GetBot(bot *types.Bot) error {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	var interval, createdAt int64
+	query := `SELECT key_hash,events,messages,interval_ns,created_at FROM bots WHERE user_id=?;`
+	err := p.db.QueryRow(query, bot.UserId).Scan(&bot.KeyHash, &bot.Events, &bot.Messages, &interval, &createdAt)
+	if err != nil {
+		return err
+	}
+	bot.Interval = time.Duration(interval)
+	bot.CreatedAt = time.Unix(createdAt, 0)
+	return nil
+}
+
+func (p *SQLitePersist) GetBots() ([]*types.Bot, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	bots := make([]*types.Bot, 0)
+	query := `SELECT user_id,key_hash,events,messages,interval_ns,created_at FROM bots;`
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		bot := types.Bot{}
+		var interval, createdAt int64
+		err = rows.Scan(&bot.UserId, &bot.KeyHash, &bot.Events, &bot.Messages, &interval, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		bot.Interval = time.Duration(interval)
+		bot.CreatedAt = time.Unix(createdAt, 0)
+		bots = append(bots, &bot)
+	}
+	return bots, rows.Err()
+}
+
+func (p *SQLitePersist) DeleteBot(bot *types.Bot) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	query := `DELETE FROM bots WHERE user_id=?;`
+	_, err := p.db.Exec(query, bot.UserId)
+	return err
+}
+
 func (p *SQLitePersist) StoreEvents(_ *types.Room, events []*types.Event) error {
 	p.RWMutex.Lock()
 	defer p.RWMutex.Unlock()
@@ -374,7 +619,7 @@ func (p *SQLitePersist) StoreEvents(_ *types.Room, events []*types.Event) error
 	if err != nil {
 		return err
 	}
-	query := `INSERT INTO events (id,room_id,user_id,plugin_name,name,language,tags,target_filter,created,created_sort,sent) VALUES (?,?,?,?,?,?,?,?,?,?,?) ON CONFLICT (id) DO NOTHING;`
+	query := `INSERT INTO events (id,room_id,user_id,plugin_name,bot,name,language,tags,target_filter,created,created_sort,sent,seq) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?) ON CONFLICT (id) DO NOTHING;`
 	for _, event := range events {
 		if event.Tags == nil {
 			event.Tags = make(map[string]string)
@@ -390,7 +635,7 @@ func (p *SQLitePersist) StoreEvents(_ *types.Room, events []*types.Event) error
 			uid.String = event.Source.User.Id
 		}
 		sort := event.Created.Nanosecond()
-		_, err = tx.Exec(query, event.Id, event.Room.Id, uid, event.Source.PluginName, event.Name, event.Language, tags, event.TargetFilter, event.Created.Unix(), sort, event.Sent.Unix())
+		_, err = tx.Exec(query, event.Id, event.Room.Id, uid, event.Source.PluginName, event.Source.Bot, event.Name, event.Language, tags, event.TargetFilter, event.Created.Unix(), sort, event.Sent.Unix(), event.Seq)
 		if err != nil {
 			_ = tx.Rollback()
 			return err
@@ -414,14 +659,110 @@ func (p *SQLitePersist) GetEventHistory(room *types.Room, fromTs, toTs time.Time
 	if room == nil {
 		return nil, fmt.Errorf("no room")
 	}
-	events := make([]*types.Event, 0)
 	from := fromTs.Unix()
 	to := toTs.Unix()
-	query := `SELECT e.id,e.room_id,e.user_id,e.plugin_name,e.name,e.language,e.tags,e.target_filter,e.created,e.sent,r.owner_id,r.tags,
+	query := sqliteEventsQuery + `
+WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.seq DESC, e.created DESC, e.created_sort DESC LIMIT ? OFFSET ?;`
+	return p.queryEvents(query, room.Id, from, to, maxCount, fromIdx)
+}
+
+// GetEventsAfterSeq returns at most maxCount events of the room with a sequence number greater than seq, ordered by
+// their sequence number.
+func (p *SQLitePersist) GetEventsAfterSeq(room *types.Room, seq uint64, maxCount int) ([]*types.Event, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	if room == nil {
+		return nil, fmt.Errorf("no room")
+	}
+	query := sqliteEventsQuery + `
+WHERE r.id=? AND e.seq > ? ORDER BY e.seq LIMIT ?;`
+	return p.queryEvents(query, room.Id, seq, maxCount)
+}
+
+// GetLastEventSeq returns the highest sequence number of the stored events of the room (0 if there are none).
+func (p *SQLitePersist) GetLastEventSeq(room *types.Room) (uint64, error) {
+	p.RWMutex.RLock()
+	defer p.RWMutex.RUnlock()
+	if p.Flock != nil {
+		p.Flock.RLock()
+		defer p.Flock.Unlock()
+	}
+	if room == nil {
+		return 0, fmt.Errorf("no room")
+	}
+	var seq uint64
+	err := p.db.QueryRow(`SELECT COALESCE(MAX(seq),0) FROM events WHERE room_id=?;`, room.Id).Scan(&seq)
+	return seq, err
+}
+
+// AssignEventSeqs numbers the stored events of the room which have no sequence number yet (events stored before
+// sequence numbers were introduced). Those are older than the numbered events, so all events of the room are
+// renumbered: first the unnumbered ones by their creation time, then the numbered ones in their order.
+func (p *SQLitePersist) AssignEventSeqs(room *types.Room) error {
+	p.RWMutex.Lock()
+	defer p.RWMutex.Unlock()
+	if p.Flock != nil {
+		p.Flock.Lock()
+		defer p.Flock.Unlock()
+	}
+	if room == nil {
+		return fmt.Errorf("no room")
+	}
+	var count int64
+	err := p.db.QueryRow(`SELECT COUNT(*) FROM events WHERE room_id=? AND seq=0;`, room.Id).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+	tx, err := p.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	ids, err := queryIds(tx, `SELECT id FROM events WHERE room_id=? ORDER BY seq>0, seq, created, created_sort, id;`, room.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for i, id := range ids {
+		_, err = tx.Exec(`UPDATE events SET seq=? WHERE id=?;`, i+1, id)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// queryIds returns the ids selected by the query.
+func queryIds(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+const sqliteEventsQuery = `SELECT e.id,e.room_id,e.user_id,e.plugin_name,e.bot,e.name,e.language,e.tags,e.target_filter,e.created,e.created_sort,e.sent,e.seq,r.owner_id,r.tags,
        u.nick,u.language,u.last_online,u.tags,o.nick,o.language,o.last_online,o.tags
-FROM events AS e INNER JOIN (rooms AS r INNER JOIN users AS o ON o.id=r.owner_id) ON r.id=e.room_id LEFT JOIN users AS u ON u.id=e.user_id
-WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.created, e.created_sort DESC LIMIT ? OFFSET ?;`
-	rows, err := p.db.Query(query, room.Id, from, to, maxCount, fromIdx)
+FROM events AS e INNER JOIN (rooms AS r INNER JOIN users AS o ON o.id=r.owner_id) ON r.id=e.room_id LEFT JOIN users AS u ON u.id=e.user_id`
+
+// queryEvents runs the query (sqliteEventsQuery with conditions) and returns the resulting events, flagged as history.
+// The caller holds the locks.
+func (p *SQLitePersist) queryEvents(query string, args ...interface{}) ([]*types.Event, error) {
+	events := make([]*types.Event, 0)
+	rows, err := p.db.Query(query, args...)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
@@ -432,12 +773,12 @@ WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.created, e.created_
 		var newRoom types.Room
 		var rawSourceUserTags sql.NullString
 		var rawRoomOwnerTags, rawRoomTags, rawEventTags string
-		var created, sent int64
+		var created, createdSort, sent int64
 		var sourceUserLastOnline sql.NullInt64
 		var ownerLastOnline int64
 		var event types.Event
 		event.Source = &types.Source{}
-		err = rows.Scan(&event.Id, &newRoom.Id, &sourceUserId, &event.Source.PluginName, &event.Name, &event.Language, &rawEventTags, &event.TargetFilter, &created, &sent, &owner.Id, &rawRoomTags, &sourceUserNick, &sourceUserLanguage, &sourceUserLastOnline, &rawSourceUserTags, &owner.Nick, &owner.Language, &ownerLastOnline, &rawRoomOwnerTags)
+		err = rows.Scan(&event.Id, &newRoom.Id, &sourceUserId, &event.Source.PluginName, &event.Source.Bot, &event.Name, &event.Language, &rawEventTags, &event.TargetFilter, &created, &createdSort, &sent, &event.Seq, &owner.Id, &rawRoomTags, &sourceUserNick, &sourceUserLanguage, &sourceUserLastOnline, &rawSourceUserTags, &owner.Nick, &owner.Language, &ownerLastOnline, &rawRoomOwnerTags)
 		if err != nil {
 			return nil, err
 		}
@@ -461,7 +802,7 @@ WHERE r.id=? AND e.created >= ? AND e.created < ? ORDER BY e.created, e.created_
 		sourceUser.LastOnline = time.Unix(sourceUserLastOnline.Int64, 0)
 		owner.LastOnline = time.Unix(ownerLastOnline, 0)
 		newRoom.Owner = &owner
-		event.Created = time.Unix(created, 0)
+		event.Created = time.Unix(created, createdSort)
 		event.Sent = time.Unix(sent, 0)
 		event.Room = &newRoom
 		event.Source.User = &sourceUser
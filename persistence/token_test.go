@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestRevokedTokens(t *testing.T) {
+	for name, newPersister := range testPersisters(t) {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPersister()
+			if !assert.NoError(t, err) || !assert.NotNil(t, p) {
+				return
+			}
+			defer p.Close()
+			now := time.Now()
+			assert.NoError(t, p.RevokeToken(types.RevokedToken{Id: "expiring", ExpiresAt: now.Add(time.Hour)}))
+			assert.NoError(t, p.RevokeToken(types.RevokedToken{Id: "forever"}))
+
+			for _, id := range []string{"expiring", "forever"} {
+				revoked, err := p.IsTokenRevoked(id)
+				assert.NoError(t, err)
+				assert.True(t, revoked, id)
+			}
+			revoked, err := p.IsTokenRevoked("unknown")
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+
+			if name != "buntdb" { // BuntDB expires the entries itself
+				assert.NoError(t, p.DeleteExpiredRevokedTokens(now.Add(2*time.Hour)))
+				revoked, err = p.IsTokenRevoked("expiring")
+				assert.NoError(t, err)
+				assert.False(t, revoked)
+			}
+			revoked, err = p.IsTokenRevoked("forever")
+			assert.NoError(t, err)
+			assert.True(t, revoked)
+		})
+	}
+}
@@ -10,6 +10,9 @@ import (
 type Persister interface {
 	StoreEvents(*types.Room, []*types.Event) error
 	GetEventHistory(*types.Room, time.Time, time.Time, int, int) ([]*types.Event, error)
+	GetEventsAfterSeq(*types.Room, uint64, int) ([]*types.Event, error)
+	GetLastEventSeq(*types.Room) (uint64, error)
+	AssignEventSeqs(*types.Room) error
 	StoreUser(types.User) error
 	GetUser(*types.User) error
 	GetUsers() ([]*types.User, error)
@@ -20,6 +23,17 @@ type Persister interface {
 	GetRooms() ([]*types.Room, error)
 	UpdateRoomTags(*types.Room, []*types.TagUpdate) ([]bool, error)
 	DeleteRoom(*types.Room) error
+	StoreMembership(types.Membership) error
+	GetMembership(*types.Membership) error
+	GetMemberships(*types.Room) ([]*types.Membership, error)
+	DeleteMembership(*types.Membership) error
+	RevokeToken(types.RevokedToken) error
+	IsTokenRevoked(string) (bool, error)
+	DeleteExpiredRevokedTokens(time.Time) error
+	StoreBot(types.Bot) error
+	GetBot(*types.Bot) error
+	GetBots() ([]*types.Bot, error)
+	DeleteBot(*types.Bot) error
 	Close() error
 }
 
@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	ArgTypeString = "string" // a single word (default)
+	ArgTypeInt    = "int"    // an integer
+	ArgTypeFloat  = "float"  // a floating point number
+	ArgTypeText   = "text"   // the rest of the message including whitespace, only allowed as the last argument
+)
+
+// CommandArg describes an argument of a command.
+type CommandArg struct {
+	Name        string // the parsed value is passed to the plugin in Tags["arg.<name>"]
+	Type        string // ArgTypeString, ArgTypeInt, ArgTypeFloat or ArgTypeText
+	Description string
+	Optional    bool // optional arguments must follow the required ones
+}
+
+// CommandSpec describes a command a plugin registers via Configure. The server parses and validates the arguments
+// and routes the command only to the plugin which registered it.
+type CommandSpec struct {
+	Name        string // the command including the leading "/", f.e. "/to"
+	Description string // shown by /help
+	Permission  string // filter expression evaluated for the command event (f.e. `Source.User.Tags["moderator"] == "true"`), everyone may use the command if empty
+	Args        []CommandArg
+}
+
+// Validate checks the command specification.
+func (c CommandSpec) Validate() error {
+	if len(c.Name) < 2 || !strings.HasPrefix(c.Name, "/") || strings.IndexFunc(c.Name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid command name: %q", c.Name)
+	}
+	names := make(map[string]struct{})
+	optional := false
+	for i, arg := range c.Args {
+		if arg.Name == "" {
+			return fmt.Errorf("command %s: argument %d has no name", c.Name, i+1)
+		}
+		if _, ok := names[arg.Name]; ok {
+			return fmt.Errorf("command %s: duplicate argument %s", c.Name, arg.Name)
+		}
+		names[arg.Name] = struct{}{}
+		switch arg.Type {
+		case "", ArgTypeString, ArgTypeInt, ArgTypeFloat:
+		case ArgTypeText:
+			if i != len(c.Args)-1 {
+				return fmt.Errorf("command %s: argument %s of type text must be the last argument", c.Name, arg.Name)
+			}
+		default:
+			return fmt.Errorf("command %s: argument %s has an invalid type: %s", c.Name, arg.Name, arg.Type)
+		}
+		if optional && !arg.Optional {
+			return fmt.Errorf("command %s: required argument %s follows an optional one", c.Name, arg.Name)
+		}
+		optional = arg.Optional
+	}
+	return nil
+}
+
+// Usage returns the usage of the command, f.e. "/to <nick> <message...>".
+func (c CommandSpec) Usage() string {
+	var sb strings.Builder
+	sb.WriteString(c.Name)
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Type == ArgTypeText {
+			name += "..."
+		}
+		if arg.Optional {
+			sb.WriteString(" [" + name + "]")
+		} else {
+			sb.WriteString(" <" + name + ">")
+		}
+	}
+	return sb.String()
+}
+
+// ParseArgs parses the text following the command name according to the argument specification. The result maps the
+// argument names to their values, missing optional arguments are left out.
+func (c CommandSpec) ParseArgs(text string) (map[string]string, error) {
+	res := make(map[string]string)
+	text = strings.TrimSpace(text)
+	for _, arg := range c.Args {
+		if text == "" {
+			if !arg.Optional {
+				return nil, fmt.Errorf("missing argument %s", arg.Name)
+			}
+			break
+		}
+		value := text
+		if arg.Type == ArgTypeText {
+			text = ""
+		} else {
+			if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+				value = text[:i]
+			}
+			text = strings.TrimSpace(text[len(value):])
+		}
+		switch arg.Type {
+		case ArgTypeInt:
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("argument %s must be an integer", arg.Name)
+			}
+		case ArgTypeFloat:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("argument %s must be a number", arg.Name)
+			}
+		}
+		res[arg.Name] = value
+	}
+	if text != "" {
+		return nil, fmt.Errorf("too many arguments")
+	}
+	return res, nil
+}
@@ -0,0 +1,42 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandSpecValidate(t *testing.T) {
+	assert.NoError(t, CommandSpec{Name: "/to", Args: []CommandArg{{Name: "nick"}, {Name: "message", Type: ArgTypeText}}}.Validate())
+	assert.Error(t, CommandSpec{Name: "to"}.Validate())
+	assert.Error(t, CommandSpec{Name: "/t o"}.Validate())
+	assert.Error(t, CommandSpec{Name: "/x", Args: []CommandArg{{Name: "a", Type: ArgTypeText}, {Name: "b"}}}.Validate())
+	assert.Error(t, CommandSpec{Name: "/x", Args: []CommandArg{{Name: "a", Optional: true}, {Name: "b"}}}.Validate())
+	assert.Error(t, CommandSpec{Name: "/x", Args: []CommandArg{{Name: "a"}, {Name: "a"}}}.Validate())
+	assert.Error(t, CommandSpec{Name: "/x", Args: []CommandArg{{Name: "a", Type: "bool"}}}.Validate())
+}
+
+func TestCommandSpecParseArgs(t *testing.T) {
+	spec := CommandSpec{Name: "/to", Args: []CommandArg{{Name: "nick"}, {Name: "message", Type: ArgTypeText}}}
+	assert.Equal(t, "/to <nick> <message...>", spec.Usage())
+	args, err := spec.ParseArgs(" bob  hello   world ")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"nick": "bob", "message": "hello   world"}, args)
+	_, err = spec.ParseArgs("bob")
+	assert.Error(t, err)
+
+	spec = CommandSpec{Name: "/roll", Args: []CommandArg{{Name: "sides", Type: ArgTypeInt, Optional: true}, {Name: "factor", Type: ArgTypeFloat, Optional: true}}}
+	assert.Equal(t, "/roll [sides] [factor]", spec.Usage())
+	args, err = spec.ParseArgs("")
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+	args, err = spec.ParseArgs("6 1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"sides": "6", "factor": "1.5"}, args)
+	_, err = spec.ParseArgs("six")
+	assert.Error(t, err)
+	_, err = spec.ParseArgs("6 x")
+	assert.Error(t, err)
+	_, err = spec.ParseArgs("6 1 2")
+	assert.Error(t, err)
+}
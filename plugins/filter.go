@@ -0,0 +1,43 @@
+package plugins
+
+import "github.com/tcriess/lightspeed-chat/types"
+
+// Make sure those constants are the same as defined in the proto enum FilterResult.Action!
+const (
+	FilterActionUnchanged = 0
+	FilterActionModified  = 1
+	FilterActionRejected  = 2
+)
+
+// FilterResult is the verdict of an interceptor plugin on a single event (see EventHandler.FilterEvents).
+type FilterResult struct {
+	Action int          // one of the FilterAction* consts
+	Event  *types.Event // the modified event, only the Tags, the TargetFilter and the Language (if not empty) are taken over (FilterActionModified only)
+	Reason string       // reason for the rejection, it is sent to the sender of the event (FilterActionRejected only)
+}
+
+// Unchanged lets the event pass as it is.
+func Unchanged() *FilterResult {
+	return &FilterResult{Action: FilterActionUnchanged}
+}
+
+// Modified replaces the Tags, the TargetFilter and the Language (unless it is empty) of the original event by the ones
+// of event.
+func Modified(event *types.Event) *FilterResult {
+	return &FilterResult{Action: FilterActionModified, Event: event}
+}
+
+// Rejected prevents the event from being broadcast or stored, the reason is sent to the sender.
+func Rejected(reason string) *FilterResult {
+	return &FilterResult{Action: FilterActionRejected, Reason: reason}
+}
+
+// PassAll returns a slice of results letting all events pass unchanged, it is the FilterEvents implementation for
+// observer plugins.
+func PassAll(events []*types.Event) []*FilterResult {
+	results := make([]*FilterResult, len(events))
+	for i := range events {
+		results[i] = Unchanged()
+	}
+	return results
+}
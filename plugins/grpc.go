@@ -20,6 +20,11 @@ type GRPCClient struct {
 	client proto.EventHandlerClient
 }
 
+// EventToProto returns the protobuf representation of the event, which is also used by the binary websocket encoding.
+func EventToProto(event *types.Event) *proto.Event {
+	return eventNative2Proto(event)
+}
+
 func eventNative2Proto(inEvent *types.Event) *proto.Event {
 	outEvent := &proto.Event{
 		Id:   inEvent.Id,
@@ -27,6 +32,8 @@ func eventNative2Proto(inEvent *types.Event) *proto.Event {
 		Source: &proto.Source{
 			User:       userNative2Proto(inEvent.Source.User),
 			PluginName: inEvent.Source.PluginName,
+			Role:       inEvent.Source.Role,
+			Bot:        inEvent.Source.Bot,
 		},
 		Created:      inEvent.Created.Unix(),
 		Language:     inEvent.Language,
@@ -35,6 +42,9 @@ func eventNative2Proto(inEvent *types.Event) *proto.Event {
 		Sent:         inEvent.Sent.Unix(),
 		TargetFilter: inEvent.TargetFilter,
 		History:      inEvent.History,
+		CreatedNs:    unixNano(inEvent.Created),
+		SentNs:       unixNano(inEvent.Sent),
+		Seq:          inEvent.Seq,
 	}
 
 	return outEvent
@@ -47,19 +57,39 @@ func eventProto2Native(inEvent *proto.Event) *types.Event {
 		Source: &types.Source{
 			User:       userProto2Native(inEvent.Source.User),
 			PluginName: inEvent.Source.PluginName,
+			Role:       inEvent.Source.Role,
+			Bot:        inEvent.Source.Bot,
 		},
-		Created:      time.Unix(inEvent.Created, 0).In(time.UTC),
+		Created:      protoTime(inEvent.Created, inEvent.CreatedNs),
 		Language:     inEvent.Language,
 		Name:         inEvent.Name,
 		Tags:         inEvent.Tags,
-		Sent:         time.Unix(inEvent.Sent, 0).In(time.UTC),
+		Sent:         protoTime(inEvent.Sent, inEvent.SentNs),
 		TargetFilter: inEvent.TargetFilter,
 		History:      inEvent.History,
+		Seq:          inEvent.Seq,
 	}
 
 	return outEvent
 }
 
+// unixNano returns t in Unix nanoseconds, or 0 for the zero time (which cannot be represented).
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// protoTime returns the time of a protobuf event: the nanoseconds if set, otherwise the seconds (plugins built before
+// the nanosecond fields were added only set the seconds).
+func protoTime(seconds, nanoseconds int64) time.Time {
+	if nanoseconds != 0 {
+		return time.Unix(0, nanoseconds).In(time.UTC)
+	}
+	return time.Unix(seconds, 0).In(time.UTC)
+}
+
 func userNative2Proto(inUser *types.User) *proto.User {
 	outUser := &proto.User{
 		Id:         inUser.Id,
@@ -67,6 +97,7 @@ func userNative2Proto(inUser *types.User) *proto.User {
 		Language:   inUser.Language,
 		Tags:       inUser.Tags,
 		LastOnline: inUser.LastOnline.Unix(),
+		IsGuest:    inUser.IsGuest,
 	}
 
 	return outUser
@@ -79,6 +110,7 @@ func userProto2Native(inUser *proto.User) *types.User {
 		Language:   inUser.Language,
 		Tags:       inUser.Tags,
 		LastOnline: time.Unix(inUser.LastOnline, 0).In(time.UTC),
+		IsGuest:    inUser.IsGuest,
 	}
 
 	return outUser
@@ -90,7 +122,7 @@ func roomNative2Proto(inRoom *types.Room) *proto.Room {
 		Owner: userNative2Proto(inRoom.Owner),
 		Tags:  inRoom.Tags,
 	}
-	globals.AppLogger.Debug("converted native to proto:", "native", *inRoom, "proto", *outRoom)
+	globals.AppLogger.Debug("converted native to proto:", "native", inRoom, "proto", outRoom)
 	return outRoom
 }
 
@@ -100,7 +132,7 @@ func roomProto2Native(inRoom *proto.Room) *types.Room {
 		Owner: userProto2Native(inRoom.Owner),
 		Tags:  inRoom.Tags,
 	}
-	globals.AppLogger.Debug("converted proto to native:", "proto", *inRoom, "native", *outRoom)
+	globals.AppLogger.Debug("converted proto to native:", "proto", inRoom, "native", outRoom)
 	return outRoom
 }
 
@@ -130,13 +162,87 @@ func tagUpdatesProto2Native(updates []*proto.TagUpdate) []*types.TagUpdate {
 	return outTagUpdates
 }
 
-func (c *GRPCClient) HandleEvents(inEvents []*types.Event) ([]*types.Event, error) {
+func filterResultsNative2Proto(results []*FilterResult) []*proto.FilterResult {
+	outResults := make([]*proto.FilterResult, len(results))
+	for i, result := range results {
+		outResult := &proto.FilterResult{
+			Action: proto.FilterResult_Action(result.Action),
+			Reason: result.Reason,
+		}
+		if result.Event != nil {
+			outResult.Event = eventNative2Proto(result.Event)
+		}
+		outResults[i] = outResult
+	}
+	return outResults
+}
+
+func filterResultsProto2Native(results []*proto.FilterResult) []*FilterResult {
+	outResults := make([]*FilterResult, len(results))
+	for i, result := range results {
+		outResult := &FilterResult{
+			Action: int(result.Action),
+			Reason: result.Reason,
+		}
+		if result.Event != nil {
+			outResult.Event = eventProto2Native(result.Event)
+		}
+		outResults[i] = outResult
+	}
+	return outResults
+}
+
+func commandSpecsNative2Proto(specs []CommandSpec) []*proto.CommandSpec {
+	outSpecs := make([]*proto.CommandSpec, len(specs))
+	for i, spec := range specs {
+		args := make([]*proto.CommandArg, len(spec.Args))
+		for j, arg := range spec.Args {
+			args[j] = &proto.CommandArg{
+				Name:        arg.Name,
+				Type:        arg.Type,
+				Description: arg.Description,
+				Optional:    arg.Optional,
+			}
+		}
+		outSpecs[i] = &proto.CommandSpec{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Permission:  spec.Permission,
+			Args:        args,
+		}
+	}
+	return outSpecs
+}
+
+func commandSpecsProto2Native(specs []*proto.CommandSpec) []CommandSpec {
+	outSpecs := make([]CommandSpec, len(specs))
+	for i, spec := range specs {
+		args := make([]CommandArg, len(spec.Args))
+		for j, arg := range spec.Args {
+			args[j] = CommandArg{
+				Name:        arg.Name,
+				Type:        arg.Type,
+				Description: arg.Description,
+				Optional:    arg.Optional,
+			}
+		}
+		outSpecs[i] = CommandSpec{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Permission:  spec.Permission,
+			Args:        args,
+		}
+	}
+	return outSpecs
+}
+
+func (c *GRPCClient) HandleEvents(ctx context.Context, inEvents []*types.Event) ([]*types.Event, error) {
 	events := make([]*proto.Event, len(inEvents))
 	for i, inEvent := range inEvents {
 		events[i] = eventNative2Proto(inEvent)
 	}
 	req := &proto.HandleEventsRequest{Events: events}
-	resp, err := c.client.HandleEvents(context.Background(), req)
+	resp, err := c.client.HandleEvents(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -147,22 +253,41 @@ func (c *GRPCClient) HandleEvents(inEvents []*types.Event) ([]*types.Event, erro
 	return outEvents, nil
 }
 
-func (c *GRPCClient) Configure(val map[string]interface{}) (string, string, error) {
+func (c *GRPCClient) FilterEvents(ctx context.Context, inEvents []*types.Event) ([]*FilterResult, error) {
+	events := make([]*proto.Event, len(inEvents))
+	for i, inEvent := range inEvents {
+		events[i] = eventNative2Proto(inEvent)
+	}
+	req := &proto.FilterEventsRequest{Events: events}
+	resp, err := c.client.FilterEvents(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return filterResultsProto2Native(resp.Results), nil
+}
+
+func (c *GRPCClient) Configure(ctx context.Context, val map[string]interface{}) (Configuration, error) {
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 	err := enc.Encode(val)
 	if err != nil {
-		return "", "", err
+		return Configuration{}, err
 	}
-	resp, err := c.client.Configure(context.Background(), &proto.ConfigureRequest{Data: buf.Bytes()})
+	resp, err := c.client.Configure(ctx, &proto.ConfigureRequest{Data: buf.Bytes()})
 	if err != nil {
-		return "", "", err
+		return Configuration{}, err
 	}
-	return resp.CronSpec, resp.EventsFilter, err
+	return Configuration{
+		CronSpec:     resp.CronSpec,
+		EventsFilter: resp.EventsFilter,
+		Priority:     int(resp.Priority),
+		Kind:         int(resp.Kind),
+		Commands:     commandSpecsProto2Native(resp.Commands),
+	}, nil
 }
 
-func (c *GRPCClient) Cron(room *types.Room) ([]*types.Event, error) {
-	resp, err := c.client.Cron(context.Background(), &proto.CronRequest{
+func (c *GRPCClient) Cron(ctx context.Context, room *types.Room) ([]*types.Event, error) {
+	resp, err := c.client.Cron(ctx, &proto.CronRequest{
 		Room: roomNative2Proto(room),
 	})
 	if err != nil {
@@ -177,8 +302,8 @@ func (c *GRPCClient) Cron(room *types.Room) ([]*types.Event, error) {
 
 // InitEmitEvents is called once per hub from the main process and it opens a permanent data stream
 // from the plugin to the main process (via GRPC/ EmitEventsHelper).
-// It must be called from a go routine as it never returns.
-func (c *GRPCClient) InitEmitEvents(room *types.Room, eh EmitEventsHelper) error {
+// It must be called from a go routine as it does not return before ctx is done.
+func (c *GRPCClient) InitEmitEvents(ctx context.Context, room *types.Room, eh EmitEventsHelper) error {
 	emitEventsServer := &GRPCEmitEventsHelperServer{Impl: eh}
 
 	var wg sync.WaitGroup
@@ -196,7 +321,7 @@ func (c *GRPCClient) InitEmitEvents(room *types.Room, eh EmitEventsHelper) error
 
 	wg.Wait()
 	// this is supposed to run forever! if it stops, the main process should call here again.
-	_, err := c.client.InitEmitEvents(context.Background(), &proto.InitEmitEventsRequest{
+	_, err := c.client.InitEmitEvents(ctx, &proto.InitEmitEventsRequest{
 		EmitEventsServer: brokerID,
 		Room:             roomNative2Proto(room),
 	})
@@ -220,7 +345,7 @@ func (s *GRPCServer) HandleEvents(ctx context.Context, req *proto.HandleEventsRe
 	for i, event := range req.Events {
 		inEvents[i] = eventProto2Native(event)
 	}
-	outEvents, err := s.Impl.HandleEvents(inEvents)
+	outEvents, err := s.Impl.HandleEvents(ctx, inEvents)
 	if err != nil {
 		return nil, err
 	}
@@ -239,16 +364,34 @@ func (s *GRPCServer) Configure(ctx context.Context, req *proto.ConfigureRequest)
 	if err != nil {
 		return nil, err
 	}
-	cronSpec, eventsFilter, err := s.Impl.Configure(val)
+	cfg, err := s.Impl.Configure(ctx, val)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ConfigureResponse{
+		CronSpec:     cfg.CronSpec,
+		EventsFilter: cfg.EventsFilter,
+		Priority:     int32(cfg.Priority),
+		Kind:         proto.ConfigureResponse_Kind(cfg.Kind),
+		Commands:     commandSpecsNative2Proto(cfg.Commands),
+	}, nil
+}
+
+func (s *GRPCServer) FilterEvents(ctx context.Context, req *proto.FilterEventsRequest) (*proto.FilterEventsResponse, error) {
+	inEvents := make([]*types.Event, len(req.Events))
+	for i, event := range req.Events {
+		inEvents[i] = eventProto2Native(event)
+	}
+	results, err := s.Impl.FilterEvents(ctx, inEvents)
 	if err != nil {
 		return nil, err
 	}
-	return &proto.ConfigureResponse{CronSpec: cronSpec, EventsFilter: eventsFilter}, nil
+	return &proto.FilterEventsResponse{Results: filterResultsNative2Proto(results)}, nil
 }
 
 func (s *GRPCServer) Cron(ctx context.Context, req *proto.CronRequest) (*proto.CronResponse, error) {
 	room := roomProto2Native(req.Room)
-	outEvents, err := s.Impl.Cron(room)
+	outEvents, err := s.Impl.Cron(ctx, room)
 	if err != nil {
 		return nil, err
 	}
@@ -269,7 +412,7 @@ func (s *GRPCServer) InitEmitEvents(ctx context.Context, req *proto.InitEmitEven
 	room := roomProto2Native(req.Room)
 
 	c := &GRPCEmitEventsHelperClient{client: proto.NewEmitEventsHelperClient(conn)}
-	err = s.Impl.InitEmitEvents(room, c) // this is supposed to run forever
+	err = s.Impl.InitEmitEvents(ctx, room, c) // this is supposed to run until ctx is done
 	if err != nil {
 		return nil, err
 	}
@@ -280,7 +423,7 @@ type GRPCEmitEventsHelperClient struct {
 	client proto.EmitEventsHelperClient
 }
 
-func (c *GRPCEmitEventsHelperClient) EmitEvents(events []*types.Event) error {
+func (c *GRPCEmitEventsHelperClient) EmitEvents(ctx context.Context, events []*types.Event) error {
 	emitEvents := make([]*proto.Event, len(events))
 	for i, event := range events {
 		emitEvents[i] = eventNative2Proto(event)
@@ -288,71 +431,107 @@ func (c *GRPCEmitEventsHelperClient) EmitEvents(events []*types.Event) error {
 	req := &proto.EmitEventsRequest{
 		Events: emitEvents,
 	}
-	_, err := c.client.EmitEvents(context.Background(), req)
+	_, err := c.client.EmitEvents(ctx, req)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *GRPCEmitEventsHelperClient) AuthenticateUser(idToken, provider string) (*types.User, error) {
+func (c *GRPCEmitEventsHelperClient) AuthenticateUser(ctx context.Context, idToken, provider string) (*types.User, error) {
 	req := &proto.AuthenticateUserRequest{
 		IdToken:  idToken,
 		Provider: provider,
 	}
-	resp, err := c.client.AuthenticateUser(context.Background(), req)
+	resp, err := c.client.AuthenticateUser(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	return userProto2Native(resp.User), nil
 }
 
-func (c *GRPCEmitEventsHelperClient) GetUser(userId string) (*types.User, error) {
+func (c *GRPCEmitEventsHelperClient) GetUser(ctx context.Context, userId string) (*types.User, error) {
 	req := &proto.GetUserRequest{
 		UserId: userId,
 	}
-	resp, err := c.client.GetUser(context.Background(), req)
+	resp, err := c.client.GetUser(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	return userProto2Native(resp.User), nil
 }
 
-func (c *GRPCEmitEventsHelperClient) ChangeUserTags(userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
+func (c *GRPCEmitEventsHelperClient) ChangeUserTags(ctx context.Context, actorId, userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
 	req := &proto.ChangeUserTagsRequest{
 		UserId:    userId,
 		TagUpdate: tagUpdatesNative2Proto(updates),
+		ActorId:   actorId,
 	}
-	resp, err := c.client.ChangeUserTags(context.Background(), req)
+	resp, err := c.client.ChangeUserTags(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
 	return userProto2Native(resp.User), resp.Ok, nil
 }
 
-func (c *GRPCEmitEventsHelperClient) GetRoom(roomId string) (*types.Room, error) {
+func (c *GRPCEmitEventsHelperClient) GetRoom(ctx context.Context, roomId string) (*types.Room, error) {
 	req := &proto.GetRoomRequest{
 		RoomId: roomId,
 	}
-	resp, err := c.client.GetRoom(context.Background(), req)
+	resp, err := c.client.GetRoom(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	return roomProto2Native(resp.Room), nil
 }
 
-func (c *GRPCEmitEventsHelperClient) ChangeRoomTags(roomId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
+func (c *GRPCEmitEventsHelperClient) ChangeRoomTags(ctx context.Context, roomId, actorId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
 	req := &proto.ChangeRoomTagsRequest{
 		RoomId:    roomId,
 		TagUpdate: tagUpdatesNative2Proto(updates),
+		ActorId:   actorId,
 	}
-	resp, err := c.client.ChangeRoomTags(context.Background(), req)
+	resp, err := c.client.ChangeRoomTags(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
 	return roomProto2Native(resp.Room), resp.Ok, nil
 }
 
+func (c *GRPCEmitEventsHelperClient) GetRoomLanguages(ctx context.Context, roomId string) ([]string, error) {
+	req := &proto.GetRoomLanguagesRequest{
+		RoomId: roomId,
+	}
+	resp, err := c.client.GetRoomLanguages(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Languages, nil
+}
+
+func (c *GRPCEmitEventsHelperClient) GetUserRole(ctx context.Context, roomId, userId string) (string, error) {
+	req := &proto.GetUserRoleRequest{
+		RoomId: roomId,
+		UserId: userId,
+	}
+	resp, err := c.client.GetUserRole(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Role, nil
+}
+
+func (c *GRPCEmitEventsHelperClient) SetUserRole(ctx context.Context, roomId, actorId, userId, role string) error {
+	req := &proto.SetUserRoleRequest{
+		RoomId:  roomId,
+		ActorId: actorId,
+		UserId:  userId,
+		Role:    role,
+	}
+	_, err := c.client.SetUserRole(ctx, req)
+	return err
+}
+
 type GRPCEmitEventsHelperServer struct {
 	proto.UnimplementedEmitEventsHelperServer
 
@@ -365,7 +544,7 @@ func (s *GRPCEmitEventsHelperServer) EmitEvents(ctx context.Context, req *proto.
 	for i, event := range req.Events {
 		events[i] = eventProto2Native(event)
 	}
-	err = s.Impl.EmitEvents(events)
+	err = s.Impl.EmitEvents(ctx, events)
 	if err != nil {
 		return nil, err
 	}
@@ -373,7 +552,7 @@ func (s *GRPCEmitEventsHelperServer) EmitEvents(ctx context.Context, req *proto.
 }
 
 func (s *GRPCEmitEventsHelperServer) AuthenticateUser(ctx context.Context, req *proto.AuthenticateUserRequest) (resp *proto.AuthenticateUserResponse, err error) {
-	user, err := s.Impl.AuthenticateUser(req.IdToken, req.Provider)
+	user, err := s.Impl.AuthenticateUser(ctx, req.IdToken, req.Provider)
 	if err != nil {
 		return nil, err
 	}
@@ -381,7 +560,7 @@ func (s *GRPCEmitEventsHelperServer) AuthenticateUser(ctx context.Context, req *
 }
 
 func (s *GRPCEmitEventsHelperServer) GetUser(ctx context.Context, req *proto.GetUserRequest) (resp *proto.GetUserResponse, err error) {
-	user, err := s.Impl.GetUser(req.UserId)
+	user, err := s.Impl.GetUser(ctx, req.UserId)
 	if err != nil {
 		return nil, err
 	}
@@ -389,7 +568,7 @@ func (s *GRPCEmitEventsHelperServer) GetUser(ctx context.Context, req *proto.Get
 }
 
 func (s *GRPCEmitEventsHelperServer) ChangeUserTags(ctx context.Context, req *proto.ChangeUserTagsRequest) (resp *proto.ChangeUserTagsResponse, err error) {
-	user, resOk, err := s.Impl.ChangeUserTags(req.UserId, tagUpdatesProto2Native(req.TagUpdate))
+	user, resOk, err := s.Impl.ChangeUserTags(ctx, req.ActorId, req.UserId, tagUpdatesProto2Native(req.TagUpdate))
 	if err != nil {
 		return nil, err
 	}
@@ -397,7 +576,7 @@ func (s *GRPCEmitEventsHelperServer) ChangeUserTags(ctx context.Context, req *pr
 }
 
 func (s *GRPCEmitEventsHelperServer) GetRoom(ctx context.Context, req *proto.GetRoomRequest) (resp *proto.GetRoomResponse, err error) {
-	room, err := s.Impl.GetRoom(req.RoomId)
+	room, err := s.Impl.GetRoom(ctx, req.RoomId)
 	if err != nil {
 		return nil, err
 	}
@@ -405,9 +584,33 @@ func (s *GRPCEmitEventsHelperServer) GetRoom(ctx context.Context, req *proto.Get
 }
 
 func (s *GRPCEmitEventsHelperServer) ChangeRoomTags(ctx context.Context, req *proto.ChangeRoomTagsRequest) (resp *proto.ChangeRoomTagsResponse, err error) {
-	room, resOk, err := s.Impl.ChangeRoomTags(req.RoomId, tagUpdatesProto2Native(req.TagUpdate))
+	room, resOk, err := s.Impl.ChangeRoomTags(ctx, req.RoomId, req.ActorId, tagUpdatesProto2Native(req.TagUpdate))
 	if err != nil {
 		return nil, err
 	}
 	return &proto.ChangeRoomTagsResponse{Room: roomNative2Proto(room), Ok: resOk}, nil
 }
+
+func (s *GRPCEmitEventsHelperServer) GetRoomLanguages(ctx context.Context, req *proto.GetRoomLanguagesRequest) (resp *proto.GetRoomLanguagesResponse, err error) {
+	languages, err := s.Impl.GetRoomLanguages(ctx, req.RoomId)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.GetRoomLanguagesResponse{Languages: languages}, nil
+}
+
+func (s *GRPCEmitEventsHelperServer) GetUserRole(ctx context.Context, req *proto.GetUserRoleRequest) (resp *proto.GetUserRoleResponse, err error) {
+	role, err := s.Impl.GetUserRole(ctx, req.RoomId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.GetUserRoleResponse{Role: role}, nil
+}
+
+func (s *GRPCEmitEventsHelperServer) SetUserRole(ctx context.Context, req *proto.SetUserRoleRequest) (resp *proto.SetUserRoleResponse, err error) {
+	err = s.Impl.SetUserRole(ctx, req.RoomId, req.ActorId, req.UserId, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.SetUserRoleResponse{}, nil
+}
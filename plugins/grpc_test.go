@@ -0,0 +1,28 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestEventConversion(t *testing.T) {
+	event := types.NewEvent(&types.Room{Id: "room"}, nil, "", "en", types.EventTypeChat, map[string]string{"message": "hi"})
+	event.Seq = 42
+	protoEvent := eventNative2Proto(event)
+	assert.Equal(t, event.Created.UnixNano(), protoEvent.CreatedNs)
+	assert.Equal(t, event.Created.Unix(), protoEvent.Created)
+	assert.Equal(t, int64(0), protoEvent.SentNs, "zero time")
+
+	native := eventProto2Native(protoEvent)
+	assert.True(t, event.Created.Equal(native.Created))
+	assert.True(t, native.Sent.IsZero())
+	assert.Equal(t, uint64(42), native.Seq)
+	assert.Equal(t, event.Id, native.Id)
+
+	// plugins built before the nanosecond fields were added only set the seconds
+	protoEvent.CreatedNs = 0
+	assert.Equal(t, time.Unix(event.Created.Unix(), 0).UTC(), eventProto2Native(protoEvent).Created)
+}
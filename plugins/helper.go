@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -8,12 +9,15 @@ import (
 )
 
 type HelperFunctionsType struct {
-	implEmitEvents       func([]*types.Event) error
-	implGetRoom          func(string) (*types.Room, error)
-	implGetUser          func(string) (*types.User, error)
-	implAuthenticateUser func(string, string) (*types.User, error)
-	implChangeRoomTags   func(string, []*types.TagUpdate) (*types.Room, []bool, error)
-	implChangeUserTags   func(string, []*types.TagUpdate) (*types.User, []bool, error)
+	implEmitEvents       func(context.Context, []*types.Event) error
+	implGetRoom          func(context.Context, string) (*types.Room, error)
+	implGetUser          func(context.Context, string) (*types.User, error)
+	implAuthenticateUser func(context.Context, string, string) (*types.User, error)
+	implChangeRoomTags   func(context.Context, string, string, []*types.TagUpdate) (*types.Room, []bool, error)
+	implChangeUserTags   func(context.Context, string, string, []*types.TagUpdate) (*types.User, []bool, error)
+	implGetRoomLanguages func(context.Context, string) ([]string, error)
+	implGetUserRole      func(context.Context, string, string) (string, error)
+	implSetUserRole      func(context.Context, string, string, string, string) error
 	sync.RWMutex
 }
 
@@ -26,6 +30,9 @@ func (h *HelperFunctionsType) Clear() {
 	h.implAuthenticateUser = nil
 	h.implChangeRoomTags = nil
 	h.implChangeUserTags = nil
+	h.implGetRoomLanguages = nil
+	h.implGetUserRole = nil
+	h.implSetUserRole = nil
 }
 
 func (h *HelperFunctionsType) Set(eh EmitEventsHelper) {
@@ -37,64 +44,97 @@ func (h *HelperFunctionsType) Set(eh EmitEventsHelper) {
 	h.implAuthenticateUser = eh.AuthenticateUser
 	h.implChangeRoomTags = eh.ChangeRoomTags
 	h.implChangeUserTags = eh.ChangeUserTags
+	h.implGetRoomLanguages = eh.GetRoomLanguages
+	h.implGetUserRole = eh.GetUserRole
+	h.implSetUserRole = eh.SetUserRole
 }
 
-func (h *HelperFunctionsType) EmitEvents(events []*types.Event) error {
+func (h *HelperFunctionsType) EmitEvents(ctx context.Context, events []*types.Event) error {
 	h.RLock()
 	if e := h.implEmitEvents; e != nil {
 		h.RUnlock()
-		return e(events)
+		return e(ctx, events)
 	}
 	h.RUnlock()
 	return fmt.Errorf("lost connection")
 }
 
-func (h *HelperFunctionsType) GetRoom(roomId string) (*types.Room, error) {
+func (h *HelperFunctionsType) GetRoom(ctx context.Context, roomId string) (*types.Room, error) {
 	h.RLock()
 	if r := h.implGetRoom; r != nil {
 		h.RUnlock()
-		return r(roomId)
+		return r(ctx, roomId)
 	}
 	h.RUnlock()
 	return nil, fmt.Errorf("lost connection")
 }
 
-func (h *HelperFunctionsType) GetUser(userId string) (*types.User, error) {
+func (h *HelperFunctionsType) GetUser(ctx context.Context, userId string) (*types.User, error) {
 	h.RLock()
 	if u := h.implGetUser; u != nil {
 		h.RUnlock()
-		return u(userId)
+		return u(ctx, userId)
 	}
 	h.RUnlock()
 	return nil, fmt.Errorf("lost connection")
 }
 
-func (h *HelperFunctionsType) AuthenticateUser(token string, provider string) (*types.User, error) {
+func (h *HelperFunctionsType) AuthenticateUser(ctx context.Context, token string, provider string) (*types.User, error) {
 	h.RLock()
 	if u := h.implAuthenticateUser; u != nil {
 		h.RUnlock()
-		return u(token, provider)
+		return u(ctx, token, provider)
 	}
 	h.RUnlock()
 	return nil, fmt.Errorf("lost connection")
 }
 
-func (h *HelperFunctionsType) ChangeRoomTags(roomId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
+func (h *HelperFunctionsType) ChangeRoomTags(ctx context.Context, roomId, actorId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
 	h.RLock()
 	if r := h.implChangeRoomTags; r != nil {
 		h.RUnlock()
-		return r(roomId, updates)
+		return r(ctx, roomId, actorId, updates)
 	}
 	h.RUnlock()
 	return nil, nil, fmt.Errorf("lost connection")
 }
 
-func (h *HelperFunctionsType) ChangeUserTags(userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
+func (h *HelperFunctionsType) ChangeUserTags(ctx context.Context, actorId, userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
 	h.RLock()
 	if u := h.implChangeUserTags; u != nil {
 		h.RUnlock()
-		return u(userId, updates)
+		return u(ctx, actorId, userId, updates)
 	}
 	h.RUnlock()
 	return nil, nil, fmt.Errorf("lost connection")
 }
+
+func (h *HelperFunctionsType) GetRoomLanguages(ctx context.Context, roomId string) ([]string, error) {
+	h.RLock()
+	if l := h.implGetRoomLanguages; l != nil {
+		h.RUnlock()
+		return l(ctx, roomId)
+	}
+	h.RUnlock()
+	return nil, fmt.Errorf("lost connection")
+}
+
+func (h *HelperFunctionsType) GetUserRole(ctx context.Context, roomId, userId string) (string, error) {
+	h.RLock()
+	if r := h.implGetUserRole; r != nil {
+		h.RUnlock()
+		return r(ctx, roomId, userId)
+	}
+	h.RUnlock()
+	return "", fmt.Errorf("lost connection")
+}
+
+func (h *HelperFunctionsType) SetUserRole(ctx context.Context, roomId, actorId, userId, role string) error {
+	h.RLock()
+	if r := h.implSetUserRole; r != nil {
+		h.RUnlock()
+		return r(ctx, roomId, actorId, userId, role)
+	}
+	h.RUnlock()
+	return fmt.Errorf("lost connection")
+}
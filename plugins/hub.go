@@ -25,32 +25,72 @@ var PluginMap = map[string]plugin.Plugin{
 	"eventhandler": &EventHandlerPlugin{},
 }
 
+// Make sure those constants are the same as defined in the proto enum ConfigureResponse.Kind!
+const (
+	// KindObserver plugins only react on events after they have been broadcast (via HandleEvents).
+	KindObserver = 0
+	// KindInterceptor plugins may modify or reject events before they are broadcast (via FilterEvents).
+	KindInterceptor = 1
+)
+
+// Configuration is returned by EventHandler.Configure and defines how the main process interacts with the plugin.
+type Configuration struct {
+	CronSpec     string        // Cron is called according to this cron spec (never if empty)
+	EventsFilter string        // only events passing this filter are passed to the plugin
+	Priority     int           // plugins with higher priority are called first
+	Kind         int           // KindObserver or KindInterceptor
+	Commands     []CommandSpec // the commands handled by the plugin
+}
+
+// EmitEventsHelper is the interface the main process provides to the plugins. All calls take a context.Context,
+// which is cancelled once the caller is no longer interested in the result.
 type EmitEventsHelper interface {
-	EmitEvents([]*types.Event) error
-	AuthenticateUser(string, string) (*types.User, error)
-	GetUser(string) (*types.User, error)
-	GetRoom(string) (*types.Room, error)
-	ChangeUserTags(string, []*types.TagUpdate) (*types.User, []bool, error)
-	ChangeRoomTags(string, []*types.TagUpdate) (*types.Room, []bool, error)
+	EmitEvents(context.Context, []*types.Event) error
+	AuthenticateUser(context.Context, string, string) (*types.User, error)
+	GetUser(context.Context, string) (*types.User, error)
+	GetRoom(context.Context, string) (*types.Room, error)
+	// ChangeUserTags changes the tags of the user on behalf of the acting user, who needs the permission to moderate.
+	// An empty actorId changes the tags on behalf of the plugin.
+	ChangeUserTags(ctx context.Context, actorId, userId string, updates []*types.TagUpdate) (*types.User, []bool, error)
+	// ChangeRoomTags changes the tags of the room on behalf of the acting user, who needs the permission to manage
+	// plugins. An empty actorId changes the tags on behalf of the plugin.
+	ChangeRoomTags(ctx context.Context, roomId, actorId string, updates []*types.TagUpdate) (*types.Room, []bool, error)
+	// GetRoomLanguages returns the languages (2 letters, lower case) of the clients currently connected to the room.
+	GetRoomLanguages(context.Context, string) ([]string, error)
+	// GetUserRole returns the role (see types.Role*) of the user in the room.
+	GetUserRole(ctx context.Context, roomId, userId string) (string, error)
+	// SetUserRole changes the role of the user in the room on behalf of the acting user, who needs the permission to
+	// moderate (only the owner may change moderators). An empty actorId changes the role on behalf of the plugin.
+	SetUserRole(ctx context.Context, roomId, actorId, userId, role string) error
 }
 
-// KV is the interface that we're exposing as a plugin.
+// EventHandler is the interface that we're exposing as a plugin.
+//
+// Every call carries a context.Context. The main process sets a deadline (see PluginSpec.Timeout) on all calls
+// except InitEmitEvents, plugins should stop working on a request once the context is done, the result is discarded
+// anyway.
 type EventHandler interface {
-	// Configure returns the cron spec and the events filter
-	Configure(map[string]interface{}) (cronSpec string, eventsFilter string, err error)
+	// Configure returns the cron spec, the events filter, the priority and the kind of the plugin
+	Configure(ctx context.Context, config map[string]interface{}) (Configuration, error)
 
 	// Cron is invoked from the main process according to the cronSpec as returned by Configure.
 	// Cron returns []types.Event to be emitted
-	Cron(*types.Room) ([]*types.Event, error)
+	Cron(ctx context.Context, room *types.Room) ([]*types.Event, error)
 
 	// HandleEvents is invoked every time a new event occurs, currently defined events are
 	// new chat message, new translation, new command, new user login
 	// the plugin only receives events that pass the eventsFilter returned by Configure
-	HandleEvents([]*types.Event) ([]*types.Event, error)
+	HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error)
+
+	// FilterEvents is only invoked for plugins of kind KindInterceptor. It receives new events before they are
+	// broadcast or stored (again, only those passing the eventsFilter) and returns exactly one FilterResult per event
+	// (in the same order): the event is either passed on unchanged, modified (tags, target filter and language) or
+	// rejected. A rejected event is neither broadcast nor stored, its sender is notified with the reason instead.
+	FilterEvents(ctx context.Context, events []*types.Event) ([]*FilterResult, error)
 
-	// InitEmitEvents never exits, it creates a permanent connection between the main program an the plugin
-	// allowing the plugin to emit events at will
-	InitEmitEvents(room *types.Room, eh EmitEventsHelper) error
+	// InitEmitEvents only exits when ctx is done, it creates a permanent connection between the main program and the
+	// plugin allowing the plugin to emit events at will
+	InitEmitEvents(ctx context.Context, room *types.Room, eh EmitEventsHelper) error
 }
 
 // This is the implementation of plugin.Plugin so we can serve/consume this.
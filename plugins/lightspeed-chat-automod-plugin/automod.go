@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	actionDelete  = "delete"  // reject the event
+	actionMask    = "mask"    // replace the offending text, the event is passed on
+	actionTimeout = "timeout" // reject the event and mute the sender for mute_duration
+)
+
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// rule is a compiled regex rule.
+type rule struct {
+	re     *regexp.Regexp
+	action string
+	reason string
+}
+
+// postedMessage is a message remembered for the repeated-message detection.
+type postedMessage struct {
+	text    string
+	created time.Time
+}
+
+// automod checks events against the configured rules. It is safe for concurrent use.
+type automod struct {
+	cfg       config
+	blocklist *regexp.Regexp // nil if there is no blocklist
+	rules     []rule
+
+	// lookupMute is called once per user to find out if the user is muted already (f.e. after a restart of the
+	// plugin), it may be nil.
+	lookupMute func(userId string) (time.Time, error)
+
+	sync.Mutex
+	mutes      map[string]time.Time // user id -> muted until
+	knownUsers map[string]struct{}  // users for which lookupMute has been called successfully
+	history    map[string][]postedMessage
+}
+
+func validAction(action string) bool {
+	return action == actionDelete || action == actionMask || action == actionTimeout
+}
+
+// newAutomod compiles the configuration, missing values are replaced by their defaults.
+func newAutomod(cfg config) (*automod, error) {
+	if cfg.BlocklistAction == "" {
+		cfg.BlocklistAction = actionMask
+	}
+	if cfg.LinksAction == "" {
+		cfg.LinksAction = actionDelete
+	}
+	if cfg.CapsAction == "" {
+		cfg.CapsAction = actionMask
+	}
+	if cfg.RepeatAction == "" {
+		cfg.RepeatAction = actionTimeout
+	}
+	if cfg.MinCapsLength <= 0 {
+		cfg.MinCapsLength = defaultMinCapsLength
+	}
+	if cfg.RepeatWindow <= 0 {
+		cfg.RepeatWindow = defaultRepeatWindow
+	}
+	if cfg.MuteDuration <= 0 {
+		cfg.MuteDuration = defaultMuteDuration
+	}
+	for _, action := range []string{cfg.BlocklistAction, cfg.LinksAction, cfg.CapsAction, cfg.RepeatAction} {
+		if !validAction(action) {
+			return nil, fmt.Errorf("invalid action %q", action)
+		}
+	}
+	a := &automod{
+		cfg:        cfg,
+		mutes:      make(map[string]time.Time),
+		knownUsers: make(map[string]struct{}),
+		history:    make(map[string][]postedMessage),
+	}
+	words := make([]string, 0, len(cfg.Blocklist))
+	for _, word := range cfg.Blocklist {
+		word = strings.TrimSpace(word)
+		if word != "" {
+			words = append(words, regexp.QuoteMeta(word))
+		}
+	}
+	if len(words) > 0 {
+		a.blocklist = regexp.MustCompile(`(?i)\b(?:` + strings.Join(words, "|") + `)\b`)
+	}
+	for _, r := range cfg.Rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule pattern %q: %w", r.Pattern, err)
+		}
+		action := r.Action
+		if action == "" {
+			action = actionDelete
+		}
+		if !validAction(action) {
+			return nil, fmt.Errorf("invalid action %q for rule %q", r.Action, r.Pattern)
+		}
+		a.rules = append(a.rules, rule{re: re, action: action, reason: r.Reason})
+	}
+	return a, nil
+}
+
+// check returns the verdict on the event at the given time. If the sender is to be muted, the returned time is the
+// end of the mute.
+func (a *automod) check(event *types.Event, now time.Time) (*plugins.FilterResult, time.Time) {
+	if event.Source == nil || event.Source.User == nil || event.Source.PluginName != "" {
+		return plugins.Unchanged(), time.Time{}
+	}
+	userId := event.Source.User.Id
+	if userId == "" {
+		userId = event.Source.User.Nick
+	}
+	if until := a.mutedUntil(userId, now); !until.IsZero() {
+		return plugins.Rejected(fmt.Sprintf("you are muted until %s", until.UTC().Format(time.RFC3339))), time.Time{}
+	}
+	message, ok := event.Tags["message"]
+	if !ok || message == "" {
+		return plugins.Unchanged(), time.Time{}
+	}
+
+	masked := false
+	maskFunc := func(re *regexp.Regexp) {
+		message = maskMatches(re, message)
+		masked = true
+	}
+	if a.blocklist != nil && a.blocklist.MatchString(message) {
+		if a.cfg.BlocklistAction == actionMask {
+			maskFunc(a.blocklist)
+		} else {
+			return a.reject(a.cfg.BlocklistAction, "your message contains a blocked word", now)
+		}
+	}
+	for _, r := range a.rules {
+		if !r.re.MatchString(message) {
+			continue
+		}
+		if r.action == actionMask {
+			maskFunc(r.re)
+			continue
+		}
+		reason := r.reason
+		if reason == "" {
+			reason = "your message is not allowed"
+		}
+		return a.reject(r.action, reason, now)
+	}
+	if a.cfg.MaxLinks > 0 && len(linkRegexp.FindAllStringIndex(message, -1)) > a.cfg.MaxLinks {
+		if a.cfg.LinksAction == actionMask {
+			maskFunc(linkRegexp)
+		} else {
+			return a.reject(a.cfg.LinksAction, fmt.Sprintf("your message contains more than %d link(s)", a.cfg.MaxLinks), now)
+		}
+	}
+	if a.cfg.MaxCapsRatio > 0 && capsRatio(message, a.cfg.MinCapsLength) > a.cfg.MaxCapsRatio {
+		if a.cfg.CapsAction == actionMask {
+			message = strings.ToLower(message)
+			masked = true
+		} else {
+			return a.reject(a.cfg.CapsAction, "your message contains too many capital letters", now)
+		}
+	}
+	if a.cfg.MaxRepeats > 0 && a.isRepeated(event.RoomId+"\x00"+userId, message, now) {
+		action := a.cfg.RepeatAction
+		if action == actionMask { // a repeated message cannot be masked
+			action = actionDelete
+		}
+		return a.reject(action, "please do not repeat yourself", now)
+	}
+
+	if !masked {
+		return plugins.Unchanged(), time.Time{}
+	}
+	tags := make(map[string]string, len(event.Tags))
+	for k, v := range event.Tags {
+		tags[k] = v
+	}
+	tags["message"] = message
+	if _, ok := tags["args"]; ok && event.Name == types.EventTypeCommand {
+		fields := strings.Fields(message)
+		if len(fields) > 0 {
+			tags["args"] = strings.Join(fields[1:], " ")
+		}
+	}
+	modEvent := *event
+	modEvent.Tags = tags
+	return plugins.Modified(&modEvent), time.Time{}
+}
+
+// reject returns the result for the actions delete and timeout.
+func (a *automod) reject(action, reason string, now time.Time) (*plugins.FilterResult, time.Time) {
+	if action == actionTimeout {
+		until := now.Add(a.cfg.MuteDuration)
+		return plugins.Rejected(fmt.Sprintf("%s, you are muted until %s", reason, until.UTC().Format(time.RFC3339))), until
+	}
+	return plugins.Rejected(reason), time.Time{}
+}
+
+// mute mutes the user until the given time.
+func (a *automod) mute(userId string, until time.Time) {
+	a.Lock()
+	defer a.Unlock()
+	a.mutes[userId] = until
+	a.knownUsers[userId] = struct{}{}
+}
+
+// mutedUntil returns the end of the user's mute, or the zero time if the user is not muted.
+func (a *automod) mutedUntil(userId string, now time.Time) time.Time {
+	a.Lock()
+	_, known := a.knownUsers[userId]
+	a.Unlock()
+	if !known && a.lookupMute != nil {
+		until, err := a.lookupMute(userId)
+		if err == nil {
+			a.Lock()
+			if _, ok := a.mutes[userId]; !ok && !until.IsZero() {
+				a.mutes[userId] = until
+			}
+			a.knownUsers[userId] = struct{}{}
+			a.Unlock()
+		}
+	}
+	a.Lock()
+	defer a.Unlock()
+	until, ok := a.mutes[userId]
+	if !ok {
+		return time.Time{}
+	}
+	if !now.Before(until) {
+		delete(a.mutes, userId)
+		return time.Time{}
+	}
+	return until
+}
+
+// isRepeated records the message and reports whether the same message was posted more than max_repeats times
+// within repeat_window.
+func (a *automod) isRepeated(key, message string, now time.Time) bool {
+	text := strings.ToLower(strings.Join(strings.Fields(message), " "))
+	a.Lock()
+	defer a.Unlock()
+	recent := make([]postedMessage, 0, len(a.history[key])+1)
+	count := 0
+	for _, m := range a.history[key] {
+		if now.Sub(m.created) > a.cfg.RepeatWindow {
+			continue
+		}
+		recent = append(recent, m)
+		if m.text == text {
+			count++
+		}
+	}
+	recent = append(recent, postedMessage{text: text, created: now})
+	a.history[key] = recent
+	return count >= a.cfg.MaxRepeats
+}
+
+// cleanup removes expired mutes and outdated messages.
+func (a *automod) cleanup(now time.Time) {
+	a.Lock()
+	defer a.Unlock()
+	for userId, until := range a.mutes {
+		if !now.Before(until) {
+			delete(a.mutes, userId)
+		}
+	}
+	for key, messages := range a.history {
+		if len(messages) == 0 || now.Sub(messages[len(messages)-1].created) > a.cfg.RepeatWindow {
+			delete(a.history, key)
+		}
+	}
+}
+
+// maskMatches replaces every match of re in s by asterisks.
+func maskMatches(re *regexp.Regexp, s string) string {
+	return re.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(m))
+	})
+}
+
+// capsRatio returns the ratio of upper case letters to all letters in s, or 0 if s contains less than minLetters
+// letters.
+func capsRatio(s string, minLetters int) float64 {
+	letters, upper := 0, 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	if letters == 0 || letters < minLetters {
+		return 0
+	}
+	return float64(upper) / float64(letters)
+}
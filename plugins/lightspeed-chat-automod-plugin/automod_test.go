@@ -0,0 +1,184 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func chatEvent(userId, message string) *types.Event {
+	source := &types.Source{User: &types.User{Id: userId, Nick: userId}}
+	room := &types.Room{Id: "room"}
+	event := types.NewEvent(room, source, "", "en", types.EventTypeChat, map[string]string{"message": message})
+	event.RoomId = room.Id
+	return event
+}
+
+func TestBlocklist(t *testing.T) {
+	a, err := newAutomod(config{Blocklist: []string{"darn", "heck"}})
+	assert.NoError(t, err)
+	now := time.Now()
+
+	res, _ := a.check(chatEvent("u1", "hello world"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+
+	event := chatEvent("u1", "Darn, what the HECK! darnit")
+	res, _ = a.check(event, now)
+	assert.Equal(t, plugins.FilterActionModified, res.Action)
+	assert.Equal(t, "****, what the ****! darnit", res.Event.Tags["message"])
+	assert.Equal(t, "Darn, what the HECK! darnit", event.Tags["message"], "original event must not be changed")
+
+	a, err = newAutomod(config{Blocklist: []string{"darn"}, BlocklistAction: actionDelete})
+	assert.NoError(t, err)
+	res, until := a.check(chatEvent("u1", "darn"), now)
+	assert.Equal(t, plugins.FilterActionRejected, res.Action)
+	assert.NotEmpty(t, res.Reason)
+	assert.True(t, until.IsZero())
+}
+
+func TestRules(t *testing.T) {
+	a, err := newAutomod(config{Rules: []ruleConfig{
+		{Pattern: `\d{4}-\d{4}-\d{4}-\d{4}`, Action: actionMask},
+		{Pattern: `(?i)buy now`, Reason: "no advertising"},
+	}})
+	assert.NoError(t, err)
+	now := time.Now()
+
+	res, _ := a.check(chatEvent("u1", "my card is 1234-5678-9012-3456"), now)
+	assert.Equal(t, plugins.FilterActionModified, res.Action)
+	assert.Equal(t, "my card is *******************", res.Event.Tags["message"])
+
+	res, _ = a.check(chatEvent("u1", "BUY NOW!"), now)
+	assert.Equal(t, plugins.FilterActionRejected, res.Action)
+	assert.Equal(t, "no advertising", res.Reason)
+
+	_, err = newAutomod(config{Rules: []ruleConfig{{Pattern: `(`}}})
+	assert.Error(t, err)
+	_, err = newAutomod(config{Rules: []ruleConfig{{Pattern: `a`, Action: "ban"}}})
+	assert.Error(t, err)
+	_, err = newAutomod(config{CapsAction: "ban"})
+	assert.Error(t, err)
+}
+
+func TestLinks(t *testing.T) {
+	a, err := newAutomod(config{MaxLinks: 1})
+	assert.NoError(t, err)
+	now := time.Now()
+
+	res, _ := a.check(chatEvent("u1", "see https://example.com"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+
+	res, _ = a.check(chatEvent("u1", "see https://example.com and www.example.org"), now)
+	assert.Equal(t, plugins.FilterActionRejected, res.Action)
+
+	a, err = newAutomod(config{MaxLinks: 1, LinksAction: actionMask})
+	assert.NoError(t, err)
+	res, _ = a.check(chatEvent("u1", "see http://a.b and http://c.d"), now)
+	assert.Equal(t, plugins.FilterActionModified, res.Action)
+	assert.Equal(t, "see ********** and **********", res.Event.Tags["message"])
+}
+
+func TestCaps(t *testing.T) {
+	a, err := newAutomod(config{MaxCapsRatio: 0.7})
+	assert.NoError(t, err)
+	now := time.Now()
+
+	res, _ := a.check(chatEvent("u1", "OK!"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action, "short messages are not checked")
+
+	res, _ = a.check(chatEvent("u1", "THIS IS REALLY LOUD"), now)
+	assert.Equal(t, plugins.FilterActionModified, res.Action)
+	assert.Equal(t, "this is really loud", res.Event.Tags["message"])
+
+	res, _ = a.check(chatEvent("u1", "This is Really Not That Loud"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+}
+
+func TestRepeatAndTimeout(t *testing.T) {
+	a, err := newAutomod(config{MaxRepeats: 2, RepeatWindow: 10 * time.Second, MuteDuration: time.Minute})
+	assert.NoError(t, err)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		res, _ := a.check(chatEvent("u1", "spam"), now)
+		assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+	}
+	res, _ := a.check(chatEvent("u2", "Spam"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action, "other users are counted separately")
+
+	res, until := a.check(chatEvent("u1", " SPAM "), now)
+	assert.Equal(t, plugins.FilterActionRejected, res.Action)
+	assert.Equal(t, now.Add(time.Minute), until)
+	a.mute("u1", until)
+
+	res, _ = a.check(chatEvent("u1", "something else"), now.Add(30*time.Second))
+	assert.Equal(t, plugins.FilterActionRejected, res.Action, "muted users cannot post")
+	res, _ = a.check(chatEvent("u2", "something else"), now.Add(30*time.Second))
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+
+	res, _ = a.check(chatEvent("u1", "spam"), now.Add(2*time.Minute))
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action, "the mute has expired and the window has passed")
+}
+
+func TestLookupMute(t *testing.T) {
+	a, err := newAutomod(config{})
+	assert.NoError(t, err)
+	now := time.Now()
+	calls := 0
+	a.lookupMute = func(userId string) (time.Time, error) {
+		calls++
+		if userId == "muted" {
+			return now.Add(time.Hour), nil
+		}
+		return time.Time{}, nil
+	}
+
+	res, _ := a.check(chatEvent("muted", "hello"), now)
+	assert.Equal(t, plugins.FilterActionRejected, res.Action)
+	res, _ = a.check(chatEvent("other", "hello"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+	res, _ = a.check(chatEvent("other", "hello again"), now)
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+	assert.Equal(t, 2, calls, "the tag is only looked up once per user")
+}
+
+func TestPluginEvents(t *testing.T) {
+	a, err := newAutomod(config{Blocklist: []string{"darn"}, BlocklistAction: actionDelete})
+	assert.NoError(t, err)
+	event := chatEvent("u1", "darn")
+	event.Source.PluginName = "base-commands"
+	res, _ := a.check(event, time.Now())
+	assert.Equal(t, plugins.FilterActionUnchanged, res.Action)
+}
+
+func TestCommandArgs(t *testing.T) {
+	a, err := newAutomod(config{Blocklist: []string{"darn"}})
+	assert.NoError(t, err)
+	event := chatEvent("u1", "/to bob darn it")
+	event.Name = types.EventTypeCommand
+	event.Tags["command"] = "/to"
+	event.Tags["args"] = "bob darn it"
+	res, _ := a.check(event, time.Now())
+	assert.Equal(t, plugins.FilterActionModified, res.Action)
+	assert.Equal(t, "/to bob **** it", res.Event.Tags["message"])
+	assert.Equal(t, "bob **** it", res.Event.Tags["args"])
+	assert.Equal(t, "/to", res.Event.Tags["command"])
+}
+
+func TestDecodeConfig(t *testing.T) {
+	cfg := config{}
+	err := decodeConfig(map[string]interface{}{
+		"blocklist":     []interface{}{"darn"},
+		"max_links":     "2",
+		"repeat_window": "1m",
+		"rules":         []map[string]interface{}{{"pattern": "x", "action": "mask"}},
+	}, &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"darn"}, cfg.Blocklist)
+	assert.Equal(t, 2, cfg.MaxLinks)
+	assert.Equal(t, time.Minute, cfg.RepeatWindow)
+	assert.Equal(t, "mask", cfg.Rules[0].Action)
+}
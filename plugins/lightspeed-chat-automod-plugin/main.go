@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	pluginName           = "automod"
+	mutedUntilTag        = "_muted_until"
+	defaultMinCapsLength = 10
+	defaultRepeatWindow  = 30 * time.Second
+	defaultMuteDuration  = 5 * time.Minute
+	cleanupInterval      = time.Minute
+	helperTimeout        = 2 * time.Second
+)
+
+type ruleConfig struct {
+	Pattern string `mapstructure:"pattern"` // regular expression (Go syntax), use (?i) for case-insensitive matching
+	Action  string `mapstructure:"action"`  // delete (default), mask or timeout
+	Reason  string `mapstructure:"reason"`  // sent to the sender if the message is rejected
+}
+
+type config struct {
+	LogLevel        string        `mapstructure:"log_level"`
+	Priority        int           `mapstructure:"priority"`
+	Blocklist       []string      `mapstructure:"blocklist"`
+	BlocklistAction string        `mapstructure:"blocklist_action"`
+	Rules           []ruleConfig  `mapstructure:"rules"`
+	MaxLinks        int           `mapstructure:"max_links"`
+	LinksAction     string        `mapstructure:"links_action"`
+	MaxCapsRatio    float64       `mapstructure:"max_caps_ratio"`
+	MinCapsLength   int           `mapstructure:"min_caps_length"`
+	CapsAction      string        `mapstructure:"caps_action"`
+	MaxRepeats      int           `mapstructure:"max_repeats"`
+	RepeatWindow    time.Duration `mapstructure:"repeat_window"`
+	RepeatAction    string        `mapstructure:"repeat_action"`
+	MuteDuration    time.Duration `mapstructure:"mute_duration"`
+}
+
+var (
+	pluginConfig config
+	mod          *automod
+	helper       plugins.HelperFunctionsType
+)
+
+var appLogger = hclog.New(&hclog.LoggerOptions{
+	Name:  pluginName,
+	Level: hclog.LevelFromString("DEBUG"),
+})
+
+func decodeConfig(val map[string]interface{}, cfg *config) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(val)
+}
+
+// lookupMute reads the _muted_until tag of the user.
+func lookupMute(userId string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+	user, err := helper.GetUser(ctx, userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user == nil || user.Tags == nil {
+		return time.Time{}, nil
+	}
+	if v, ok := user.Tags[mutedUntilTag]; ok {
+		if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(ts, 0), nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// storeMute sets the _muted_until tag of the user.
+func storeMute(userId string, until time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+	updates := []*types.TagUpdate{
+		{
+			Name:       mutedUntilTag,
+			Type:       types.TagValueTypeInt,
+			Expression: strconv.FormatInt(until.Unix(), 10),
+		},
+	}
+	_, _, err := helper.ChangeUserTags(ctx, "", userId, updates)
+	if err != nil {
+		appLogger.Error("could not store mute", "user", userId, "error", err)
+	}
+}
+
+// Here is a real implementation of the plugin interface
+type EventHandler struct{}
+
+// HandleEvents is never called, the plugin is an interceptor.
+func (m *EventHandler) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	return nil, nil
+}
+
+func (m *EventHandler) Configure(ctx context.Context, val map[string]interface{}) (plugins.Configuration, error) {
+	err := decodeConfig(val, &pluginConfig)
+	if err != nil {
+		return plugins.Configuration{}, err
+	}
+	if pluginConfig.LogLevel != "" {
+		appLogger.SetLevel(hclog.LevelFromString(pluginConfig.LogLevel))
+	}
+	appLogger.Info("in plugin configure", "val", val)
+	mod, err = newAutomod(pluginConfig)
+	if err != nil {
+		return plugins.Configuration{}, err
+	}
+	mod.lookupMute = lookupMute
+	return plugins.Configuration{
+		EventsFilter: `(Name == "chat" || Name == "command") && Source.PluginName == ""`,
+		Priority:     pluginConfig.Priority,
+		Kind:         plugins.KindInterceptor,
+	}, nil
+}
+
+func (m *EventHandler) FilterEvents(ctx context.Context, events []*types.Event) ([]*plugins.FilterResult, error) {
+	results := make([]*plugins.FilterResult, len(events))
+	now := time.Now()
+	for i, event := range events {
+		result, muteUntil := mod.check(event, now)
+		if !muteUntil.IsZero() {
+			userId := event.Source.User.Id
+			if userId == "" {
+				userId = event.Source.User.Nick
+			}
+			mod.mute(userId, muteUntil)
+			if event.Source.User.Id != "" {
+				go storeMute(event.Source.User.Id, muteUntil)
+			}
+		}
+		if result.Action != plugins.FilterActionUnchanged {
+			appLogger.Info("automod action", "id", event.Id, "action", result.Action, "reason", result.Reason)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (m *EventHandler) Cron(ctx context.Context, room *types.Room) ([]*types.Event, error) {
+	return nil, nil
+}
+
+// make this run until the main process cancels the context!
+func (m *EventHandler) InitEmitEvents(ctx context.Context, room *types.Room, eh plugins.EmitEventsHelper) error {
+	appLogger.Info("in plugin initEmitEvents")
+	helper.Set(eh)
+	defer helper.Clear()
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			mod.cleanup(now)
+		}
+	}
+}
+
+func main() {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugins.Handshake,
+		Plugins: map[string]plugin.Plugin{
+			"eventhandler": &plugins.EventHandlerPlugin{Impl: &EventHandler{}},
+		},
+
+		// A non-nil value here enables gRPC serving for this plugin...
+		GRPCServer: plugin.DefaultGRPCServer,
+	})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/plugins/sdk/sdktest"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestCommands(t *testing.T) {
+	hub, err := sdktest.NewHub(newEventHandler(), map[string]interface{}{"priority": "5"})
+	assert.NoError(t, err)
+	assert.NoError(t, hub.Connect())
+	defer hub.Close()
+	assert.Equal(t, plugins.KindObserver, hub.Configuration.Kind)
+	assert.Equal(t, 5, hub.Configuration.Priority)
+
+	alice := &types.User{Id: "alice", Nick: "alice", Language: "en"}
+
+	res, err := hub.Send(hub.CommandEvent(alice, "/to bob  hello   world"))
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "hello   world", res[0].Tags["message"])
+		assert.Equal(t, `(Target.User.Nick == "bob" || Target.User.Id == "alice")`, res[0].TargetFilter)
+		assert.Equal(t, pluginName, res[0].Source.PluginName)
+	}
+
+	res, err = hub.Send(hub.CommandEvent(alice, "/fg red colorful"))
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "colorful", res[0].Tags["message"])
+		assert.Equal(t, "red", res[0].Tags["fg_color"])
+	}
+
+	assert.Equal(t, []plugins.CommandSpec{fgCommandSpec, toCommandSpec}, hub.Configuration.Commands)
+
+	res, err = hub.Send(hub.CommandEvent(alice, "/unknown"), hub.ChatEvent(alice, "/to bob hi"))
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func TestCron(t *testing.T) {
+	hub, err := sdktest.NewHub(newEventHandler(), nil)
+	assert.NoError(t, err)
+	res, err := hub.Cron()
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, baseCommandsText, res[0].Tags["message"])
+	}
+}
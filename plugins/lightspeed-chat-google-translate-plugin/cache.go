@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/golang-lru"
+)
+
+const defaultCacheSize = 10000
+
+// cacheKey identifies a translation. SourceLanguage is the language given to the provider, empty if the provider
+// detects it.
+type cacheKey struct {
+	SourceLanguage string
+	TargetLanguage string
+	Text           string
+}
+
+// cacheEntry is the on-disk representation of a cached translation. GivenSourceLanguage is the source language of
+// the key, SourceLanguage the (detected) source language of the translation.
+type cacheEntry struct {
+	GivenSourceLanguage string `json:"given_source_language,omitempty"`
+	TargetLanguage      string `json:"target_language"`
+	Text                string `json:"text"`
+	Translation         string `json:"translation"`
+	SourceLanguage      string `json:"source_language,omitempty"`
+}
+
+// translationCache is an ARC cache of translations, independent of the provider. If a file name is given, the cache
+// can be loaded from and saved to this file.
+type translationCache struct {
+	arc      *lru.ARCCache
+	fileName string
+
+	sync.Mutex // protects dirty and serializes saving
+	dirty      bool
+}
+
+func newTranslationCache(size int, fileName string) (*translationCache, error) {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	arc, err := lru.NewARC(size)
+	if err != nil {
+		return nil, err
+	}
+	return &translationCache{arc: arc, fileName: fileName}, nil
+}
+
+func (c *translationCache) Get(sourceLanguage, language, text string) (translationResult, bool) {
+	if v, ok := c.arc.Get(cacheKey{SourceLanguage: sourceLanguage, TargetLanguage: language, Text: text}); ok {
+		return v.(translationResult), true
+	}
+	return translationResult{}, false
+}
+
+func (c *translationCache) Add(sourceLanguage, language, text string, translation translationResult) {
+	c.arc.Add(cacheKey{SourceLanguage: sourceLanguage, TargetLanguage: language, Text: text}, translation)
+	c.Lock()
+	c.dirty = true
+	c.Unlock()
+}
+
+// Load reads the cache file, a missing file is not an error.
+func (c *translationCache) Load() error {
+	if c.fileName == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entries := make([]cacheEntry, 0)
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		key := cacheKey{SourceLanguage: entry.GivenSourceLanguage, TargetLanguage: entry.TargetLanguage, Text: entry.Text}
+		c.arc.Add(key, translationResult{Text: entry.Translation, SourceLanguage: entry.SourceLanguage})
+	}
+	return nil
+}
+
+// Save writes the cache file if the cache has been changed since the last save. The file is replaced atomically.
+func (c *translationCache) Save() error {
+	if c.fileName == "" {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	keys := c.arc.Keys()
+	entries := make([]cacheEntry, 0, len(keys))
+	for _, k := range keys {
+		key := k.(cacheKey)
+		if v, ok := c.arc.Peek(key); ok {
+			t := v.(translationResult)
+			entries = append(entries, cacheEntry{
+				GivenSourceLanguage: key.SourceLanguage,
+				TargetLanguage:      key.TargetLanguage,
+				Text:                key.Text,
+				Translation:         t.Text,
+				SourceLanguage:      t.SourceLanguage,
+			})
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(c.fileName), filepath.Base(c.fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	err = os.Rename(tmpFile.Name(), c.fileName)
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	c.dirty = false
+	return nil
+}
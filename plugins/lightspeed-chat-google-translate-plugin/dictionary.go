@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// dictionaryProvider is a deterministic offline provider (f.e. for tests): texts found in the dictionary of the target
+// language are replaced by their translation, all other texts are echoed with the language prefixed ("[de] text").
+type dictionaryProvider struct {
+	dictionary map[string]map[string]string // language (2 letters, lower case) -> text -> translation
+}
+
+func newDictionaryProvider(dictionary map[string]map[string]string) *dictionaryProvider {
+	p := &dictionaryProvider{dictionary: make(map[string]map[string]string)}
+	for language, entries := range dictionary {
+		if len(language) < 2 {
+			continue
+		}
+		p.dictionary[strings.ToLower(language[:2])] = entries
+	}
+	return p
+}
+
+func (p *dictionaryProvider) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error) {
+	if len(targetLanguage) < 2 {
+		return nil, fmt.Errorf("invalid target language %q", targetLanguage)
+	}
+	language := strings.ToLower(targetLanguage[:2])
+	translations := make([]translationResult, len(texts))
+	for i, text := range texts {
+		translations[i].SourceLanguage = shortLanguage(sourceLanguage)
+		if sameLanguage(sourceLanguage, language) {
+			continue
+		}
+		if t, ok := p.dictionary[language][text]; ok {
+			translations[i].Text = t
+		} else {
+			translations[i].Text = fmt.Sprintf("[%s] %s", language, text)
+		}
+	}
+	return translations, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	translate "cloud.google.com/go/translate/apiv3"
+	translatepb "google.golang.org/genproto/googleapis/cloud/translate/v3"
+)
+
+// googleProvider uses the google cloud translation API, the credentials are taken from the environment variable
+// GOOGLE_APPLICATION_CREDENTIALS.
+type googleProvider struct {
+	projectId string
+}
+
+func (p *googleProvider) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error) {
+	c, err := translate.NewTranslationClient(ctx)
+	if err != nil {
+		appLogger.Error("could not create translation client", "error", err)
+		return nil, err
+	}
+	defer c.Close()
+	req := &translatepb.TranslateTextRequest{
+		Contents:           texts,
+		SourceLanguageCode: sourceLanguage,
+		TargetLanguageCode: targetLanguage,
+		Parent:             fmt.Sprintf("projects/%s/locations/global", p.projectId),
+	}
+	resp, err := c.TranslateText(ctx, req)
+	if err != nil {
+		appLogger.Error("could not translate", "error", err)
+		return nil, err
+	}
+	if len(resp.Translations) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(resp.Translations), len(texts))
+	}
+	translations := make([]translationResult, len(texts))
+	for i, t := range resp.Translations {
+		translations[i].SourceLanguage = shortLanguage(sourceLanguage)
+		if t.DetectedLanguageCode != "" {
+			translations[i].SourceLanguage = shortLanguage(t.DetectedLanguageCode)
+		}
+		if !sameLanguage(translations[i].SourceLanguage, targetLanguage) {
+			translations[i].Text = html.UnescapeString(t.TranslatedText)
+		}
+	}
+	return translations, nil
+}
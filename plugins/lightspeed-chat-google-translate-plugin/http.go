@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	httpTimeout     = 30 * time.Second
+	deepLDefaultURL = "https://api-free.deepl.com"
+)
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
+
+// doJSON sends the request and decodes the JSON response into res.
+func doJSON(client *http.Client, req *http.Request, res interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("translation request failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// libreTranslateProvider uses the HTTP API of LibreTranslate (or any other Argos Translate based, compatible server).
+type libreTranslateProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateDetectedLanguage struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText   []string                         `json:"translatedText"`
+	DetectedLanguage []libreTranslateDetectedLanguage `json:"detectedLanguage"`
+}
+
+func (p *libreTranslateProvider) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error) {
+	source := shortLanguage(sourceLanguage)
+	if source == "" {
+		source = "auto"
+	}
+	body, err := json.Marshal(libreTranslateRequest{
+		Q:      texts,
+		Source: source,
+		Target: strings.ToLower(targetLanguage[:2]),
+		Format: "text",
+		APIKey: p.apiKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.url, "/")+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res := libreTranslateResponse{}
+	err = doJSON(p.client, req, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(res.TranslatedText), len(texts))
+	}
+	translations := make([]translationResult, len(texts))
+	for i, t := range res.TranslatedText {
+		translations[i].SourceLanguage = shortLanguage(sourceLanguage)
+		if i < len(res.DetectedLanguage) && res.DetectedLanguage[i].Language != "" {
+			translations[i].SourceLanguage = shortLanguage(res.DetectedLanguage[i].Language)
+		}
+		if !sameLanguage(translations[i].SourceLanguage, targetLanguage) {
+			translations[i].Text = t
+		}
+	}
+	return translations, nil
+}
+
+// deepLProvider uses the DeepL API (v2) or a compatible server.
+type deepLProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+// deepLTargetLanguage converts a language code like "de-DE" to a DeepL target language, only English and Portuguese
+// require the variant.
+func deepLTargetLanguage(language string) string {
+	parts := strings.SplitN(strings.ToUpper(strings.Replace(language, "_", "-", -1)), "-", 2)
+	switch parts[0] {
+	case "EN":
+		if len(parts) == 2 && parts[1] == "GB" {
+			return "EN-GB"
+		}
+		return "EN-US"
+	case "PT":
+		if len(parts) == 2 && parts[1] == "BR" {
+			return "PT-BR"
+		}
+		return "PT-PT"
+	}
+	return parts[0]
+}
+
+func (p *deepLProvider) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error) {
+	form := url.Values{}
+	for _, text := range texts {
+		form.Add("text", text)
+	}
+	if source := shortLanguage(sourceLanguage); source != "" {
+		form.Set("source_lang", strings.ToUpper(source))
+	}
+	form.Set("target_lang", deepLTargetLanguage(targetLanguage))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.url, "/")+"/v2/translate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+p.apiKey)
+	res := deepLResponse{}
+	err = doJSON(p.client, req, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Translations) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(res.Translations), len(texts))
+	}
+	translations := make([]translationResult, len(texts))
+	for i, t := range res.Translations {
+		translations[i].SourceLanguage = shortLanguage(sourceLanguage)
+		if t.DetectedSourceLanguage != "" {
+			translations[i].SourceLanguage = shortLanguage(t.DetectedSourceLanguage)
+		}
+		if !sameLanguage(translations[i].SourceLanguage, targetLanguage) {
+			translations[i].Text = t.Text
+		}
+	}
+	return translations, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/plugins"
+)
+
+const (
+	defaultLanguageGracePeriod = 5 * time.Minute
+	roomLanguagesTimeout       = time.Second
+)
+
+// roomLanguages keeps track of the languages of the clients connected to each room, so that messages are only
+// translated into languages somebody is actually reading. A language stays active for a grace period after its last
+// client has left (clients often reconnect).
+type roomLanguages struct {
+	gracePeriod time.Duration
+
+	sync.Mutex
+	helpers  map[string]plugins.EmitEventsHelper // room id -> helper of the room's hub
+	lastSeen map[string]map[string]time.Time     // room id -> language (2 letters) -> last time a client was connected
+}
+
+func newRoomLanguages(gracePeriod time.Duration) *roomLanguages {
+	if gracePeriod <= 0 {
+		gracePeriod = defaultLanguageGracePeriod
+	}
+	return &roomLanguages{
+		gracePeriod: gracePeriod,
+		helpers:     make(map[string]plugins.EmitEventsHelper),
+		lastSeen:    make(map[string]map[string]time.Time),
+	}
+}
+
+// setHelper registers (or, if eh is nil, removes) the helper of a room.
+func (r *roomLanguages) setHelper(roomId string, eh plugins.EmitEventsHelper) {
+	r.Lock()
+	defer r.Unlock()
+	if eh == nil {
+		delete(r.helpers, roomId)
+		return
+	}
+	r.helpers[roomId] = eh
+}
+
+// filter returns the subset of languages which are active in the room. If the languages of the room cannot be
+// determined, all languages are returned.
+func (r *roomLanguages) filter(ctx context.Context, roomId string, languages []string, now time.Time) []string {
+	r.Lock()
+	eh, ok := r.helpers[roomId]
+	r.Unlock()
+	if !ok {
+		return languages
+	}
+	ctx, cancel := context.WithTimeout(ctx, roomLanguagesTimeout)
+	defer cancel()
+	connected, err := eh.GetRoomLanguages(ctx, roomId)
+	if err != nil {
+		appLogger.Error("could not get room languages, translating into all languages", "room", roomId, "error", err)
+		return languages
+	}
+	return r.update(roomId, connected, languages, now)
+}
+
+// update records the connected languages and returns the subset of languages which have been connected within the
+// grace period.
+func (r *roomLanguages) update(roomId string, connected []string, languages []string, now time.Time) []string {
+	r.Lock()
+	defer r.Unlock()
+	lastSeen, ok := r.lastSeen[roomId]
+	if !ok {
+		lastSeen = make(map[string]time.Time)
+		r.lastSeen[roomId] = lastSeen
+	}
+	for _, language := range connected {
+		if len(language) >= 2 {
+			lastSeen[strings.ToLower(language[:2])] = now
+		}
+	}
+	active := make([]string, 0, len(languages))
+	for _, language := range languages {
+		if t, ok := lastSeen[strings.ToLower(language[:2])]; ok && now.Sub(t) <= r.gracePeriod {
+			active = append(active, language)
+		}
+	}
+	return active
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+)
+
+type fakeHelper struct {
+	plugins.EmitEventsHelper
+	languages []string
+	err       error
+}
+
+func (h *fakeHelper) GetRoomLanguages(ctx context.Context, roomId string) ([]string, error) {
+	return h.languages, h.err
+}
+
+func TestRoomLanguages(t *testing.T) {
+	configured := []string{"de-DE", "es-ES", "en-US"}
+	r := newRoomLanguages(time.Minute)
+	now := time.Now()
+
+	assert.Equal(t, configured, r.filter(context.Background(), "room", configured, now), "unknown rooms get all languages")
+
+	h := &fakeHelper{languages: []string{"de", "fr"}}
+	r.setHelper("room", h)
+	assert.Equal(t, []string{"de-DE"}, r.filter(context.Background(), "room", configured, now))
+
+	h.languages = []string{"en"}
+	assert.Equal(t, []string{"de-DE", "en-US"}, r.filter(context.Background(), "room", configured, now.Add(30*time.Second)), "de is still in its grace period")
+	assert.Equal(t, []string{"en-US"}, r.filter(context.Background(), "room", configured, now.Add(2*time.Minute)))
+
+	h.languages = nil
+	assert.Empty(t, r.filter(context.Background(), "room", configured, now.Add(5*time.Minute)))
+
+	h.err = fmt.Errorf("lost connection")
+	assert.Equal(t, configured, r.filter(context.Background(), "room", configured, now.Add(5*time.Minute)))
+
+	r.setHelper("room", nil)
+	h.err = nil
+	assert.Equal(t, configured, r.filter(context.Background(), "room", configured, now.Add(5*time.Minute)))
+}
@@ -3,49 +3,50 @@ package main
 import (
 	"context"
 	"fmt"
-	"html"
 	"strconv"
 	"strings"
 	"time"
 
-	translate "cloud.google.com/go/translate/apiv3"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
-	"github.com/hashicorp/golang-lru"
 	"github.com/mitchellh/mapstructure"
 	"github.com/tcriess/lightspeed-chat/plugins"
 	"github.com/tcriess/lightspeed-chat/types"
-	translatepb "google.golang.org/genproto/googleapis/cloud/translate/v3"
 )
 
 const (
-	translatorNick     = "translatorBot"
-	translatorText     = "translatorBot active"
-	translatorHelpText = `### Translator plugin ###
- -> all chat messages are automatically translated into all supported languages, no further commands are required`
-	helpCommand            = "/help"
-	translatorTextLanguage = "en-US"
-	pluginName             = "google-translate"
+	translatorNick           = "translatorBot"
+	translatorText           = "translatorBot active"
+	translatorTextLanguage   = "en-US"
+	pluginName               = "google-translate"
+	defaultCacheSaveInterval = time.Minute
 )
 
 type config struct {
-	LogLevel  string   `mapstructure:"log_level"`
-	CronSpec  string   `mapstructure:"cron_spec"`
-	ProjectId string   `mapstructure:"project_id"`
-	Languages []string `mapstructure:"languages"`
-	CacheSize int      `mapstructure:"cache_size"`
+	LogLevel          string                       `mapstructure:"log_level"`
+	CronSpec          string                       `mapstructure:"cron_spec"`
+	Priority          int                          `mapstructure:"priority"`
+	Provider          string                       `mapstructure:"provider"`   // google (default), libretranslate, deepl or dictionary
+	ProjectId         string                       `mapstructure:"project_id"` // google only
+	URL               string                       `mapstructure:"url"`        // libretranslate and deepl only
+	APIKey            string                       `mapstructure:"api_key"`    // libretranslate and deepl only
+	Dictionary        map[string]map[string]string `mapstructure:"dictionary"` // dictionary only
+	Languages         []string                     `mapstructure:"languages"`
+	CacheSize         int                          `mapstructure:"cache_size"`
+	CacheFile         string                       `mapstructure:"cache_file"`
+	CacheSaveInterval time.Duration                `mapstructure:"cache_save_interval"`
+	TranslateAll      bool                         `mapstructure:"translate_all"`         // translate into all languages, even if nobody reads them
+	LanguageGrace     time.Duration                `mapstructure:"language_grace_period"` // keep translating after the last client of a language left
+	DetectLanguage    bool                         `mapstructure:"detect_language"`       // let the provider detect the language instead of trusting the event language
 }
 
 var (
 	pluginConfig config
-	cache        *lru.ARCCache
+	cache        *translationCache
+	provider     translationProvider
+	activeLangs  = newRoomLanguages(defaultLanguageGracePeriod)
 )
 
-type cacheKey struct {
-	TargetLanguage string
-	Text           string
-}
-
 var appLogger = hclog.New(&hclog.LoggerOptions{
 	Name:  pluginName,
 	Level: hclog.LevelFromString("DEBUG"),
@@ -54,9 +55,10 @@ var appLogger = hclog.New(&hclog.LoggerOptions{
 // Here is a real implementation of the plugin interface
 type EventHandler struct{}
 
-func (m *EventHandler) HandleEvents(events []*types.Event) ([]*types.Event, error) {
+func (m *EventHandler) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
 	appLogger.Info("in HandleEvents", "events", events, "projectId", pluginConfig.ProjectId, "languages", pluginConfig.Languages)
 	outEvents := make([]*types.Event, 0)
+	translationRequests := make([]*types.Event, 0)
 
 	for _, event := range events {
 		source := &types.Source{
@@ -73,9 +75,20 @@ func (m *EventHandler) HandleEvents(events []*types.Event) ([]*types.Event, erro
 				continue
 			}
 
-			for _, language := range pluginConfig.Languages {
-				isoLang := language[0:2]
-				res, err := translation([]string{message}, language)
+			languages := pluginConfig.Languages
+			if !pluginConfig.TranslateAll && event.Room != nil {
+				languages = activeLangs.filter(ctx, event.Room.Id, languages, time.Now())
+			}
+			sourceLanguage := ""
+			if !pluginConfig.DetectLanguage {
+				sourceLanguage = shortLanguage(event.Language)
+			}
+			for _, language := range languages {
+				isoLang := shortLanguage(language)
+				if sameLanguage(sourceLanguage, isoLang) {
+					continue
+				}
+				res, err := translation(ctx, []string{message}, sourceLanguage, language)
 				if err != nil {
 					return outEvents, err
 				}
@@ -83,69 +96,163 @@ func (m *EventHandler) HandleEvents(events []*types.Event) ([]*types.Event, erro
 					appLogger.Info("no translation")
 					continue
 				}
-				if res[0] != "" {
-					filter := event.TargetFilter
-					if filter != "" {
-						filter = fmt.Sprintf(`( %s ) && Target.Client.ClientLanguage startsWith %s`, filter, strconv.Quote(isoLang))
-					} else {
-						filter = fmt.Sprintf(`Target.Client.ClientLanguage startsWith %s`, strconv.Quote(isoLang))
-					}
-					tags := map[string]string{
-						"message":   res[0],
-						"source_id": event.Id,
-					}
-					outEvent := types.NewEvent(event.Room, source, filter, isoLang, types.EventTypeTranslation, tags)
-					outEvents = append(outEvents, outEvent)
+				if res[0].Text != "" && !sameLanguage(res[0].SourceLanguage, isoLang) {
+					outEvents = append(outEvents, translationEvent(event.Room, source, event.Id, event.TargetFilter, isoLang, res[0]))
 				}
 			}
 
-		case types.EventTypeCommand:
-			command, ok := event.Tags["command"]
-			if !ok {
-				continue
-			}
-			switch command {
-			case helpCommand:
-				tags := map[string]string{
-					"message": translatorHelpText,
-				}
-				outEvent := types.NewEvent(event.Room, source, fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(event.Source.User.Id)), translatorTextLanguage, types.EventTypeChat, tags)
-				translatedEvents, _ := m.HandleEvents([]*types.Event{outEvent})
-				outEvents = append(outEvents, outEvent)
-				if len(translatedEvents) > 0 {
-					outEvents = append(outEvents, translatedEvents...)
-				}
-			}
+		case types.EventTypeTranslationRequest:
+			translationRequests = append(translationRequests, event)
 
 		default:
 			continue
 		}
 
 	}
+	if len(translationRequests) > 0 {
+		res, err := handleTranslationRequests(ctx, translationRequests)
+		if err != nil {
+			return outEvents, err
+		}
+		outEvents = append(outEvents, res...)
+	}
 	return outEvents, nil
 }
 
-func (m *EventHandler) Configure(val map[string]interface{}) (string, string, error) {
-	err := mapstructure.WeakDecode(val, &pluginConfig)
+// translationEvent creates the translation event of the message sourceId, it is only sent to clients using language.
+func translationEvent(room *types.Room, source *types.Source, sourceId, targetFilter, language string, res translationResult) *types.Event {
+	filter := fmt.Sprintf(`Target.Client.ClientLanguage startsWith %s`, strconv.Quote(language))
+	if targetFilter != "" {
+		filter = fmt.Sprintf(`( %s ) && %s`, targetFilter, filter)
+	}
+	tags := map[string]string{
+		"message":         res.Text,
+		"source_id":       sourceId,
+		"source_language": res.SourceLanguage,
+	}
+	return types.NewEvent(room, source, filter, language, types.EventTypeTranslation, tags)
+}
+
+// handleTranslationRequests answers the translation requests of the chat server (f.e. for the history of a newly
+// joined client). Only requests for configured languages are answered, all requests with the same target and source
+// language are translated in one call.
+func handleTranslationRequests(ctx context.Context, requests []*types.Event) ([]*types.Event, error) {
+	type batchKey struct {
+		targetLanguage string
+		sourceLanguage string
+	}
+	batches := make(map[batchKey][]*types.Event)
+	keys := make([]batchKey, 0)
+	for _, request := range requests {
+		target := ""
+		for _, language := range pluginConfig.Languages {
+			if sameLanguage(language, request.Tags["target_language"]) {
+				target = language
+				break
+			}
+		}
+		if target == "" || request.Tags["message"] == "" {
+			continue
+		}
+		key := batchKey{targetLanguage: target}
+		if !pluginConfig.DetectLanguage {
+			key.sourceLanguage = shortLanguage(request.Language)
+		}
+		if sameLanguage(key.sourceLanguage, target) {
+			continue
+		}
+		if _, ok := batches[key]; !ok {
+			keys = append(keys, key)
+		}
+		batches[key] = append(batches[key], request)
+	}
+	outEvents := make([]*types.Event, 0)
+	for _, key := range keys {
+		batch := batches[key]
+		texts := make([]string, len(batch))
+		for i, request := range batch {
+			texts[i] = request.Tags["message"]
+		}
+		res, err := translation(ctx, texts, key.sourceLanguage, key.targetLanguage)
+		if err != nil {
+			return outEvents, err
+		}
+		isoLang := shortLanguage(key.targetLanguage)
+		for i, request := range batch {
+			if i >= len(res) || res[i].Text == "" || sameLanguage(res[i].SourceLanguage, isoLang) {
+				continue
+			}
+			source := &types.Source{
+				User:       request.User,
+				PluginName: pluginName,
+			}
+			outEvents = append(outEvents, translationEvent(request.Room, source, request.Tags["source_id"], request.TargetFilter, isoLang, res[i]))
+		}
+	}
+	return outEvents, nil
+}
+
+func decodeConfig(val map[string]interface{}, cfg *config) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
 	if err != nil {
-		return "", "", err
+		return err
+	}
+	return decoder.Decode(val)
+}
+
+func (m *EventHandler) Configure(ctx context.Context, val map[string]interface{}) (plugins.Configuration, error) {
+	err := decodeConfig(val, &pluginConfig)
+	if err != nil {
+		return plugins.Configuration{}, err
 	}
 	if pluginConfig.LogLevel != "" {
 		appLogger.SetLevel(hclog.LevelFromString(pluginConfig.LogLevel))
 	}
-	appLogger.Info("in plugin configure", "val", val)
-	if pluginConfig.CacheSize > 0 {
-		if c, err := lru.NewARC(int(pluginConfig.CacheSize)); err == nil {
-			cache = c
-		} else {
-			appLogger.Error("could not create lru cache", "error", err)
+	appLogger.Info("in plugin configure", "provider", pluginConfig.Provider, "languages", pluginConfig.Languages)
+	for _, language := range pluginConfig.Languages {
+		if len(language) < 2 {
+			return plugins.Configuration{}, fmt.Errorf("invalid language %q", language)
 		}
 	}
-	eventFilter := fmt.Sprintf(`(Name=="command" && (Tags["command"] in [%s])) || Name == "chat"`, strconv.Quote(helpCommand))
-	return pluginConfig.CronSpec, eventFilter, nil
+	provider, err = newProvider(pluginConfig)
+	if err != nil {
+		return plugins.Configuration{}, err
+	}
+	activeLangs = newRoomLanguages(pluginConfig.LanguageGrace)
+	cache, err = newTranslationCache(pluginConfig.CacheSize, pluginConfig.CacheFile)
+	if err != nil {
+		return plugins.Configuration{}, err
+	}
+	err = cache.Load()
+	if err != nil {
+		appLogger.Error("could not load translation cache", "file", pluginConfig.CacheFile, "error", err)
+	}
+	if pluginConfig.CacheFile != "" {
+		interval := pluginConfig.CacheSaveInterval
+		if interval <= 0 {
+			interval = defaultCacheSaveInterval
+		}
+		go saveCacheLoop(cache, interval)
+	}
+	eventFilter := fmt.Sprintf(`Name == "chat" || Name == %s`, strconv.Quote(types.EventTypeTranslationRequest))
+	return plugins.Configuration{
+		CronSpec:     pluginConfig.CronSpec,
+		EventsFilter: eventFilter,
+		Priority:     pluginConfig.Priority,
+		Kind:         plugins.KindObserver,
+	}, nil
+}
+
+// FilterEvents is never called, the plugin is an observer.
+func (m *EventHandler) FilterEvents(ctx context.Context, events []*types.Event) ([]*plugins.FilterResult, error) {
+	return plugins.PassAll(events), nil
 }
 
-func (m *EventHandler) Cron(room *types.Room) ([]*types.Event, error) {
+func (m *EventHandler) Cron(ctx context.Context, room *types.Room) ([]*types.Event, error) {
 	tags := map[string]string{
 		"message": translatorText,
 	}
@@ -155,7 +262,7 @@ func (m *EventHandler) Cron(room *types.Room) ([]*types.Event, error) {
 	}
 	event := types.NewEvent(room, source, "", translatorTextLanguage, types.EventTypeChat, tags)
 	events := []*types.Event{event}
-	outEvents, err := m.HandleEvents(events)
+	outEvents, err := m.HandleEvents(ctx, events)
 	if err != nil {
 		return events, err
 	}
@@ -163,32 +270,49 @@ func (m *EventHandler) Cron(room *types.Room) ([]*types.Event, error) {
 	return events, nil
 }
 
-// make this run forever!
-func (m *EventHandler) InitEmitEvents(room *types.Room, eh plugins.EmitEventsHelper) error {
+// make this run until the main process cancels the context!
+func (m *EventHandler) InitEmitEvents(ctx context.Context, room *types.Room, eh plugins.EmitEventsHelper) error {
 	appLogger.Info("in plugin initEmitEvents")
+	if room != nil {
+		activeLangs.setHelper(room.Id, eh)
+		defer activeLangs.setHelper(room.Id, nil)
+	}
 
 	appLogger.Debug("start emit events loop")
-	for {
-		<-time.After(60 * time.Second)
+	<-ctx.Done()
+	if cache != nil {
+		err := cache.Save()
+		if err != nil {
+			appLogger.Error("could not save translation cache", "error", err)
+		}
 	}
+	return ctx.Err()
+}
 
-	return nil
+// saveCacheLoop saves the cache regularly, the plugin process may be killed at any time.
+func saveCacheLoop(c *translationCache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		err := c.Save()
+		if err != nil {
+			appLogger.Error("could not save translation cache", "error", err)
+		}
+	}
 }
 
-func translation(srcText []string, language string) ([]string, error) {
-	appLogger.Info("in translation", "srcText", srcText, "language", language)
-	translations := make([]string, len(srcText))
+// translation translates srcText into language using the cache and the configured provider. An empty sourceLanguage
+// means that the provider detects the language.
+func translation(ctx context.Context, srcText []string, sourceLanguage, language string) ([]translationResult, error) {
+	appLogger.Info("in translation", "srcText", srcText, "sourceLanguage", sourceLanguage, "language", language)
+	translations := make([]translationResult, len(srcText))
 	if len(srcText) == 0 {
 		return translations, nil
 	}
 	toTranslateIdx := make([]int, 0)
 	for i, s := range srcText {
-		k := cacheKey{
-			TargetLanguage: language,
-			Text:           s,
-		}
-		if v, ok := cache.Get(k); ok {
-			translations[i] = v.(string)
+		if v, ok := cache.Get(sourceLanguage, language, s); ok {
+			translations[i] = v
 			appLogger.Debug("found translation in cache!")
 		} else {
 			toTranslateIdx = append(toTranslateIdx, i)
@@ -201,37 +325,14 @@ func translation(srcText []string, language string) ([]string, error) {
 	for i, idx := range toTranslateIdx {
 		toTranslate[i] = srcText[idx]
 	}
-	ctx := context.Background()
-	ctx, _ = context.WithTimeout(ctx, time.Second)
-	c, err := translate.NewTranslationClient(ctx)
-	if err != nil {
-		appLogger.Error("could not create translation client", "error", err)
-		return nil, err
-	}
-	req := &translatepb.TranslateTextRequest{
-		Contents: toTranslate, // srcText,
-		//MimeType:           "",
-		//SourceLanguageCode: "",
-		TargetLanguageCode: language,
-		Parent:             fmt.Sprintf("projects/%s/locations/global", pluginConfig.ProjectId),
-		//Model:              "",
-		//GlossaryConfig:     nil,
-		//Labels:             nil,
-	}
-	resp, err := c.TranslateText(ctx, req)
+	res, err := provider.Translate(ctx, toTranslate, sourceLanguage, language)
 	if err != nil {
 		appLogger.Error("could not translate", "error", err)
 		return nil, err
 	}
-	for i, t := range resp.Translations {
-		if t.DetectedLanguageCode[:2] != language[:2] {
-			translations[toTranslateIdx[i]] = html.UnescapeString(t.TranslatedText)
-			k := cacheKey{
-				TargetLanguage: language,
-				Text:           srcText[toTranslateIdx[i]],
-			}
-			cache.Add(k, html.UnescapeString(t.TranslatedText))
-		}
+	for i, t := range res {
+		translations[toTranslateIdx[i]] = t
+		cache.Add(sourceLanguage, language, srcText[toTranslateIdx[i]], t)
 	}
 	appLogger.Debug("translated", "translations", translations)
 	return translations, nil
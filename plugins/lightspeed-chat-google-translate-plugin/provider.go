@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	providerGoogle         = "google"
+	providerLibreTranslate = "libretranslate"
+	providerDeepL          = "deepl"
+	providerDictionary     = "dictionary"
+)
+
+// translationResult is the translation of a single text.
+type translationResult struct {
+	Text           string // empty if the text is already in the target language
+	SourceLanguage string // the (detected) language of the text, 2 letters, lower case, empty if unknown
+}
+
+// translationProvider translates texts into a target language.
+type translationProvider interface {
+	// Translate returns one result per text (in the same order). If sourceLanguage is empty, the provider detects the
+	// language of the texts.
+	Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error)
+}
+
+// newProvider creates the provider configured in cfg.
+func newProvider(cfg config) (translationProvider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", providerGoogle:
+		return &googleProvider{projectId: cfg.ProjectId}, nil
+	case providerLibreTranslate:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("provider %s requires a url", providerLibreTranslate)
+		}
+		return &libreTranslateProvider{url: cfg.URL, apiKey: cfg.APIKey, client: newHTTPClient()}, nil
+	case providerDeepL:
+		url := cfg.URL
+		if url == "" {
+			url = deepLDefaultURL
+		}
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("provider %s requires an api_key", providerDeepL)
+		}
+		return &deepLProvider{url: url, apiKey: cfg.APIKey, client: newHTTPClient()}, nil
+	case providerDictionary:
+		return newDictionaryProvider(cfg.Dictionary), nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q", cfg.Provider)
+	}
+}
+
+// shortLanguage returns the first 2 letters of the language code in lower case, or the empty string.
+func shortLanguage(language string) string {
+	if len(language) < 2 {
+		return ""
+	}
+	return strings.ToLower(language[:2])
+}
+
+// sameLanguage reports whether the two language codes denote the same language (region is ignored).
+func sameLanguage(a, b string) bool {
+	if len(a) < 2 || len(b) < 2 {
+		return false
+	}
+	return strings.EqualFold(a[:2], b[:2])
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestDictionaryProvider(t *testing.T) {
+	p, err := newProvider(config{
+		Provider:   providerDictionary,
+		Dictionary: map[string]map[string]string{"de-DE": {"hello": "hallo"}},
+	})
+	assert.NoError(t, err)
+	res, err := p.Translate(context.Background(), []string{"hello", "world"}, "en", "de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "hallo", SourceLanguage: "en"}, {Text: "[de] world", SourceLanguage: "en"}}, res)
+	res, err = p.Translate(context.Background(), []string{"hallo"}, "de", "de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{SourceLanguage: "de"}}, res)
+}
+
+func TestLibreTranslateProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		req := libreTranslateRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "de", req.Target)
+		assert.Equal(t, "auto", req.Source)
+		assert.Equal(t, "secret", req.APIKey)
+		res := libreTranslateResponse{}
+		for _, q := range req.Q {
+			if q == "hallo" {
+				res.TranslatedText = append(res.TranslatedText, q)
+				res.DetectedLanguage = append(res.DetectedLanguage, libreTranslateDetectedLanguage{Language: "de", Confidence: 90})
+			} else {
+				res.TranslatedText = append(res.TranslatedText, "übersetzt: "+q)
+				res.DetectedLanguage = append(res.DetectedLanguage, libreTranslateDetectedLanguage{Language: "en", Confidence: 90})
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	p, err := newProvider(config{Provider: providerLibreTranslate, URL: server.URL + "/", APIKey: "secret"})
+	assert.NoError(t, err)
+	res, err := p.Translate(context.Background(), []string{"hello", "hallo"}, "", "de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "übersetzt: hello", SourceLanguage: "en"}, {SourceLanguage: "de"}}, res)
+
+	_, err = newProvider(config{Provider: providerLibreTranslate})
+	assert.Error(t, err)
+}
+
+func TestDeepLProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/translate", r.URL.Path)
+		assert.Equal(t, "DeepL-Auth-Key secret", r.Header.Get("Authorization"))
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "EN-GB", r.PostForm.Get("target_lang"))
+		assert.Equal(t, "", r.PostForm.Get("source_lang"))
+		if r.PostForm.Get("text") == "fail" {
+			http.Error(w, "quota exceeded", 456)
+			return
+		}
+		res := deepLResponse{}
+		res.Translations = make([]struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		}, len(r.PostForm["text"]))
+		for i, text := range r.PostForm["text"] {
+			res.Translations[i].DetectedSourceLanguage = "DE"
+			res.Translations[i].Text = "translated: " + text
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	p, err := newProvider(config{Provider: providerDeepL, URL: server.URL, APIKey: "secret"})
+	assert.NoError(t, err)
+	res, err := p.Translate(context.Background(), []string{"hallo", "welt"}, "", "en-GB")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "translated: hallo", SourceLanguage: "de"}, {Text: "translated: welt", SourceLanguage: "de"}}, res)
+	_, err = p.Translate(context.Background(), []string{"fail"}, "", "en-GB")
+	assert.Error(t, err)
+
+	assert.Equal(t, "DE", deepLTargetLanguage("de-DE"))
+	assert.Equal(t, "EN-US", deepLTargetLanguage("en"))
+	assert.Equal(t, "PT-BR", deepLTargetLanguage("pt_br"))
+}
+
+func TestUnknownProvider(t *testing.T) {
+	_, err := newProvider(config{Provider: "babelfish"})
+	assert.Error(t, err)
+}
+
+func TestTranslationCache(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "cache.json")
+	var err error
+	cache, err = newTranslationCache(10, fileName)
+	assert.NoError(t, err)
+	provider = &countingProvider{translationProvider: newDictionaryProvider(nil)}
+	res, err := translation(context.Background(), []string{"a", "b"}, "en", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] a", SourceLanguage: "en"}, {Text: "[de] b", SourceLanguage: "en"}}, res)
+	res, err = translation(context.Background(), []string{"b", "c"}, "en", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] b", SourceLanguage: "en"}, {Text: "[de] c", SourceLanguage: "en"}}, res)
+	assert.Equal(t, 3, provider.(*countingProvider).count, "cached texts are not translated again")
+	assert.NoError(t, cache.Save())
+
+	// a restart with a different provider uses the cached translations
+	cache, err = newTranslationCache(10, fileName)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Load())
+	provider = &countingProvider{translationProvider: newDictionaryProvider(nil)}
+	res, err = translation(context.Background(), []string{"a", "c"}, "en", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] a", SourceLanguage: "en"}, {Text: "[de] c", SourceLanguage: "en"}}, res)
+	assert.Equal(t, 0, provider.(*countingProvider).count)
+
+	// the same text with a different source language is translated again, and cached separately
+	res, err = translation(context.Background(), []string{"a"}, "fr", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] a", SourceLanguage: "fr"}}, res)
+	assert.Equal(t, 1, provider.(*countingProvider).count)
+	assert.NoError(t, cache.Save())
+
+	cache, err = newTranslationCache(10, fileName)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Load())
+	provider = &countingProvider{translationProvider: newDictionaryProvider(nil)}
+	res, err = translation(context.Background(), []string{"a", "a"}, "en", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] a", SourceLanguage: "en"}, {Text: "[de] a", SourceLanguage: "en"}}, res)
+	res, err = translation(context.Background(), []string{"a"}, "fr", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, []translationResult{{Text: "[de] a", SourceLanguage: "fr"}}, res)
+	assert.Equal(t, 0, provider.(*countingProvider).count)
+}
+
+type countingProvider struct {
+	translationProvider
+	count int
+}
+
+func (p *countingProvider) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage string) ([]translationResult, error) {
+	p.count += len(texts)
+	return p.translationProvider.Translate(ctx, texts, sourceLanguage, targetLanguage)
+}
+
+func TestHandleEventsSkipsSourceLanguage(t *testing.T) {
+	var err error
+	cache, err = newTranslationCache(10, "")
+	assert.NoError(t, err)
+	provider = newDictionaryProvider(nil)
+	pluginConfig = config{Languages: []string{"de-DE", "en-US"}, TranslateAll: true}
+	source := &types.Source{User: &types.User{Id: "u1"}}
+	event := types.NewEvent(&types.Room{Id: "room"}, source, "", "de", types.EventTypeChat, map[string]string{"message": "hallo"})
+
+	h := &EventHandler{}
+	res, err := h.HandleEvents(context.Background(), []*types.Event{event})
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, types.EventTypeTranslation, res[0].Name)
+		assert.Equal(t, "en", res[0].Language)
+		assert.Equal(t, "de", res[0].Tags["source_language"])
+		assert.Equal(t, event.Id, res[0].Tags["source_id"])
+		assert.Equal(t, "[en] hallo", res[0].Tags["message"])
+	}
+}
+
+func TestHandleTranslationRequests(t *testing.T) {
+	var err error
+	cache, err = newTranslationCache(10, "")
+	assert.NoError(t, err)
+	counting := &countingProvider{translationProvider: newDictionaryProvider(nil)}
+	provider = counting
+	pluginConfig = config{Languages: []string{"de-DE", "en-US"}}
+	room := &types.Room{Id: "room"}
+	request := func(sourceId, language, message, target string) *types.Event {
+		tags := map[string]string{"source_id": sourceId, "message": message, "target_language": target}
+		return types.NewEvent(room, nil, "", language, types.EventTypeTranslationRequest, tags)
+	}
+	requests := []*types.Event{
+		request("1", "en", "hello", "de"),
+		request("2", "en", "world", "de"),
+		request("3", "de", "hallo", "de"), // already in the target language
+		request("4", "en", "hello", "fr"), // not configured
+	}
+	res, err := (&EventHandler{}).HandleEvents(context.Background(), requests)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, "1", res[0].Tags["source_id"])
+		assert.Equal(t, "[de] hello", res[0].Tags["message"])
+		assert.Equal(t, "de", res[0].Language)
+		assert.Equal(t, types.EventTypeTranslation, res[0].Name)
+		assert.Equal(t, "2", res[1].Tags["source_id"])
+	}
+	assert.Equal(t, 2, counting.count)
+}
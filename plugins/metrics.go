@@ -0,0 +1,11 @@
+package plugins
+
+import "expvar"
+
+// Counters for the calls from the main process to the plugins, keyed by "<plugin name>.<method>".
+// They are published via expvar (see /debug/vars).
+var (
+	pluginCalls    = expvar.NewMap("plugin_calls")
+	pluginErrors   = expvar.NewMap("plugin_errors")
+	pluginTimeouts = expvar.NewMap("plugin_timeouts")
+)
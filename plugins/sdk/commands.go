@@ -0,0 +1,161 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// CommandFunc handles a command, the returned events are emitted.
+type CommandFunc func(ctx context.Context, cmd *Command) ([]*types.Event, error)
+
+// Command is a command sent by a user, f.e. "/to bob hello".
+type Command struct {
+	Event      *types.Event // the command event
+	Name       string       // the command including the leading "/", f.e. "/to"
+	Args       []string     // the arguments, f.e. ["bob", "hello"]
+	RawArgs    string       // the arguments as sent, f.e. "bob hello"
+	PluginName string       // the name of the plugin handling the command
+}
+
+// Text returns the message following the first n arguments (with the original whitespace inside the message), f.e.
+// Text(1) of "/to bob hello  world" is "hello  world".
+func (c *Command) Text(n int) string {
+	text := strings.TrimSpace(c.Event.Tags["message"])
+	if strings.HasPrefix(text, c.Name) {
+		text = strings.TrimSpace(text[len(c.Name):])
+	} else {
+		text = strings.TrimSpace(c.RawArgs)
+	}
+	for i := 0; i < n; i++ {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return ""
+		}
+		text = strings.TrimSpace(text[strings.Index(text, fields[0])+len(fields[0]):])
+	}
+	return text
+}
+
+// Arg returns the value of the argument name as parsed by the server according to the command specification, or the
+// empty string if the (optional) argument is missing.
+func (c *Command) Arg(name string) string {
+	return c.Event.Tags["arg."+name]
+}
+
+// Source returns the source for events created by the plugin in reply to the command.
+func (c *Command) Source() *types.Source {
+	return &types.Source{
+		User:       c.Event.User,
+		PluginName: c.PluginName,
+	}
+}
+
+// Reply creates a chat message which is only sent to the sender of the command.
+func (c *Command) Reply(message string) *types.Event {
+	filter := fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.Event.Source.User.Id))
+	tags := map[string]string{
+		"message":   message,
+		"mime_type": "text/plain",
+	}
+	return types.NewEvent(c.Event.Room, c.Source(), filter, c.Event.Language, types.EventTypeChat, tags)
+}
+
+// Router routes command events (Tags["command"], Tags["args"]) to their handlers.
+type Router struct {
+	pluginName string
+	logger     hclog.Logger
+	handlers   map[string]CommandFunc
+	specs      map[string]plugins.CommandSpec
+}
+
+// NewRouter creates an empty router.
+func NewRouter(pluginName string, logger hclog.Logger) *Router {
+	return &Router{
+		pluginName: pluginName,
+		logger:     logger,
+		handlers:   make(map[string]CommandFunc),
+		specs:      make(map[string]plugins.CommandSpec),
+	}
+}
+
+// Handle registers the handler for the command, the leading "/" of the name is optional. The specification is passed
+// to the server, which parses the arguments (see Command.Arg) and answers /help.
+func (r *Router) Handle(spec plugins.CommandSpec, f CommandFunc) {
+	if !strings.HasPrefix(spec.Name, "/") {
+		spec.Name = "/" + spec.Name
+	}
+	r.handlers[spec.Name] = f
+	r.specs[spec.Name] = spec
+}
+
+// Specs returns the specifications of the registered commands, sorted by name.
+func (r *Router) Specs() []plugins.CommandSpec {
+	specs := make([]plugins.CommandSpec, 0, len(r.specs))
+	for _, command := range r.Commands() {
+		specs = append(specs, r.specs[command])
+	}
+	return specs
+}
+
+// Commands returns the sorted names of the registered commands.
+func (r *Router) Commands() []string {
+	commands := make([]string, 0, len(r.handlers))
+	for command := range r.handlers {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// Filter returns the events filter accepting the registered commands, or the empty string if there are none.
+func (r *Router) Filter() string {
+	commands := r.Commands()
+	if len(commands) == 0 {
+		return ""
+	}
+	quotedCommands := make([]string, len(commands))
+	for i, command := range commands {
+		quotedCommands[i] = strconv.Quote(command)
+	}
+	return fmt.Sprintf(`Name == "command" && (Tags["command"] in [%s])`, strings.Join(quotedCommands, ","))
+}
+
+// HandleEvents calls the handlers of the command events, other events are ignored. A failing handler is logged, the
+// other commands are handled anyway.
+func (r *Router) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	outEvents := make([]*types.Event, 0)
+	for _, event := range events {
+		if event.Name != types.EventTypeCommand {
+			continue
+		}
+		name, ok := event.Tags["command"]
+		if !ok {
+			continue
+		}
+		f, ok := r.handlers[name]
+		if !ok {
+			continue
+		}
+		cmd := &Command{
+			Event:      event,
+			Name:       name,
+			Args:       strings.Fields(event.Tags["args"]),
+			RawArgs:    event.Tags["args"],
+			PluginName: r.pluginName,
+		}
+		res, err := f(ctx, cmd)
+		if err != nil {
+			r.logger.Error("could not execute command", "command", name, "error", err)
+			continue
+		}
+		outEvents = append(outEvents, res...)
+	}
+	return outEvents, nil
+}
@@ -0,0 +1,177 @@
+// Package sdk makes writing lightspeed-chat plugins easier.
+//
+// A plugin embeds *Base, which implements all methods of plugins.EventHandler with sensible defaults, registers its
+// commands with the Router of the Base and calls Serve in its main function:
+//
+//	type EventHandler struct {
+//		*sdk.Base
+//	}
+//
+//	func main() {
+//		h := &EventHandler{Base: sdk.NewBase("example")}
+//		hello := plugins.CommandSpec{
+//			Name:        "/hello",
+//			Description: "say hello",
+//			Args:        []plugins.CommandArg{{Name: "name"}},
+//		}
+//		h.Commands.Handle(hello, func(ctx context.Context, cmd *sdk.Command) ([]*types.Event, error) {
+//			return []*types.Event{cmd.Reply("hello " + cmd.Arg("name"))}, nil
+//		})
+//		sdk.Serve(h)
+//	}
+//
+// The package sdktest contains an in-process test harness for plugins.
+package sdk
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// Settings are the configuration attributes which are understood by every plugin using Base.
+type Settings struct {
+	LogLevel string `mapstructure:"log_level"`
+	CronSpec string `mapstructure:"cron_spec"`
+	Priority int    `mapstructure:"priority"`
+}
+
+// Base implements plugins.EventHandler. Plugins embed *Base and override the methods they need.
+type Base struct {
+	Name     string       // the plugin name, used as PluginName in the source of the events created by the helpers
+	Kind     int          // plugins.KindObserver (default) or plugins.KindInterceptor
+	Logger   hclog.Logger // logs to go-plugin
+	Settings Settings     // set by Configure
+	Commands *Router      // the commands handled by HandleEvents
+
+	// EventsFilter is the filter for all events except commands (which are determined by Commands), f.e.
+	// `Name == "chat"`. Leave it empty if the plugin is only interested in its commands.
+	EventsFilter string
+
+	helpersLock sync.RWMutex
+	helpers     map[string]plugins.EmitEventsHelper // room id -> helper, while InitEmitEvents is running
+}
+
+// NewBase creates a Base for the plugin name.
+func NewBase(name string) *Base {
+	logger := NewLogger(name)
+	return &Base{
+		Name:     name,
+		Kind:     plugins.KindObserver,
+		Logger:   logger,
+		Commands: NewRouter(name, logger),
+		helpers:  make(map[string]plugins.EmitEventsHelper),
+	}
+}
+
+// NewLogger creates a logger for a plugin. It writes JSON to stderr, so that go-plugin passes the log entries on to
+// the logger of the chat server with their original level.
+func NewLogger(name string) hclog.Logger {
+	return hclog.New(&hclog.LoggerOptions{
+		Name:       name,
+		Level:      hclog.Debug,
+		Output:     os.Stderr,
+		JSONFormat: true,
+	})
+}
+
+// DecodeConfig decodes the raw plugin configuration into cfg (a pointer to a struct using mapstructure tags).
+// Values are converted if necessary, durations may be given as strings ("5s").
+func DecodeConfig(raw map[string]interface{}, cfg interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(raw)
+}
+
+// Serve serves the plugin, it never returns.
+func Serve(handler plugins.EventHandler) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugins.Handshake,
+		Plugins: map[string]plugin.Plugin{
+			"eventhandler": &plugins.EventHandlerPlugin{Impl: handler},
+		},
+		GRPCServer: plugin.DefaultGRPCServer,
+		Logger:     NewLogger("plugin"),
+	})
+}
+
+// Configure decodes the Settings and returns the configuration of the plugin. Plugins with their own configuration
+// call it first and decode their configuration using DecodeConfig afterwards.
+func (b *Base) Configure(ctx context.Context, val map[string]interface{}) (plugins.Configuration, error) {
+	err := DecodeConfig(val, &b.Settings)
+	if err != nil {
+		return plugins.Configuration{}, err
+	}
+	if b.Settings.LogLevel != "" {
+		b.Logger.SetLevel(hclog.LevelFromString(b.Settings.LogLevel))
+	}
+	eventsFilter := b.EventsFilter
+	if commandsFilter := b.Commands.Filter(); commandsFilter != "" {
+		if eventsFilter != "" {
+			eventsFilter = "(" + commandsFilter + ") || (" + eventsFilter + ")"
+		} else {
+			eventsFilter = commandsFilter
+		}
+	}
+	return plugins.Configuration{
+		CronSpec:     b.Settings.CronSpec,
+		EventsFilter: eventsFilter,
+		Priority:     b.Settings.Priority,
+		Kind:         b.Kind,
+		Commands:     b.Commands.Specs(),
+	}, nil
+}
+
+// HandleEvents passes the commands to the Router.
+func (b *Base) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	return b.Commands.HandleEvents(ctx, events)
+}
+
+// FilterEvents lets all events pass.
+func (b *Base) FilterEvents(ctx context.Context, events []*types.Event) ([]*plugins.FilterResult, error) {
+	return plugins.PassAll(events), nil
+}
+
+// Cron does nothing.
+func (b *Base) Cron(ctx context.Context, room *types.Room) ([]*types.Event, error) {
+	return nil, nil
+}
+
+// InitEmitEvents keeps the helper of the room (see Helper) until the context is cancelled.
+func (b *Base) InitEmitEvents(ctx context.Context, room *types.Room, eh plugins.EmitEventsHelper) error {
+	roomId := ""
+	if room != nil {
+		roomId = room.Id
+	}
+	b.helpersLock.Lock()
+	b.helpers[roomId] = eh
+	b.helpersLock.Unlock()
+	defer func() {
+		b.helpersLock.Lock()
+		if b.helpers[roomId] == eh {
+			delete(b.helpers, roomId)
+		}
+		b.helpersLock.Unlock()
+	}()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Helper returns the EmitEventsHelper of the room, or nil if the room is not (yet) connected.
+func (b *Base) Helper(roomId string) plugins.EmitEventsHelper {
+	b.helpersLock.RLock()
+	defer b.helpersLock.RUnlock()
+	return b.helpers[roomId]
+}
@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func commandEvent(message, command, args string) *types.Event {
+	source := &types.Source{User: &types.User{Id: "u1", Nick: "nick1"}}
+	tags := map[string]string{"message": message, "command": command, "args": args, "arg.text": strings.TrimSpace(message[len(command):])}
+	return types.NewEvent(&types.Room{Id: "room"}, source, "", "en", types.EventTypeCommand, tags)
+}
+
+func TestCommandText(t *testing.T) {
+	cmd := &Command{Event: commandEvent("/to bob  hello  world ", "/to", "bob hello world"), Name: "/to"}
+	assert.Equal(t, "bob  hello  world", cmd.Text(0))
+	assert.Equal(t, "hello  world", cmd.Text(1))
+	assert.Equal(t, "world", cmd.Text(2))
+	assert.Equal(t, "", cmd.Text(3))
+
+	reply := cmd.Reply("hi")
+	assert.Equal(t, types.EventTypeChat, reply.Name)
+	assert.Equal(t, `Target.User.Id == "u1"`, reply.TargetFilter)
+	assert.Equal(t, "hi", reply.Tags["message"])
+}
+
+func TestRouter(t *testing.T) {
+	base := NewBase("test")
+	called := make([]string, 0)
+	base.Commands.Handle(plugins.CommandSpec{Name: "echo", Args: []plugins.CommandArg{{Name: "text", Type: plugins.ArgTypeText}}}, func(ctx context.Context, cmd *Command) ([]*types.Event, error) {
+		called = append(called, cmd.Arg("text"))
+		return []*types.Event{cmd.Reply(cmd.Arg("text"))}, nil
+	})
+	base.Commands.Handle(plugins.CommandSpec{Name: "/fail"}, func(ctx context.Context, cmd *Command) ([]*types.Event, error) {
+		return nil, assert.AnError
+	})
+	base.EventsFilter = `Name == "chat"`
+	assert.Equal(t, []string{"/echo", "/fail"}, base.Commands.Commands())
+
+	cfg, err := base.Configure(context.Background(), map[string]interface{}{"priority": "3", "cron_spec": "@every 1m"})
+	assert.NoError(t, err)
+	assert.Equal(t, plugins.Configuration{
+		CronSpec:     "@every 1m",
+		EventsFilter: `(Name == "command" && (Tags["command"] in ["/echo","/fail"])) || (Name == "chat")`,
+		Priority:     3,
+		Kind:         plugins.KindObserver,
+		Commands: []plugins.CommandSpec{
+			{Name: "/echo", Args: []plugins.CommandArg{{Name: "text", Type: plugins.ArgTypeText}}},
+			{Name: "/fail"},
+		},
+	}, cfg)
+
+	res, err := base.HandleEvents(context.Background(), []*types.Event{
+		commandEvent("/fail", "/fail", ""),
+		commandEvent("/echo a  b", "/echo", "a b"),
+		commandEvent("/other", "/other", ""),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a  b"}, called)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "a  b", res[0].Tags["message"])
+		assert.Equal(t, "test", res[0].Source.PluginName)
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	cfg := struct {
+		Interval time.Duration `mapstructure:"interval"`
+		Count    int           `mapstructure:"count"`
+	}{}
+	err := DecodeConfig(map[string]interface{}{"interval": "5s", "count": "2"}, &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cfg.Interval)
+	assert.Equal(t, 2, cfg.Count)
+}
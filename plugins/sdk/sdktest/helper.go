@@ -0,0 +1,140 @@
+// Package sdktest runs plugins in-process for tests, without the chat server and without go-plugin.
+package sdktest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tcriess/lightspeed-chat/filter"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// FakeHelper implements plugins.EmitEventsHelper on top of in-memory users and rooms. Emitted events are recorded.
+type FakeHelper struct {
+	Users     map[string]*types.User // user id -> user
+	Rooms     map[string]*types.Room // room id -> room
+	Tokens    map[string]*types.User // provider + "\x00" + id token -> user, used by AuthenticateUser
+	Languages map[string][]string    // room id -> languages of the connected clients
+	Roles     map[string]string      // room id + "\x00" + user id -> role, types.RoleMember if not set
+	emitted   []*types.Event
+	sync.Mutex
+}
+
+// NewFakeHelper creates an empty FakeHelper.
+func NewFakeHelper() *FakeHelper {
+	return &FakeHelper{
+		Users:     make(map[string]*types.User),
+		Rooms:     make(map[string]*types.Room),
+		Tokens:    make(map[string]*types.User),
+		Languages: make(map[string][]string),
+		Roles:     make(map[string]string),
+	}
+}
+
+// Emitted returns the events emitted so far.
+func (f *FakeHelper) Emitted() []*types.Event {
+	f.Lock()
+	defer f.Unlock()
+	res := make([]*types.Event, len(f.emitted))
+	copy(res, f.emitted)
+	return res
+}
+
+func (f *FakeHelper) EmitEvents(ctx context.Context, events []*types.Event) error {
+	f.Lock()
+	defer f.Unlock()
+	f.emitted = append(f.emitted, events...)
+	return nil
+}
+
+func (f *FakeHelper) AuthenticateUser(ctx context.Context, idToken, provider string) (*types.User, error) {
+	f.Lock()
+	defer f.Unlock()
+	user, ok := f.Tokens[provider+"\x00"+idToken]
+	if !ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return user, nil
+}
+
+func (f *FakeHelper) GetUser(ctx context.Context, userId string) (*types.User, error) {
+	f.Lock()
+	defer f.Unlock()
+	user, ok := f.Users[userId]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", userId)
+	}
+	return user, nil
+}
+
+func (f *FakeHelper) GetRoom(ctx context.Context, roomId string) (*types.Room, error) {
+	f.Lock()
+	defer f.Unlock()
+	room, ok := f.Rooms[roomId]
+	if !ok {
+		return nil, fmt.Errorf("room not found: %s", roomId)
+	}
+	return room, nil
+}
+
+// ChangeUserTags changes the tags without checking the permissions of the acting user.
+func (f *FakeHelper) ChangeUserTags(ctx context.Context, actorId, userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	user, ok := f.Users[userId]
+	if !ok {
+		user = &types.User{Id: userId}
+		f.Users[userId] = user
+	}
+	if user.Tags == nil {
+		user.Tags = make(types.JSONStringMap)
+	}
+	return user, filter.UpdateTags(user.Tags, updates), nil
+}
+
+// ChangeRoomTags changes the tags without checking the permissions of the acting user.
+func (f *FakeHelper) ChangeRoomTags(ctx context.Context, roomId, actorId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	room, ok := f.Rooms[roomId]
+	if !ok {
+		return nil, nil, fmt.Errorf("room not found: %s", roomId)
+	}
+	if room.Tags == nil {
+		room.Tags = make(types.JSONStringMap)
+	}
+	return room, filter.UpdateTags(room.Tags, updates), nil
+}
+
+func (f *FakeHelper) GetRoomLanguages(ctx context.Context, roomId string) ([]string, error) {
+	f.Lock()
+	defer f.Unlock()
+	languages := append([]string{}, f.Languages[roomId]...)
+	sort.Strings(languages)
+	return languages, nil
+}
+
+func (f *FakeHelper) GetUserRole(ctx context.Context, roomId, userId string) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+	if room, ok := f.Rooms[roomId]; ok && room.Owner != nil && room.Owner.Id == userId {
+		return types.RoleOwner, nil
+	}
+	if role, ok := f.Roles[roomId+"\x00"+userId]; ok {
+		return role, nil
+	}
+	return types.RoleMember, nil
+}
+
+// SetUserRole sets the role without checking the permissions of the acting user.
+func (f *FakeHelper) SetUserRole(ctx context.Context, roomId, actorId, userId, role string) error {
+	if !types.ValidRole(role) {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.Roles[roomId+"\x00"+userId] = role
+	return nil
+}
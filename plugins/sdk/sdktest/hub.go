@@ -0,0 +1,221 @@
+package sdktest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/tcriess/lightspeed-chat/filter"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// connectTimeout is the maximum time Connect waits for the plugin to register the helper of the room.
+const connectTimeout = 2 * time.Second
+
+// Hub plays the part of the chat server for a single plugin and a single room: events are only passed to the plugin
+// if its events filter accepts them, and observers and interceptors are called the way the server calls them.
+type Hub struct {
+	Room          *types.Room
+	Helper        *FakeHelper
+	Configuration plugins.Configuration
+
+	handler plugins.EventHandler
+	filter  *vm.Program
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewHub configures the plugin with config. Call Connect to run InitEmitEvents and Close at the end of the test.
+func NewHub(handler plugins.EventHandler, config map[string]interface{}) (*Hub, error) {
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	cfg, err := handler.Configure(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	h := &Hub{
+		Room:          &types.Room{Id: "test", Owner: &types.User{Id: "owner", Nick: "owner"}},
+		Helper:        NewFakeHelper(),
+		Configuration: cfg,
+		handler:       handler,
+	}
+	if cfg.EventsFilter != "" {
+		h.filter, err = expr.Compile(cfg.EventsFilter, expr.Env(filter.Env{}))
+		if err != nil {
+			return nil, fmt.Errorf("could not compile events filter: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// Connect runs InitEmitEvents of the plugin in the background. If the plugin makes the helpers available via
+// Helper(roomId) (as sdk.Base does), Connect waits until the helper of the room is registered.
+func (h *Hub) Connect() error {
+	h.Helper.Lock()
+	h.Helper.Rooms[h.Room.Id] = h.Room
+	h.Helper.Unlock()
+	var ctx context.Context
+	ctx, h.cancel = context.WithCancel(context.Background())
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		_ = h.handler.InitEmitEvents(ctx, h.Room, h.Helper)
+	}()
+	withHelpers, ok := h.handler.(interface {
+		Helper(string) plugins.EmitEventsHelper
+	})
+	if !ok {
+		return nil
+	}
+	deadline := time.Now().Add(connectTimeout)
+	for withHelpers.Helper(h.Room.Id) == nil {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("plugin did not register the helper of room %s", h.Room.Id)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
+
+// Close cancels InitEmitEvents and waits for it to return.
+func (h *Hub) Close() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+}
+
+// Accepts reports whether the plugin receives the event: either it is one of the commands of the plugin, or the events
+// filter of the plugin accepts it.
+func (h *Hub) Accepts(event *types.Event) bool {
+	if _, ok := h.command(event); ok {
+		return true
+	}
+	if h.filter == nil {
+		return true
+	}
+	res, err := expr.Run(h.filter, filter.PluginEnv(h.Room, event))
+	if err != nil {
+		return false
+	}
+	b, ok := res.(bool)
+	return ok && b
+}
+
+// Send passes the events accepted by the events filter to HandleEvents of an observer plugin and returns the
+// resulting events.
+func (h *Hub) Send(events ...*types.Event) ([]*types.Event, error) {
+	if h.Configuration.Kind != plugins.KindObserver {
+		return nil, fmt.Errorf("the plugin is not an observer")
+	}
+	passEvents := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		if h.Accepts(event) {
+			passEvents = append(passEvents, event)
+		}
+	}
+	if len(passEvents) == 0 {
+		return nil, nil
+	}
+	return h.handler.HandleEvents(context.Background(), passEvents)
+}
+
+// Filter passes the events to FilterEvents of an interceptor plugin. It returns one result per event, events which
+// are not accepted by the events filter are left unchanged without calling the plugin.
+func (h *Hub) Filter(events ...*types.Event) ([]*plugins.FilterResult, error) {
+	if h.Configuration.Kind != plugins.KindInterceptor {
+		return nil, fmt.Errorf("the plugin is not an interceptor")
+	}
+	results := plugins.PassAll(events)
+	passEvents := make([]*types.Event, 0, len(events))
+	indices := make([]int, 0, len(events))
+	for i, event := range events {
+		if h.Accepts(event) {
+			passEvents = append(passEvents, event)
+			indices = append(indices, i)
+		}
+	}
+	if len(passEvents) == 0 {
+		return results, nil
+	}
+	res, err := h.handler.FilterEvents(context.Background(), passEvents)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != len(passEvents) {
+		return nil, fmt.Errorf("got %d filter results for %d events", len(res), len(passEvents))
+	}
+	for i, result := range res {
+		results[indices[i]] = result
+	}
+	return results, nil
+}
+
+// Cron calls Cron of the plugin.
+func (h *Hub) Cron() ([]*types.Event, error) {
+	return h.handler.Cron(context.Background(), h.Room)
+}
+
+// ChatEvent creates a chat event sent by the user, like the server does for a chat message not starting with "/".
+func (h *Hub) ChatEvent(user *types.User, message string) *types.Event {
+	source := &types.Source{User: user}
+	tags := map[string]string{
+		"message":   message,
+		"mime_type": "text/plain",
+	}
+	event := types.NewEvent(h.Room, source, "", user.Language, types.EventTypeChat, tags)
+	return event
+}
+
+// command returns the specification of the command of the event if it is registered by the plugin.
+func (h *Hub) command(event *types.Event) (plugins.CommandSpec, bool) {
+	if event.Name != types.EventTypeCommand {
+		return plugins.CommandSpec{}, false
+	}
+	for _, spec := range h.Configuration.Commands {
+		if spec.Name == event.Tags["command"] {
+			return spec, true
+		}
+	}
+	return plugins.CommandSpec{}, false
+}
+
+// CommandEvent creates a command event sent by the user, like the server does for a chat message starting with "/".
+// If the command is registered by the plugin, the arguments are parsed into the tags "arg.<name>"; invalid
+// arguments are left out, as the server would not pass the command to the plugin at all.
+func (h *Hub) CommandEvent(user *types.User, message string) *types.Event {
+	source := &types.Source{User: user}
+	fields := strings.Fields(message)
+	command := ""
+	args := ""
+	if len(fields) > 0 {
+		command = fields[0]
+	}
+	if len(fields) > 1 {
+		args = strings.Join(fields[1:], " ")
+	}
+	tags := map[string]string{
+		"message":                message,
+		"mime_type":              "text/plain",
+		"original_target_filter": "",
+		"command":                command,
+		"args":                   args,
+	}
+	targetFilter := fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(user.Id))
+	event := types.NewEvent(h.Room, source, targetFilter, user.Language, types.EventTypeCommand, tags)
+	if spec, ok := h.command(event); ok {
+		if parsed, err := spec.ParseArgs(strings.TrimSpace(message)[len(command):]); err == nil {
+			for name, value := range parsed {
+				event.Tags["arg."+name] = value
+			}
+		}
+	}
+	return event
+}
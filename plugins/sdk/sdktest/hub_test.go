@@ -0,0 +1,70 @@
+package sdktest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/plugins/sdk"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+type interceptor struct {
+	*sdk.Base
+}
+
+func (i *interceptor) FilterEvents(ctx context.Context, events []*types.Event) ([]*plugins.FilterResult, error) {
+	results := make([]*plugins.FilterResult, len(events))
+	for n := range events {
+		results[n] = plugins.Rejected("no")
+	}
+	return results, nil
+}
+
+func TestInterceptor(t *testing.T) {
+	i := &interceptor{Base: sdk.NewBase("interceptor")}
+	i.Kind = plugins.KindInterceptor
+	i.EventsFilter = `Tags["message"] == "bad"`
+	hub, err := NewHub(i, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, hub.Connect())
+	defer hub.Close()
+
+	user := &types.User{Id: "u1", Nick: "u1"}
+	res, err := hub.Filter(hub.ChatEvent(user, "good"), hub.ChatEvent(user, "bad"))
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, plugins.FilterActionUnchanged, res[0].Action)
+		assert.Equal(t, plugins.FilterActionRejected, res[1].Action)
+	}
+	_, err = hub.Send(hub.ChatEvent(user, "bad"))
+	assert.Error(t, err)
+}
+
+func TestHelper(t *testing.T) {
+	base := sdk.NewBase("helper")
+	hub, err := NewHub(base, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, hub.Connect())
+	hub.Helper.Languages[hub.Room.Id] = []string{"en", "de"}
+
+	helper := base.Helper(hub.Room.Id)
+	if assert.NotNil(t, helper) {
+		languages, err := helper.GetRoomLanguages(context.Background(), hub.Room.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"de", "en"}, languages)
+
+		user, ok, err := helper.ChangeUserTags(context.Background(), "", "u1", []*types.TagUpdate{{Name: "x", Type: types.TagValueTypeInt, Expression: "1+1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{true}, ok)
+		assert.Equal(t, "2", user.Tags["x"])
+
+		event := hub.ChatEvent(&types.User{Id: "u1"}, "hi")
+		assert.NoError(t, helper.EmitEvents(context.Background(), []*types.Event{event}))
+		assert.Equal(t, []*types.Event{event}, hub.Helper.Emitted())
+	}
+
+	hub.Close()
+	assert.Nil(t, base.Helper(hub.Room.Id))
+}
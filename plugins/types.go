@@ -1,8 +1,221 @@
 package plugins
 
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	// DefaultTimeout is the deadline for a single call to a plugin if the plugin configuration does not provide one.
+	DefaultTimeout = 5 * time.Second
+	// DefaultQueueSize is the number of event batches that may be queued per plugin and hub.
+	DefaultQueueSize = 100
+	// DefaultConcurrency is the number of concurrent calls to a plugin per hub.
+	DefaultConcurrency = 1
+
+	// OverflowPolicyDrop drops events for a plugin if its queue is full.
+	OverflowPolicyDrop = "drop"
+	// OverflowPolicyBlock waits for room in the queue (for at most the plugin timeout) before dropping events.
+	OverflowPolicyBlock = "block"
+)
+
 type PluginSpec struct {
-	Name        string
-	Plugin      EventHandler
-	CronSpec    string
-	EventFilter string
+	Name           string
+	Plugin         EventHandler
+	CronSpec       string
+	EventFilter    string
+	Priority       int           // plugins with higher priority are called first
+	Kind           int           // KindObserver or KindInterceptor
+	Timeout        time.Duration // deadline for each call to the plugin, DefaultTimeout if not set
+	QueueSize      int           // size of the event queue, DefaultQueueSize if not set
+	Concurrency    int           // number of concurrent calls, DefaultConcurrency if not set
+	OverflowPolicy string        // OverflowPolicyDrop (default) or OverflowPolicyBlock
+	Commands       []CommandSpec // the commands registered by the plugin
+}
+
+// NewPluginSpec configures the plugin and returns its spec. cfg is the configuration block of the plugin, nil if there
+// is none: the plugin is configured with an empty configuration then, so that it registers its commands, kind and
+// priority, but it receives all events and has no cron job.
+func NewPluginSpec(ctx context.Context, name string, plugin EventHandler, cfg *config.PluginConfig) (PluginSpec, error) {
+	spec := PluginSpec{
+		Name:   name,
+		Plugin: plugin,
+	}
+	rawConfig := make(map[string]interface{})
+	if cfg != nil {
+		spec.Timeout = cfg.Timeout
+		spec.QueueSize = cfg.QueueSize
+		spec.Concurrency = cfg.Concurrency
+		spec.OverflowPolicy = cfg.OverflowPolicy
+		switch spec.OverflowPolicy {
+		case "", OverflowPolicyDrop, OverflowPolicyBlock:
+		default:
+			return PluginSpec{}, fmt.Errorf("invalid overflow policy for plugin %s: %s", name, spec.OverflowPolicy)
+		}
+		if cfg.RawPluginConfig != nil {
+			rawConfig = cfg.RawPluginConfig
+		}
+	}
+	configuration, err := spec.Configure(ctx, rawConfig)
+	if err != nil {
+		return PluginSpec{}, fmt.Errorf("could not configure plugin %s: %w", name, err)
+	}
+	for _, command := range configuration.Commands {
+		if err := command.Validate(); err != nil {
+			return PluginSpec{}, fmt.Errorf("invalid command of plugin %s: %w", name, err)
+		}
+	}
+	spec.Priority = configuration.Priority
+	spec.Kind = configuration.Kind
+	spec.Commands = configuration.Commands
+	if cfg != nil {
+		spec.CronSpec = configuration.CronSpec
+		spec.EventFilter = configuration.EventsFilter
+	}
+	return spec, nil
+}
+
+// GetTimeout returns the deadline for each call to the plugin.
+func (p PluginSpec) GetTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return DefaultTimeout
+}
+
+// GetQueueSize returns the size of the plugin's event queue.
+func (p PluginSpec) GetQueueSize() int {
+	if p.QueueSize > 0 {
+		return p.QueueSize
+	}
+	return DefaultQueueSize
+}
+
+// GetConcurrency returns the number of concurrent calls to the plugin.
+func (p PluginSpec) GetConcurrency() int {
+	if p.Concurrency > 0 {
+		return p.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+// call runs f with a context that is cancelled after the configured timeout. If f does not return in time, call
+// returns the context error right away and whatever f returns later is discarded.
+func (p PluginSpec) call(ctx context.Context, method string, f func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, p.GetTimeout())
+	defer cancel()
+	key := p.Name + "." + method
+	pluginCalls.Add(key, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- f(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				pluginTimeouts.Add(key, 1)
+				globals.AppLogger.Warn("plugin call timed out", "plugin", p.Name, "method", method, "timeout", p.GetTimeout())
+			} else {
+				pluginErrors.Add(key, 1)
+			}
+		}
+		return err
+
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			pluginTimeouts.Add(key, 1)
+			globals.AppLogger.Warn("plugin call timed out, discarding late result", "plugin", p.Name, "method", method, "timeout", p.GetTimeout())
+		} else {
+			pluginErrors.Add(key, 1)
+		}
+		return ctx.Err()
+	}
+}
+
+// Configure calls Configure on the plugin, observing the configured timeout.
+func (p PluginSpec) Configure(ctx context.Context, config map[string]interface{}) (Configuration, error) {
+	var cfg Configuration
+	err := p.call(ctx, "Configure", func(ctx context.Context) error {
+		var err error
+		cfg, err = p.Plugin.Configure(ctx, config)
+		return err
+	})
+	if err != nil {
+		return Configuration{}, err
+	}
+	return cfg, nil
+}
+
+// Cron calls Cron on the plugin, observing the configured timeout.
+func (p PluginSpec) Cron(ctx context.Context, room *types.Room) ([]*types.Event, error) {
+	var events []*types.Event
+	err := p.call(ctx, "Cron", func(ctx context.Context) error {
+		var err error
+		events, err = p.Plugin.Cron(ctx, room)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// HandleEvents calls HandleEvents on the plugin, observing the configured timeout.
+func (p PluginSpec) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	var resEvents []*types.Event
+	err := p.call(ctx, "HandleEvents", func(ctx context.Context) error {
+		var err error
+		resEvents, err = p.Plugin.HandleEvents(ctx, events)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resEvents, nil
+}
+
+// FilterEvents calls FilterEvents on the plugin, observing the configured timeout. It makes sure that there is
+// exactly one result per event.
+func (p PluginSpec) FilterEvents(ctx context.Context, events []*types.Event) ([]*FilterResult, error) {
+	var results []*FilterResult
+	err := p.call(ctx, "FilterEvents", func(ctx context.Context) error {
+		var err error
+		results, err = p.Plugin.FilterEvents(ctx, events)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(events) {
+		return nil, fmt.Errorf("plugin %s returned %d filter results for %d events", p.Name, len(results), len(events))
+	}
+	for _, result := range results {
+		if result == nil || (result.Action == FilterActionModified && result.Event == nil) {
+			return nil, fmt.Errorf("plugin %s returned an invalid filter result", p.Name)
+		}
+	}
+	return results, nil
+}
+
+// SortPlugins returns the names of the plugins ordered by descending priority (and name, for equal priorities).
+func SortPlugins(pluginMap map[string]PluginSpec) []string {
+	names := make([]string, 0, len(pluginMap))
+	for name := range pluginMap {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := pluginMap[names[i]].Priority, pluginMap[names[j]].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+	return names
 }
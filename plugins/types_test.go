@@ -0,0 +1,187 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// blockingPlugin answers HandleEvents only after release is closed, ignoring the deadline of the call. returned is
+// closed once it has answered.
+type blockingPlugin struct {
+	EventHandler
+	release  chan struct{}
+	returned chan struct{}
+}
+
+func (b *blockingPlugin) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	defer close(b.returned)
+	<-b.release
+	return events, nil
+}
+
+// errorPlugin answers HandleEvents with err, after waiting for the deadline if wait is set.
+type errorPlugin struct {
+	EventHandler
+	err  error
+	wait bool
+}
+
+func (e *errorPlugin) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	if e.wait {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return events, nil
+}
+
+// callCounts are the calls, errors and timeouts counted for a plugin method.
+type callCounts struct {
+	calls, errors, timeouts int64
+}
+
+// countCalls returns a function returning the calls, errors and timeouts counted for the key since countCalls was
+// called (the counters are shared by all tests).
+func countCalls(key string) func() callCounts {
+	get := func(m *expvar.Map) int64 {
+		if v, ok := m.Get(key).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	start := callCounts{get(pluginCalls), get(pluginErrors), get(pluginTimeouts)}
+	return func() callCounts {
+		return callCounts{get(pluginCalls) - start.calls, get(pluginErrors) - start.errors, get(pluginTimeouts) - start.timeouts}
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	plugin := &blockingPlugin{release: make(chan struct{}), returned: make(chan struct{})}
+	spec := PluginSpec{Name: "blocking", Plugin: plugin, Timeout: 20 * time.Millisecond}
+	events := []*types.Event{types.NewEvent(&types.Room{Id: "room"}, nil, "", "en", types.EventTypeChat, nil)}
+	counts := countCalls("blocking.HandleEvents")
+
+	start := time.Now()
+	res, err := spec.HandleEvents(context.Background(), events)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, res)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second), "the call returns at the deadline, not with the plugin")
+	assert.Equal(t, callCounts{calls: 1, timeouts: 1}, counts())
+
+	// the late reply is discarded
+	close(plugin.release)
+	<-plugin.returned
+	assert.Equal(t, callCounts{calls: 1, timeouts: 1}, counts(), "the late reply is not counted again")
+}
+
+func TestCallMetrics(t *testing.T) {
+	events := []*types.Event{types.NewEvent(&types.Room{Id: "room"}, nil, "", "en", types.EventTypeChat, nil)}
+
+	counts := countCalls("ok.HandleEvents")
+	spec := PluginSpec{Name: "ok", Plugin: &errorPlugin{}}
+	res, err := spec.HandleEvents(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, events, res)
+	assert.Equal(t, callCounts{calls: 1}, counts())
+
+	counts = countCalls("failing.HandleEvents")
+	failed := errors.New("failed")
+	spec = PluginSpec{Name: "failing", Plugin: &errorPlugin{err: failed}}
+	_, err = spec.HandleEvents(context.Background(), events)
+	assert.Equal(t, failed, err)
+	assert.Equal(t, callCounts{calls: 1, errors: 1}, counts())
+
+	// a plugin returning the context error at the deadline counts as a timeout
+	counts = countCalls("waiting.HandleEvents")
+	spec = PluginSpec{Name: "waiting", Plugin: &errorPlugin{wait: true}, Timeout: 10 * time.Millisecond}
+	_, err = spec.HandleEvents(context.Background(), events)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, callCounts{calls: 1, timeouts: 1}, counts())
+
+	// a cancelled call is an error, not a timeout
+	counts = countCalls("cancelled.HandleEvents")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	spec = PluginSpec{Name: "cancelled", Plugin: &errorPlugin{wait: true}}
+	_, err = spec.HandleEvents(ctx, events)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, callCounts{calls: 1, errors: 1}, counts())
+}
+
+func TestSortPlugins(t *testing.T) {
+	pluginMap := map[string]PluginSpec{
+		"translate": {Name: "translate"},
+		"automod":   {Name: "automod", Priority: 100},
+		"commands":  {Name: "commands", Priority: 10},
+		"archive":   {Name: "archive"},
+		"spam":      {Name: "spam", Priority: 100},
+		"late":      {Name: "late", Priority: -1},
+	}
+	assert.Equal(t, []string{"automod", "spam", "commands", "archive", "translate", "late"}, SortPlugins(pluginMap),
+		"higher priorities first, equal priorities by name")
+	assert.Empty(t, SortPlugins(nil))
+}
+
+// configurablePlugin returns a fixed configuration.
+type configurablePlugin struct {
+	EventHandler
+	configuration Configuration
+	config        map[string]interface{}
+}
+
+func (c *configurablePlugin) Configure(ctx context.Context, config map[string]interface{}) (Configuration, error) {
+	c.config = config
+	return c.configuration, nil
+}
+
+func TestNewPluginSpec(t *testing.T) {
+	plugin := &configurablePlugin{configuration: Configuration{
+		CronSpec:     "@every 1m",
+		EventsFilter: `Name == "chat"`,
+		Priority:     10,
+		Kind:         KindInterceptor,
+		Commands:     []CommandSpec{{Name: "/test"}},
+	}}
+	spec, err := NewPluginSpec(context.Background(), "test", plugin, &config.PluginConfig{
+		Name:            "test",
+		Timeout:         time.Second,
+		OverflowPolicy:  OverflowPolicyBlock,
+		RawPluginConfig: map[string]interface{}{"key": "value"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"key": "value"}, plugin.config)
+		assert.Equal(t, "@every 1m", spec.CronSpec)
+		assert.Equal(t, `Name == "chat"`, spec.EventFilter)
+		assert.Equal(t, 10, spec.Priority)
+		assert.Equal(t, KindInterceptor, spec.Kind)
+		assert.Equal(t, time.Second, spec.Timeout)
+		assert.Equal(t, OverflowPolicyBlock, spec.OverflowPolicy)
+		assert.Len(t, spec.Commands, 1)
+	}
+
+	// a plugin without configuration block is configured with an empty configuration
+	spec, err = NewPluginSpec(context.Background(), "test", plugin, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{}, plugin.config)
+		assert.Len(t, spec.Commands, 1, "the commands are registered")
+		assert.Equal(t, 10, spec.Priority)
+		assert.Equal(t, KindInterceptor, spec.Kind)
+		assert.Equal(t, "", spec.EventFilter, "the plugin receives all events")
+		assert.Equal(t, "", spec.CronSpec)
+	}
+
+	_, err = NewPluginSpec(context.Background(), "test", plugin, &config.PluginConfig{OverflowPolicy: "wait"})
+	assert.Error(t, err)
+	plugin.configuration.Commands = []CommandSpec{{Name: "test"}}
+	_, err = NewPluginSpec(context.Background(), "test", plugin, nil)
+	assert.Error(t, err, "invalid command")
+}
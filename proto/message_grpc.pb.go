@@ -21,6 +21,7 @@ type EventHandlerClient interface {
 	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ConfigureResponse, error)
 	Cron(ctx context.Context, in *CronRequest, opts ...grpc.CallOption) (*CronResponse, error)
 	HandleEvents(ctx context.Context, in *HandleEventsRequest, opts ...grpc.CallOption) (*HandleEventsResponse, error)
+	FilterEvents(ctx context.Context, in *FilterEventsRequest, opts ...grpc.CallOption) (*FilterEventsResponse, error)
 	InitEmitEvents(ctx context.Context, in *InitEmitEventsRequest, opts ...grpc.CallOption) (*InitEmitEventsResponse, error)
 }
 
@@ -59,6 +60,15 @@ func (c *eventHandlerClient) HandleEvents(ctx context.Context, in *HandleEventsR
 	return out, nil
 }
 
+func (c *eventHandlerClient) FilterEvents(ctx context.Context, in *FilterEventsRequest, opts ...grpc.CallOption) (*FilterEventsResponse, error) {
+	out := new(FilterEventsResponse)
+	err := c.cc.Invoke(ctx, "/proto.EventHandler/FilterEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eventHandlerClient) InitEmitEvents(ctx context.Context, in *InitEmitEventsRequest, opts ...grpc.CallOption) (*InitEmitEventsResponse, error) {
 	out := new(InitEmitEventsResponse)
 	err := c.cc.Invoke(ctx, "/proto.EventHandler/InitEmitEvents", in, out, opts...)
@@ -75,6 +85,7 @@ type EventHandlerServer interface {
 	Configure(context.Context, *ConfigureRequest) (*ConfigureResponse, error)
 	Cron(context.Context, *CronRequest) (*CronResponse, error)
 	HandleEvents(context.Context, *HandleEventsRequest) (*HandleEventsResponse, error)
+	FilterEvents(context.Context, *FilterEventsRequest) (*FilterEventsResponse, error)
 	InitEmitEvents(context.Context, *InitEmitEventsRequest) (*InitEmitEventsResponse, error)
 	mustEmbedUnimplementedEventHandlerServer()
 }
@@ -92,6 +103,9 @@ func (UnimplementedEventHandlerServer) Cron(context.Context, *CronRequest) (*Cro
 func (UnimplementedEventHandlerServer) HandleEvents(context.Context, *HandleEventsRequest) (*HandleEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleEvents not implemented")
 }
+func (UnimplementedEventHandlerServer) FilterEvents(context.Context, *FilterEventsRequest) (*FilterEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FilterEvents not implemented")
+}
 func (UnimplementedEventHandlerServer) InitEmitEvents(context.Context, *InitEmitEventsRequest) (*InitEmitEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitEmitEvents not implemented")
 }
@@ -162,6 +176,24 @@ func _EventHandler_HandleEvents_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventHandler_FilterEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventHandlerServer).FilterEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.EventHandler/FilterEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventHandlerServer).FilterEvents(ctx, req.(*FilterEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EventHandler_InitEmitEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InitEmitEventsRequest)
 	if err := dec(in); err != nil {
@@ -199,6 +231,10 @@ var EventHandler_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HandleEvents",
 			Handler:    _EventHandler_HandleEvents_Handler,
 		},
+		{
+			MethodName: "FilterEvents",
+			Handler:    _EventHandler_FilterEvents_Handler,
+		},
 		{
 			MethodName: "InitEmitEvents",
 			Handler:    _EventHandler_InitEmitEvents_Handler,
@@ -218,6 +254,9 @@ type EmitEventsHelperClient interface {
 	ChangeUserTags(ctx context.Context, in *ChangeUserTagsRequest, opts ...grpc.CallOption) (*ChangeUserTagsResponse, error)
 	GetRoom(ctx context.Context, in *GetRoomRequest, opts ...grpc.CallOption) (*GetRoomResponse, error)
 	ChangeRoomTags(ctx context.Context, in *ChangeRoomTagsRequest, opts ...grpc.CallOption) (*ChangeRoomTagsResponse, error)
+	GetRoomLanguages(ctx context.Context, in *GetRoomLanguagesRequest, opts ...grpc.CallOption) (*GetRoomLanguagesResponse, error)
+	GetUserRole(ctx context.Context, in *GetUserRoleRequest, opts ...grpc.CallOption) (*GetUserRoleResponse, error)
+	SetUserRole(ctx context.Context, in *SetUserRoleRequest, opts ...grpc.CallOption) (*SetUserRoleResponse, error)
 }
 
 type emitEventsHelperClient struct {
@@ -282,6 +321,33 @@ func (c *emitEventsHelperClient) ChangeRoomTags(ctx context.Context, in *ChangeR
 	return out, nil
 }
 
+func (c *emitEventsHelperClient) GetRoomLanguages(ctx context.Context, in *GetRoomLanguagesRequest, opts ...grpc.CallOption) (*GetRoomLanguagesResponse, error) {
+	out := new(GetRoomLanguagesResponse)
+	err := c.cc.Invoke(ctx, "/proto.EmitEventsHelper/GetRoomLanguages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emitEventsHelperClient) GetUserRole(ctx context.Context, in *GetUserRoleRequest, opts ...grpc.CallOption) (*GetUserRoleResponse, error) {
+	out := new(GetUserRoleResponse)
+	err := c.cc.Invoke(ctx, "/proto.EmitEventsHelper/GetUserRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emitEventsHelperClient) SetUserRole(ctx context.Context, in *SetUserRoleRequest, opts ...grpc.CallOption) (*SetUserRoleResponse, error) {
+	out := new(SetUserRoleResponse)
+	err := c.cc.Invoke(ctx, "/proto.EmitEventsHelper/SetUserRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EmitEventsHelperServer is the server API for EmitEventsHelper service.
 // All implementations must embed UnimplementedEmitEventsHelperServer
 // for forward compatibility
@@ -292,6 +358,9 @@ type EmitEventsHelperServer interface {
 	ChangeUserTags(context.Context, *ChangeUserTagsRequest) (*ChangeUserTagsResponse, error)
 	GetRoom(context.Context, *GetRoomRequest) (*GetRoomResponse, error)
 	ChangeRoomTags(context.Context, *ChangeRoomTagsRequest) (*ChangeRoomTagsResponse, error)
+	GetRoomLanguages(context.Context, *GetRoomLanguagesRequest) (*GetRoomLanguagesResponse, error)
+	GetUserRole(context.Context, *GetUserRoleRequest) (*GetUserRoleResponse, error)
+	SetUserRole(context.Context, *SetUserRoleRequest) (*SetUserRoleResponse, error)
 	mustEmbedUnimplementedEmitEventsHelperServer()
 }
 
@@ -317,6 +386,15 @@ func (UnimplementedEmitEventsHelperServer) GetRoom(context.Context, *GetRoomRequ
 func (UnimplementedEmitEventsHelperServer) ChangeRoomTags(context.Context, *ChangeRoomTagsRequest) (*ChangeRoomTagsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ChangeRoomTags not implemented")
 }
+func (UnimplementedEmitEventsHelperServer) GetRoomLanguages(context.Context, *GetRoomLanguagesRequest) (*GetRoomLanguagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoomLanguages not implemented")
+}
+func (UnimplementedEmitEventsHelperServer) GetUserRole(context.Context, *GetUserRoleRequest) (*GetUserRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserRole not implemented")
+}
+func (UnimplementedEmitEventsHelperServer) SetUserRole(context.Context, *SetUserRoleRequest) (*SetUserRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserRole not implemented")
+}
 func (UnimplementedEmitEventsHelperServer) mustEmbedUnimplementedEmitEventsHelperServer() {}
 
 // UnsafeEmitEventsHelperServer may be embedded to opt out of forward compatibility for this service.
@@ -438,6 +516,60 @@ func _EmitEventsHelper_ChangeRoomTags_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmitEventsHelper_GetRoomLanguages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRoomLanguagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmitEventsHelperServer).GetRoomLanguages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.EmitEventsHelper/GetRoomLanguages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmitEventsHelperServer).GetRoomLanguages(ctx, req.(*GetRoomLanguagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmitEventsHelper_GetUserRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmitEventsHelperServer).GetUserRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.EmitEventsHelper/GetUserRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmitEventsHelperServer).GetUserRole(ctx, req.(*GetUserRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmitEventsHelper_SetUserRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmitEventsHelperServer).SetUserRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.EmitEventsHelper/SetUserRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmitEventsHelperServer).SetUserRole(ctx, req.(*SetUserRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EmitEventsHelper_ServiceDesc is the grpc.ServiceDesc for EmitEventsHelper service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -469,6 +601,18 @@ var EmitEventsHelper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ChangeRoomTags",
 			Handler:    _EmitEventsHelper_ChangeRoomTags_Handler,
 		},
+		{
+			MethodName: "GetRoomLanguages",
+			Handler:    _EmitEventsHelper_GetRoomLanguages_Handler,
+		},
+		{
+			MethodName: "GetUserRole",
+			Handler:    _EmitEventsHelper_GetUserRole_Handler,
+		},
+		{
+			MethodName: "SetUserRole",
+			Handler:    _EmitEventsHelper_SetUserRole_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/message.proto",
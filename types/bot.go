@@ -0,0 +1,33 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Bot makes a user a bot, which authenticates with a long-lived API key instead of a token. Only the hash of the key
+// is stored. The bot may send the events named in Events (comma separated, chat messages only if empty), at most
+// Messages per Interval, which replace the global limits (0: the global limits apply).
+type Bot struct {
+	UserId    string        `json:"user_id" gorm:"primaryKey"`
+	KeyHash   string        `json:"-"`
+	Events    string        `json:"events"`
+	Messages  int           `json:"messages"`
+	Interval  time.Duration `json:"interval"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"-"`
+}
+
+// AllowsEvent reports whether the bot may send events with the name. Commands are sent as chat messages, but they must
+// be allowed explicitly (EventTypeCommand).
+func (b *Bot) AllowsEvent(name string) bool {
+	if strings.TrimSpace(b.Events) == "" {
+		return name == EventTypeChat
+	}
+	for _, allowed := range strings.Split(b.Events, ",") {
+		if strings.TrimSpace(allowed) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,9 @@
 package types
 
 import (
-	"fmt"
-	"gorm.io/gorm"
 	"time"
 
-	"github.com/mitchellh/hashstructure/v2"
-	"github.com/tcriess/lightspeed-chat/globals"
+	"gorm.io/gorm"
 )
 
 const (
@@ -16,16 +13,22 @@ const (
 	EventTypeUser        = "user"
 	EventTypeTranslation = "translation"
 	EventTypeInternal    = "_internal"
+
+	// EventTypeTranslationRequest events are only sent to plugins, they ask for the translation of the message
+	// (source_id) into the language target_language, the answer is an EventTypeTranslation event.
+	EventTypeTranslationRequest = "translation_request"
 )
 
 type Source struct {
 	UserId     string `json:"-"`
 	User       *User  `json:"user"`
 	PluginName string `json:"plugin_name"`
+	Role       string `json:"role"`          // role of the user in the room when the event was sent (empty for plugin events)
+	Bot        bool   `json:"bot,omitempty"` // sent by a bot user (see Bot)
 }
 
 type Event struct {
-	Id       string `json:"id" hash:"ignore" gorm:"primaryKey"`
+	Id       string `json:"id" gorm:"primaryKey"`
 	RoomId   string `json:"-"`
 	Room     *Room  `json:"room"`
 	*Source  `json:"source" gorm:"embeddedPrefix:source_"`
@@ -33,10 +36,11 @@ type Event struct {
 	Language string        `json:"language"`
 	Name     string        `json:"name"`
 	Tags     JSONStringMap `json:"tags"`
-	History  bool          `json:"history" gorm:"-"` // set to true is this event is sent from history
+	History  bool          `json:"history" gorm:"-"`           // set to true is this event is sent from history
+	Seq      uint64        `json:"seq,omitempty" gorm:"index"` // sequence number within the room, 0 for events which are not stored
 
 	// the following fields are not part of the filter.Env!
-	Sent         time.Time `json:"sent" hash:"ignore"`
+	Sent         time.Time `json:"sent"`
 	TargetFilter string    `json:"target_filter"`
 
 	UpdatedAt time.Time      `json:"-"`
@@ -45,7 +49,7 @@ type Event struct {
 
 // NewEvent creates a new event with the given parameters.
 //
-// The resulting *Event has no `nil` values, the Created timestamp is set to now.
+// The resulting *Event has no `nil` values, the Created timestamp is set to now and the id is a new ULID.
 func NewEvent(room *Room, source *Source, targetFilter string, language string, name string, tags map[string]string) *Event {
 	if source == nil {
 		source = &Source{}
@@ -53,7 +57,10 @@ func NewEvent(room *Room, source *Source, targetFilter string, language string,
 	if source.User == nil {
 		source.User = &User{}
 	}
-	source.User.LastOnline = source.User.LastOnline.In(time.UTC)
+	// the user is shared by the events of a client, so it is only written if necessary
+	if source.User.LastOnline.Location() != time.UTC {
+		source.User.LastOnline = source.User.LastOnline.In(time.UTC)
+	}
 	if source.User.Tags == nil {
 		source.User.Tags = make(map[string]string)
 	}
@@ -72,20 +79,16 @@ func NewEvent(room *Room, source *Source, targetFilter string, language string,
 	if tags == nil {
 		tags = make(map[string]string)
 	}
+	now := time.Now().In(time.UTC)
 	evt := &Event{
+		Id:           NewULID(now),
 		Room:         room,
 		Source:       source,
-		Created:      time.Now().In(time.UTC),
+		Created:      now,
 		Language:     language,
 		Name:         name,
 		Tags:         tags,
 		TargetFilter: targetFilter,
 	}
-	hash, err := hashstructure.Hash(evt, hashstructure.FormatV2, nil)
-	if err != nil {
-		globals.AppLogger.Error("could not hash event", "error", err)
-	} else {
-		evt.Id = fmt.Sprintf("%016X", hash)
-	}
 	return evt
 }
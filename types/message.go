@@ -19,11 +19,20 @@ const (
 	WireMessageTypeUsers        = "users"
 	WireMessageTypeCommands     = "commands"
 	WireMessageTypeGenerics     = "generics"
+	WireMessageTypeSession      = "session"
+	WireMessageTypeGap          = "gap"
+	WireMessageTypeHello        = "hello" // protocol v1
+	WireMessageTypeError        = "error" // protocol v1
+	WireMessageTypeAck          = "ack"   // protocol v1
+	WireMessageTypeBatch        = "batch" // protocol v1
+	WireMessageTypeConnection   = "connection"
 )
 
-// JSON-serialized WebsocketMessage is what is actually sent via the Websocket connection
+// JSON-serialized WebsocketMessage is what is actually sent via the Websocket connection. Id is the request id of a
+// message sent by the client, which is returned in the ack or error message answering it (protocol v1).
 type WebsocketMessage struct {
 	Event string          `json:"event"`
+	Id    string          `json:"id,omitempty"`
 	Data  json.RawMessage `json:"data"`
 }
 
@@ -31,11 +40,11 @@ type WebsocketMessage struct {
 
 // ChatMessage is a basic chat message, contains the sender nick
 type ChatMessage struct {
-	Nick      string    `json:"nick" mapstructure:"-"`                   // sender nick, outgoing
-	Timestamp time.Time `json:"timestamp" mapstructure:"-"`              // sent time, outgoing
-	Message   string    `json:"message" mapstructure:"message"`          // actual message, incoming + outgoing
-	Language  string    `json:"language" hash:"ignore" mapstructure:"-"` // language of the message (for future use), outgoing
-	Filter    string    `json:"filter" mapstructure:"filter"`            // filter expression incoming
+	Nick      string    `json:"nick" mapstructure:"-"`            // sender nick, outgoing
+	Timestamp time.Time `json:"timestamp" mapstructure:"-"`       // sent time, outgoing
+	Message   string    `json:"message" mapstructure:"message"`   // actual message, incoming + outgoing
+	Language  string    `json:"language" mapstructure:"language"` // language of the message, incoming (optional) + outgoing
+	Filter    string    `json:"filter" mapstructure:"filter"`     // filter expression incoming
 }
 
 // LoginMessage is sent when a client logs in and contains the id token, the provider and the user's language setting
@@ -44,3 +53,45 @@ type LoginMessage struct {
 	Provider string `json:"provider" mapstructure:"provider"`
 	Language string `json:"language" mapstructure:"language"`
 }
+
+// SessionMessage is sent to the client when it connects, logs in or out and contains the token to resume the session
+// when reconnecting
+type SessionMessage struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// GapMessage is sent to a reconnecting client if the events it missed since last_seq cannot be replayed, the client
+// receives the history instead. seq is the sequence number of the last event of the room.
+type GapMessage struct {
+	LastSeq uint64 `json:"last_seq"`
+	Seq     uint64 `json:"seq"`
+}
+
+// HelloMessage negotiates the protocol version and the encoding: the client sends the versions it supports and the
+// encoding it wants to use, the server answers with the chosen version and encoding and the ones it supports
+type HelloMessage struct {
+	Version   int      `json:"version" mapstructure:"version"`
+	Versions  []int    `json:"versions" mapstructure:"versions"`
+	Encoding  string   `json:"encoding,omitempty" mapstructure:"encoding"`
+	Encodings []string `json:"encodings,omitempty" mapstructure:"encodings"`
+}
+
+// ErrorMessage is sent to the client if a message cannot be processed (protocol v1)
+type ErrorMessage struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AckMessage acknowledges a message sent by the client with a request id (protocol v1). For events, it contains the
+// id and sequence number of the resulting event.
+type AckMessage struct {
+	EventId string `json:"event_id,omitempty"`
+	Seq     uint64 `json:"seq,omitempty"`
+}
+
+// ConnectionMessage is sent to a client connected via Server-Sent Events and contains the id of the connection, which
+// is used to post messages via HTTP
+type ConnectionMessage struct {
+	Id string `json:"id"`
+}
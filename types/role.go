@@ -0,0 +1,115 @@
+package types
+
+import (
+	"strconv"
+	"time"
+)
+
+// roles of a user in a room, the owner of the room always has RoleOwner, users who are not logged in have RoleGuest
+const (
+	RoleOwner     = "owner"
+	RoleModerator = "moderator"
+	RoleMember    = "member" // default role of authenticated users
+	RoleGuest     = "guest"
+	RoleBanned    = "banned"
+)
+
+// permissions which are granted to roles
+const (
+	PermissionRead          = "read"           // receive events
+	PermissionPost          = "post"           // send chat messages and events
+	PermissionPostLinks     = "post_links"     // send chat messages containing links
+	PermissionCommands      = "commands"       // use commands
+	PermissionModerate      = "moderate"       // change the roles of other users (except owner) and use moderation commands
+	PermissionManagePlugins = "manage_plugins" // change room tags via plugins
+)
+
+// RoomPermissionTagPrefix is the prefix of the room tags overriding the default permissions, f.e. the room tag
+// "_permission.guest.post" = "true" allows guests to post.
+const RoomPermissionTagPrefix = "_permission."
+
+// RoomAllowGuestsTag is the room tag which used to allow guests to post (it is equivalent to
+// "_permission.guest.post").
+const RoomAllowGuestsTag = "_allow_guests"
+
+// Roles contains all roles, ordered from most to least privileged.
+var Roles = []string{RoleOwner, RoleModerator, RoleMember, RoleGuest, RoleBanned}
+
+// Permissions contains all permissions.
+var Permissions = []string{PermissionRead, PermissionPost, PermissionPostLinks, PermissionCommands, PermissionModerate, PermissionManagePlugins}
+
+// DefaultPermissions is the permission matrix used if the room does not override it.
+var DefaultPermissions = map[string]map[string]bool{
+	RoleOwner: {
+		PermissionRead:          true,
+		PermissionPost:          true,
+		PermissionPostLinks:     true,
+		PermissionCommands:      true,
+		PermissionModerate:      true,
+		PermissionManagePlugins: true,
+	},
+	RoleModerator: {
+		PermissionRead:      true,
+		PermissionPost:      true,
+		PermissionPostLinks: true,
+		PermissionCommands:  true,
+		PermissionModerate:  true,
+	},
+	RoleMember: {
+		PermissionRead:      true,
+		PermissionPost:      true,
+		PermissionPostLinks: true,
+		PermissionCommands:  true,
+	},
+	RoleGuest: {
+		PermissionRead: true,
+	},
+	RoleBanned: {},
+}
+
+// Membership is the role of a user in a room.
+type Membership struct {
+	RoomId    string    `json:"room_id" gorm:"primaryKey"`
+	UserId    string    `json:"user_id" gorm:"primaryKey"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// PermissionTag returns the name of the room tag overriding the permission of the role.
+func PermissionTag(role, permission string) string {
+	return RoomPermissionTagPrefix + role + "." + permission
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the role has the permission in the room. The room tag "_permission.<role>.<permission>"
+// (a boolean) overrides DefaultPermissions. The owner always has all permissions.
+func (r *Room) Allows(role, permission string) bool {
+	if role == RoleOwner {
+		return true
+	}
+	if r != nil && r.Tags != nil {
+		if v, ok := r.Tags[PermissionTag(role, permission)]; ok {
+			if allowed, err := strconv.ParseBool(v); err == nil {
+				return allowed
+			}
+		}
+		if role == RoleGuest && permission == PermissionPost {
+			if v, ok := r.Tags[RoomAllowGuestsTag]; ok {
+				if allowed, err := strconv.ParseBool(v); err == nil {
+					return allowed
+				}
+			}
+		}
+	}
+	return DefaultPermissions[role][permission]
+}
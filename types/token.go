@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// RevokedToken is an entry of the deny list of locally signed tokens. The entry can be removed once the token has
+// expired (ExpiresAt), a zero ExpiresAt keeps the entry forever.
+type RevokedToken struct {
+	Id        string    `json:"id" gorm:"primaryKey"` // the jti claim of the token, or "sha256:<hash of the token>"
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
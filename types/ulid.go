@@ -0,0 +1,65 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/globals"
+)
+
+// ulidAlphabet is Crockford's base32 alphabet.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidState struct {
+	sync.Mutex
+	ms     uint64
+	random [10]byte
+}
+
+// NewULID returns a new ULID (see https://github.com/ulid/spec) for the time t: 48 bits of Unix milliseconds followed
+// by 80 random bits, encoded as 26 characters. ULIDs generated within the same millisecond (or for an earlier time)
+// increment the random part of the previous one, so the ULIDs of a process are strictly increasing.
+func NewULID(t time.Time) string {
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	ulidState.Lock()
+	if ms > ulidState.ms {
+		ulidState.ms = ms
+		if _, err := rand.Read(ulidState.random[:]); err != nil {
+			globals.AppLogger.Error("could not generate random ulid", "error", err)
+		}
+	} else {
+		incrementULIDRandom()
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], ulidState.ms<<16)
+	copy(b[6:], ulidState.random[:])
+	ulidState.Unlock()
+	return encodeULID(b)
+}
+
+// incrementULIDRandom increments the random part of the last ULID, on overflow the time part is incremented.
+func incrementULIDRandom() {
+	for i := len(ulidState.random) - 1; i >= 0; i-- {
+		ulidState.random[i]++
+		if ulidState.random[i] != 0 {
+			return
+		}
+	}
+	ulidState.ms++
+}
+
+// encodeULID encodes the 128 bits of the ULID in base32, 5 bits per character starting with the least significant
+// bits (the first character holds the 3 most significant bits).
+func encodeULID(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"encoding/binary"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewULID(t *testing.T) {
+	// example of the specification: the time part of 1469918176385 ms (NewULID does not go back to that time once it
+	// has been called)
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], 1469918176385<<16)
+	assert.Equal(t, "01ARYZ6S41", encodeULID(b)[:10])
+
+	now := time.Now()
+	ids := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		id := NewULID(now)
+		assert.Len(t, id, 26)
+		ids = append(ids, id)
+	}
+	assert.True(t, sort.StringsAreSorted(ids), "ulids of the same millisecond are monotonic")
+	assert.Less(t, ids[len(ids)-1], NewULID(now.Add(-time.Hour)), "ulids never go back in time")
+	assert.Less(t, ids[len(ids)-1], NewULID(now.Add(time.Millisecond)))
+}
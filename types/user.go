@@ -6,11 +6,12 @@ import (
 )
 
 type User struct {
-	Id         string         `json:"id" gorm:"primaryKey"`      // unique id
-	Nick       string         `json:"nick" gorm:"index"`         // should also be unique
-	Language   string         `json:"language"`                  // alpha-2 iso
-	Tags       JSONStringMap  `json:"tags"`                      // tags
-	LastOnline time.Time      `json:"last_online" hash:"ignore"` // last seen online
+	Id         string         `json:"id" gorm:"primaryKey"`        // unique id
+	Nick       string         `json:"nick" gorm:"index"`           // should also be unique
+	Language   string         `json:"language"`                    // alpha-2 iso
+	Tags       JSONStringMap  `json:"tags"`                        // tags
+	LastOnline time.Time      `json:"last_online"`                 // last seen online
+	IsGuest    bool           `json:"is_guest,omitempty" gorm:"-"` // unauthenticated guest, never persisted
 	CreatedAt  time.Time      `json:"-"`
 	UpdatedAt  time.Time      `json:"-"`
 	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// ErrNoBot is returned by PostBotMessage if the identity is not the identity of a bot.
+var ErrNoBot = errors.New("no bot")
+
+// bot returns the bot of the client, nil if the user is no bot.
+func (c *Client) bot() *types.Bot {
+	c.roleLock.RLock()
+	defer c.roleLock.RUnlock()
+	if c.identity == nil {
+		return nil
+	}
+	return c.identity.Bot
+}
+
+// checkBotEvent checks whether the client may send events with the name, which is restricted for bots (see
+// types.Bot.AllowsEvent). It returns the notice for the bot if not, or the empty string.
+func (c *Client) checkBotEvent(name string) string {
+	if bot := c.bot(); bot != nil && !bot.AllowsEvent(name) {
+		return fmt.Sprintf("This bot is not allowed to send %q events.", name)
+	}
+	return ""
+}
+
+// botLimiter returns the rate limiter of the bot with the user id in the room.
+func (h *Hub) botLimiter(userId string) *rateLimiter {
+	h.botLimitersLock.Lock()
+	defer h.botLimitersLock.Unlock()
+	if h.botLimiters == nil {
+		h.botLimiters = make(map[string]*rateLimiter)
+	}
+	limiter, ok := h.botLimiters[userId]
+	if !ok {
+		limiter = &rateLimiter{}
+		h.botLimiters[userId] = limiter
+	}
+	return limiter
+}
+
+// forwardToPlugins queues the events sent by the client for the plugins (see PluginLoop). The events posted by a bot
+// without a connection are handled right away.
+func (c *Client) forwardToPlugins(events []*types.Event) {
+	if c.conn == nil {
+		go func() {
+			if err := c.hub.handlePlugins(context.Background(), events, nil); err != nil {
+				globals.AppLogger.Error("could not handle plugins", "error", err)
+			}
+		}()
+		return
+	}
+	c.hub.RLock()
+	if _, ok := c.hub.clients[c]; ok {
+		c.PluginChan <- events
+	}
+	c.hub.RUnlock()
+}
+
+// PostBotMessage handles a message posted via HTTP by the bot of the identity (user is its user) without a
+// connection, as if the bot sent it via a connection using protocol v1. The message is read from r, it is limited to
+// the maximum message size (see ErrMessageTooLarge). It returns the answer to the message: an error message (see
+// types.ErrorMessage), or the ack of a message with a request id, nil otherwise.
+func (h *Hub) PostBotMessage(user *types.User, identity *auth.Identity, r io.Reader) (*types.WebsocketMessage, error) {
+	if identity == nil || identity.Bot == nil {
+		return nil, ErrNoBot
+	}
+	c := NewClient(h, nil, user, identity, user.Language, nil)
+	c.setProtocol(ProtocolV1)
+	raw, err := readLimited(r, c.cfg.MaxMessageSize)
+	if err != nil {
+		return nil, err
+	}
+	message := types.WebsocketMessage{}
+	if err := json.Unmarshal(raw, &message); err != nil {
+		return nil, ErrMalformedMessage
+	}
+	switch message.Event {
+	case types.WireMessageTypeHello, types.WireMessageTypeLogin, types.WireMessageTypeLogout:
+		// there is no connection to set up
+		c.sendError(message.Id, errorUnknownEvent, fmt.Sprintf("%q messages cannot be posted.", message.Event))
+	default:
+		c.handleMessage(raw)
+	}
+	// the answers are queued for the client, an error takes precedence over an ack
+	var answer *types.WebsocketMessage
+	for len(c.Send) > 0 {
+		frame := <-c.Send
+		reply := &types.WebsocketMessage{}
+		if err := json.Unmarshal(frame.Data, reply); err != nil {
+			return nil, err
+		}
+		if answer == nil || reply.Event == types.WireMessageTypeError {
+			answer = reply
+		}
+	}
+	return answer, nil
+}
+
+// PostStatus returns the HTTP status answering a message posted by a bot (see PostBotMessage), answer is the answer
+// of the hub.
+func PostStatus(answer *types.WebsocketMessage) int {
+	if answer == nil || answer.Event != types.WireMessageTypeError {
+		return http.StatusAccepted
+	}
+	errorMessage := types.ErrorMessage{}
+	_ = json.Unmarshal(answer.Data, &errorMessage)
+	switch errorMessage.Code {
+	case errorForbidden:
+		return http.StatusForbidden
+	case errorLimit:
+		return http.StatusTooManyRequests
+	case errorRejected:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
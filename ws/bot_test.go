@@ -0,0 +1,67 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/types"
+	"github.com/tcriess/lightspeed-chat/ws"
+)
+
+// post posts the message as the bot, it returns the HTTP status of the answer.
+func post(t *testing.T, hub *ws.Hub, bot *types.Bot, event, id string, data interface{}) int {
+	user := &types.User{Id: bot.UserId, Nick: bot.UserId, Language: "en", Tags: make(map[string]string)}
+	identity := &auth.Identity{Provider: auth.BotProvider, UserId: bot.UserId, Bot: bot}
+	raw, err := json.Marshal(data)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	message, err := json.Marshal(types.WebsocketMessage{Event: event, Id: id, Data: raw})
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	answer, err := hub.PostBotMessage(user, identity, strings.NewReader(string(message)))
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	if id != "" && answer != nil && answer.Event != types.WireMessageTypeError {
+		assert.Equal(t, types.WireMessageTypeAck, answer.Event)
+		assert.Equal(t, id, answer.Id)
+	}
+	return ws.PostStatus(answer)
+}
+
+func TestPostBotMessage(t *testing.T) {
+	hub := newRunningHub(t)
+	alice := connect(hub, "alice", "")
+	defer alice.Disconnect()
+
+	bot := &types.Bot{UserId: "alerts", Events: "chat,alert", Messages: 2, Interval: time.Minute}
+	assert.Equal(t, http.StatusAccepted, post(t, hub, bot, types.WireMessageTypeChat, "1", types.ChatMessage{Message: "disk full"}))
+	event, err := alice.NextEvent(types.EventTypeChat, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "disk full", event.Tags["message"])
+		assert.Equal(t, "alerts", event.Source.User.Id)
+		assert.True(t, event.Source.Bot)
+	}
+
+	assert.Equal(t, http.StatusForbidden, post(t, hub, bot, types.WireMessageTypeChat, "", types.ChatMessage{Message: "/help"}), "commands are not allowed")
+	assert.Equal(t, http.StatusForbidden, post(t, hub, bot, "other", "", map[string]string{}))
+	assert.Equal(t, http.StatusBadRequest, post(t, hub, bot, types.WireMessageTypeLogin, "", map[string]string{}))
+
+	assert.Equal(t, http.StatusAccepted, post(t, hub, bot, "alert", "2", map[string]interface{}{"tags": map[string]string{"level": "high"}}))
+	event, err = alice.NextEvent("alert", timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "high", event.Tags["level"])
+		assert.True(t, event.Source.Bot)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, post(t, hub, bot, types.WireMessageTypeChat, "", types.ChatMessage{Message: "again"}), "the limit of the bot is shared by its posts")
+
+	_, err = hub.PostBotMessage(&types.User{Id: "alice"}, &auth.Identity{UserId: "alice"}, strings.NewReader("{}"))
+	assert.Equal(t, ws.ErrNoBot, err)
+}
@@ -0,0 +1,261 @@
+package ws
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/filter"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	// CloseSlowConsumer is the websocket close code sent to a client which is disconnected because it does not read
+	// the messages fast enough.
+	CloseSlowConsumer = 4000
+
+	// SlowConsumerDisconnect drops new messages if the send queue of a client is full and disconnects the client once
+	// more than the maximum number of dropped messages is reached.
+	SlowConsumerDisconnect = "disconnect"
+	// SlowConsumerDropOldest drops the oldest queued message if the send queue of a client is full.
+	SlowConsumerDropOldest = "drop_oldest"
+	// SlowConsumerDropNonChat drops events which are no chat messages (or translations) once the send queue of a
+	// client is three quarters full, if the queue is full, the oldest queued message is dropped.
+	SlowConsumerDropNonChat = "drop_non_chat"
+
+	// RoomSlowConsumerPolicyTag and RoomSlowConsumerMaxDroppedTag are the room tags overriding the slow consumer
+	// policy of the configuration.
+	RoomSlowConsumerPolicyTag     = "_slow_consumer.policy"
+	RoomSlowConsumerMaxDroppedTag = "_slow_consumer.max_dropped"
+)
+
+// ValidSlowConsumerPolicy reports whether policy is one of the slow consumer policies.
+func ValidSlowConsumerPolicy(policy string) bool {
+	switch policy {
+	case SlowConsumerDisconnect, SlowConsumerDropOldest, SlowConsumerDropNonChat:
+		return true
+	}
+	return false
+}
+
+// ClientMetrics are the statistics of the send queue of a client.
+type ClientMetrics struct {
+	UserId    string `json:"user_id"`
+	Queued    int    `json:"queued"`     // messages currently in the send queue
+	MaxQueued int64  `json:"max_queued"` // maximum number of messages in the send queue
+	Sent      int64  `json:"sent"`       // messages written to the connection
+	Dropped   int64  `json:"dropped"`    // messages dropped because the send queue was full
+}
+
+// clientStats are the counters of ClientMetrics, which are updated atomically.
+type clientStats struct {
+	maxQueued int64
+	sent      int64
+	dropped   int64
+}
+
+func (s *clientStats) queued(n int) {
+	for {
+		max := atomic.LoadInt64(&s.maxQueued)
+		if int64(n) <= max || atomic.CompareAndSwapInt64(&s.maxQueued, max, int64(n)) {
+			return
+		}
+	}
+}
+
+// Metrics returns the statistics of the send queue of the client.
+func (c *Client) Metrics() ClientMetrics {
+	return ClientMetrics{
+		UserId:    c.user.Id,
+		Queued:    len(c.Send),
+		MaxQueued: atomic.LoadInt64(&c.stats.maxQueued),
+		Sent:      atomic.LoadInt64(&c.stats.sent),
+		Dropped:   atomic.LoadInt64(&c.stats.dropped),
+	}
+}
+
+// ClientMetrics returns the statistics of the send queues of all clients of the room.
+func (h *Hub) ClientMetrics() []ClientMetrics {
+	h.RLock()
+	defer h.RUnlock()
+	metrics := make([]ClientMetrics, 0, len(h.clients))
+	for client := range h.clients {
+		metrics = append(metrics, client.Metrics())
+	}
+	return metrics
+}
+
+// slowConsumerConfig returns the slow consumer policy of the room: the room tags override the configuration, the
+// default is to disconnect a client at the first dropped message.
+func (h *Hub) slowConsumerConfig() config.SlowConsumerConfig {
+	cfg := config.SlowConsumerConfig{}
+	if h.Cfg != nil {
+		cfg = h.Cfg.WebsocketConfig.SlowConsumer
+	}
+	if h.Room != nil {
+		tags := h.roomTags()
+		if policy, ok := tags[RoomSlowConsumerPolicyTag]; ok {
+			cfg.Policy = policy
+		}
+		if v, ok := tags[RoomSlowConsumerMaxDroppedTag]; ok {
+			if maxDropped, err := strconv.Atoi(v); err == nil {
+				cfg.MaxDropped = maxDropped
+			}
+		}
+	}
+	if !ValidSlowConsumerPolicy(cfg.Policy) {
+		if cfg.Policy != "" {
+			globals.AppLogger.Warn("invalid slow consumer policy, disconnecting slow clients", "room", h.Room.Id, "policy", cfg.Policy)
+		}
+		cfg.Policy = SlowConsumerDisconnect
+	}
+	return cfg
+}
+
+// chatEvent reports whether the event is kept in favor of other events by SlowConsumerDropNonChat.
+func chatEvent(event *types.Event) bool {
+	return event.Name == types.EventTypeChat || event.Name == types.EventTypeTranslation
+}
+
+// broadcast sends the events to all clients which may read them and pass their target filters. Each event is
+// serialized once per encoding. Sending never blocks: if the send queue of a client is full, the slow consumer policy
+// of the room applies (see enqueue), the other clients are not affected.
+func (h *Hub) broadcast(events []*types.Event) {
+	for _, event := range events {
+		if event.Name == types.EventTypeInternal {
+			continue
+		}
+		var prog *vm.Program
+		if event.TargetFilter != "" {
+			var err error
+			prog, err = expr.Compile(event.TargetFilter, expr.Env(filter.Env{}))
+			if err != nil {
+				globals.AppLogger.Error("could not compile filter", "error", err)
+				continue
+			}
+		}
+		encoded := h.encoded.get(event)
+		chat := chatEvent(event)
+		slow := make([]*Client, 0)
+		h.RLock()
+		for client := range h.clients {
+			if !client.Can(types.PermissionRead) || !client.RunFilterEvent(event, prog) {
+				continue
+			}
+			frame := encoded.Frame(client.Encoding())
+			if frame.Data == nil {
+				continue
+			}
+			if client.enqueue(frame, chat) {
+				slow = append(slow, client)
+			}
+		}
+		h.RUnlock()
+		for _, client := range slow {
+			go client.disconnectSlow()
+		}
+	}
+}
+
+// enqueue adds the message to the send queue of the client without blocking. If the queue is full, a message is
+// dropped according to the slow consumer policy of the room, chat is set for messages which are kept in favor of
+// other events (chat messages and answers to the client). It reports whether the client is to be disconnected (see
+// disconnectSlow). The caller must hold the hub lock and make sure that the client is registered.
+func (c *Client) enqueue(frame Frame, chat bool) bool {
+	cfg := c.hub.slowConsumerConfig()
+	if cfg.Policy == SlowConsumerDropNonChat && !chat && 4*len(c.Send) >= 3*cap(c.Send) {
+		return c.drop(cfg)
+	}
+	select {
+	case c.Send <- frame:
+		c.stats.queued(len(c.Send))
+		return false
+	default:
+	}
+	if cfg.Policy == SlowConsumerDisconnect {
+		return c.drop(cfg)
+	}
+	// make room by dropping the oldest message
+	select {
+	case <-c.Send:
+		c.drop(cfg)
+	default:
+	}
+	select {
+	case c.Send <- frame:
+		return false
+	default:
+		return c.drop(cfg)
+	}
+}
+
+// drop counts a message dropped for the client. It reports whether the client is to be disconnected.
+func (c *Client) drop(cfg config.SlowConsumerConfig) bool {
+	dropped := atomic.AddInt64(&c.stats.dropped, 1)
+	clientsDropped.Add(c.hub.Room.Id, 1)
+	return cfg.Policy == SlowConsumerDisconnect && dropped > int64(cfg.MaxDropped)
+}
+
+// send adds the message to the send queue of the client, if it is registered. A slow client is disconnected. The
+// answers to a bot posting without a connection are always queued (see Hub.PostBotMessage).
+func (c *Client) send(frame Frame) {
+	if c.conn == nil {
+		c.enqueue(frame, true)
+		return
+	}
+	c.hub.RLock()
+	_, ok := c.hub.clients[c]
+	disconnect := ok && c.enqueue(frame, true)
+	c.hub.RUnlock()
+	if disconnect {
+		c.disconnectSlow()
+	}
+}
+
+// queueEvents encodes the events for the client and adds them to its send queue like send, if it is registered, so that
+// they are sent in order with the other messages to the client. Clients without the read permission do not receive
+// events.
+func (c *Client) queueEvents(events []*types.Event) {
+	if !c.Can(types.PermissionRead) {
+		return
+	}
+	frames := c.encodeEvents(events)
+	if len(frames) == 0 {
+		return
+	}
+	c.hub.RLock()
+	disconnect := false
+	if _, ok := c.hub.clients[c]; ok {
+		for _, frame := range frames {
+			if c.enqueue(frame, true) {
+				disconnect = true
+			}
+		}
+	}
+	c.hub.RUnlock()
+	if disconnect {
+		c.disconnectSlow()
+	}
+}
+
+// SlowConsumer reports whether the client was disconnected because it did not read its messages fast enough.
+func (c *Client) SlowConsumer() bool {
+	return atomic.LoadInt32(&c.slow) != 0
+}
+
+// disconnectSlow closes the connection of a client which does not read its messages fast enough, with the close code
+// CloseSlowConsumer. The read loop ends when the connection is closed, which unregisters the client.
+func (c *Client) disconnectSlow() {
+	if !atomic.CompareAndSwapInt32(&c.slow, 0, 1) {
+		return
+	}
+	slowConsumers.Add(c.hub.Room.Id, 1)
+	globals.AppLogger.Warn("disconnecting slow client", "room", c.hub.Room.Id, "user", c.user.Id, "dropped", atomic.LoadInt64(&c.stats.dropped))
+	if c.conn == nil {
+		return
+	}
+	_ = c.conn.Close(CloseSlowConsumer, "slow consumer")
+}
@@ -0,0 +1,245 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// newBroadcastTestClient registers a member client with a send queue of the given size.
+func newBroadcastTestClient(h *Hub, userId string, queueSize int) *Client {
+	c := &Client{
+		hub:  h,
+		user: &types.User{Id: userId, Nick: userId, Tags: make(map[string]string)},
+		role: types.RoleMember,
+		Send: make(chan Frame, queueSize),
+	}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func TestBroadcast(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	alice := newBroadcastTestClient(h, "alice", 10)
+	bob := newBroadcastTestClient(h, "bob", 10)
+	slow := newBroadcastTestClient(h, "slow", 1)
+	bob.setProtocol(ProtocolV1)
+	bob.setEncoding(EncodingProtobuf)
+
+	events := []*types.Event{
+		types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "one"}),
+		types.NewEvent(h.Room, nil, `Target.User.Id == "alice"`, "en", types.EventTypeChat, map[string]string{"message": "two"}),
+		types.NewEvent(h.Room, nil, "", "en", types.EventTypeInternal, nil),
+		types.NewEvent(h.Room, nil, "invalid ==", "en", types.EventTypeChat, nil),
+		types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "three"}),
+	}
+	h.broadcast(events)
+
+	messages := make([]string, 0)
+	for len(alice.Send) > 0 {
+		frame := <-alice.Send
+		assert.False(t, frame.Binary)
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, json.Unmarshal(frame.Data, &msg))
+		event := types.Event{}
+		assert.NoError(t, json.Unmarshal(msg.Data, &event))
+		messages = append(messages, event.Tags["message"])
+	}
+	assert.Equal(t, []string{"one", "two", "three"}, messages, "the events are sent in order")
+
+	if assert.Len(t, bob.Send, 2) {
+		assert.True(t, (<-bob.Send).Binary)
+	}
+	assert.False(t, alice.SlowConsumer())
+	assert.False(t, bob.SlowConsumer())
+	assert.Eventually(t, slow.SlowConsumer, time.Second, time.Millisecond, "the broadcast does not block on a full queue")
+	assert.Len(t, slow.Send, 1)
+	assert.Equal(t, int64(1), slow.Metrics().Dropped)
+	assert.Equal(t, int64(3), alice.Metrics().MaxQueued)
+}
+
+func TestSlowConsumerPolicies(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Room.Tags = map[string]string{RoomSlowConsumerPolicyTag: SlowConsumerDisconnect, RoomSlowConsumerMaxDroppedTag: "2"}
+	c := newBroadcastTestClient(h, "alice", 4)
+	frame := func(data string) Frame { return Frame{Data: []byte(data)} }
+	queued := func() []string {
+		res := make([]string, 0)
+		for len(c.Send) > 0 {
+			res = append(res, string((<-c.Send).Data))
+		}
+		return res
+	}
+
+	for i := 0; i < 4; i++ {
+		assert.False(t, c.enqueue(frame(strconv.Itoa(i)), true))
+	}
+	assert.False(t, c.enqueue(frame("4"), true))
+	assert.False(t, c.enqueue(frame("5"), true))
+	assert.True(t, c.enqueue(frame("6"), true), "disconnect after more than max_dropped messages")
+	assert.Equal(t, []string{"0", "1", "2", "3"}, queued(), "new messages are dropped")
+
+	h.Room.Tags[RoomSlowConsumerPolicyTag] = SlowConsumerDropOldest
+	for i := 0; i < 6; i++ {
+		assert.False(t, c.enqueue(frame(strconv.Itoa(i)), false))
+	}
+	assert.Equal(t, []string{"2", "3", "4", "5"}, queued())
+
+	h.Room.Tags[RoomSlowConsumerPolicyTag] = SlowConsumerDropNonChat
+	for _, data := range []string{"info1", "info2", "info3", "info4"} {
+		assert.False(t, c.enqueue(frame(data), false))
+	}
+	assert.False(t, c.enqueue(frame("chat1"), true))
+	assert.False(t, c.enqueue(frame("chat2"), true))
+	assert.Equal(t, []string{"info2", "info3", "chat1", "chat2"}, queued(), "non-chat events are dropped first")
+	assert.Equal(t, int64(3+2+2), c.Metrics().Dropped)
+	assert.False(t, c.SlowConsumer())
+
+	h.Room.Tags[RoomSlowConsumerPolicyTag] = "unknown"
+	h.Room.Tags[RoomSlowConsumerMaxDroppedTag] = "0"
+	assert.Equal(t, SlowConsumerDisconnect, h.slowConsumerConfig().Policy)
+}
+
+// TestStalledReader connects a websocket client which stops reading and lets the hub broadcast until the send queue
+// of the client is full.
+func TestStalledReader(t *testing.T) {
+	globals.AppLogger.SetLevel(hclog.Info)
+	defer globals.AppLogger.SetLevel(hclog.Debug)
+
+	for _, policy := range []string{SlowConsumerDisconnect, SlowConsumerDropOldest} {
+		t.Run(policy, func(t *testing.T) {
+			h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+			h.Room.Tags = map[string]string{RoomSlowConsumerPolicyTag: policy, RoomSlowConsumerMaxDroppedTag: "10"}
+			h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{SendQueueSize: 16}}
+			server, clients := newTestServer(h)
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL(server), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			c := <-clients
+
+			message := strings.Repeat("x", 32*1024)
+			sent := 0
+			broadcast := func(message string) {
+				h.broadcast([]*types.Event{types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, map[string]string{"message": message})})
+				sent++
+			}
+			// the queue is not drained any more once the connection is congested
+			drained := func() bool {
+				for deadline := time.Now().Add(50 * time.Millisecond); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+					if len(c.Send) < cap(c.Send) {
+						return true
+					}
+				}
+				return false
+			}
+			for sent < 10000 && drained() {
+				broadcast(message)
+			}
+			assert.Zero(t, c.Metrics().Dropped)
+			for i := 0; i < 20; i++ {
+				broadcast(message)
+			}
+			assert.Equal(t, int64(20), c.Metrics().Dropped)
+			broadcast("last")
+
+			received := 0
+			last := false
+			_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+			for !last {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					break
+				}
+				received++
+				last = strings.Contains(string(data), `"last"`)
+			}
+			assert.Less(t, received, sent)
+			if policy == SlowConsumerDisconnect {
+				assert.True(t, c.SlowConsumer())
+				assert.False(t, last, "the client is disconnected before the last message")
+			} else {
+				assert.False(t, c.SlowConsumer())
+				assert.True(t, last, "the newest message is kept")
+			}
+		})
+	}
+}
+
+// legacyBroadcast is the broadcast of earlier versions, which serialized the event for each client and started a
+// goroutine per client, for comparison.
+func legacyBroadcast(h *Hub, evt *types.Event) {
+	var wg sync.WaitGroup
+	h.RLock()
+	for client := range h.clients {
+		if !client.Can(types.PermissionRead) || !client.RunFilterEvent(evt, nil) {
+			continue
+		}
+		if data, err := json.Marshal(types.WireEvent{Event: evt}); err == nil {
+			wg.Add(1)
+			client.Add(1)
+			go func(c *Client, d []byte) {
+				defer wg.Done()
+				defer c.Done()
+				c.Send <- Frame{Data: d}
+			}(client, data)
+		}
+	}
+	wg.Wait()
+	h.RUnlock()
+}
+
+// BenchmarkBroadcast broadcasts chat messages to thousands of clients, which read their messages in a goroutine each.
+func BenchmarkBroadcast(b *testing.B) {
+	globals.AppLogger.SetLevel(hclog.Warn)
+	defer globals.AppLogger.SetLevel(hclog.Debug)
+	broadcasts := map[string]func(*Hub, *types.Event){
+		"legacy": legacyBroadcast,
+		"shared": func(h *Hub, event *types.Event) { h.broadcast([]*types.Event{event}) },
+	}
+	for _, clients := range []int{100, 1000, 5000} {
+		for _, name := range []string{"legacy", "shared"} {
+			broadcast := broadcasts[name]
+			b.Run(fmt.Sprintf("%s/%d", name, clients), func(b *testing.B) {
+				h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+				var wg sync.WaitGroup
+				for i := 0; i < clients; i++ {
+					c := newBroadcastTestClient(h, fmt.Sprintf("user%d", i), defaultQueueSize)
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for range c.Send {
+						}
+					}()
+				}
+				source := &types.Source{User: &types.User{Id: "alice", Nick: "Alice", Language: "en"}}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					tags := map[string]string{"message": fmt.Sprintf("message %d", i), "mime_type": "text/plain"}
+					broadcast(h, types.NewEvent(h.Room, source, "", "en", types.EventTypeChat, tags))
+				}
+				b.StopTimer()
+				for c := range h.clients {
+					close(c.Send)
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
@@ -1,45 +1,66 @@
 package ws
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
-	"gorm.io/gorm"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/folkengine/goname"
 	"github.com/gorilla/websocket"
 	"github.com/mitchellh/mapstructure"
 	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/config"
 	"github.com/tcriess/lightspeed-chat/globals"
 	"github.com/tcriess/lightspeed-chat/types"
-	"github.com/tidwall/buntdb"
 )
 
-const (
-	sendChannelSize   = 1000
-	pluginChannelSize = 1000
-)
-
-// Client is a middleman between the websocket connection and the hub.
+// Client is a middleman between the connection (see Transport) and the hub.
 type Client struct {
+	// statistics of the send queue, the first field to align the atomic counters
+	stats clientStats
+
 	hub *Hub
 
-	// The websocket connection.
-	conn *websocket.Conn
+	// The connection, its configuration and its id, which is used to post messages via HTTP (see Hub.PostMessage).
+	// conn is nil if a bot posts a message without a connection (see Hub.PostBotMessage).
+	conn Transport
+	cfg  config.WebsocketConfig
+	id   string
 
-	// Buffered channel of outbound messages.
-	Send chan []byte
+	// messageLock serializes the handling of the messages of the client, which are read by ReadLoop or posted.
+	messageLock sync.Mutex
 
-	SendEvents chan []*types.Event
+	// Buffered channel of outbound messages, events are queued encoded for the client (see queueEvents) so that all
+	// messages are sent in order.
+	Send chan Frame
 
 	Language string
 
 	user *types.User
 
+	// authenticated is true if the user logged in (or connected with a valid token), identity is the result of the
+	// authentication, role is the role of the user in the room (see Hub.RoleOf), groupRole the role derived from the
+	// groups of the identity provider which replaces the default member role
+	authenticated bool
+	identity      *auth.Identity
+	groupRole     string
+	role          string
+	roleLock      sync.RWMutex
+
+	// protocol version used by the client (see Protocol), binary is 1 if the client uses the protobuf encoding (see
+	// Encoding), slow is 1 if the client was disconnected as a slow consumer
+	protocol int32
+	binary   int32
+	slow     int32
+
+	// guest is the guest identity of the connection, which is used after a logout
+	guest   *types.User
+	limiter rateLimiter
+
 	PluginChan chan []*types.Event
 	doneChan   chan struct{}
 
@@ -48,7 +69,9 @@ type Client struct {
 	sync.WaitGroup
 }
 
-func NewClient(hub *Hub, conn *websocket.Conn, user *types.User, language string, doneChan chan struct{}) *Client {
+// NewClient creates the client for the user connected via conn. identity is the result of the authentication, nil for
+// guests.
+func NewClient(hub *Hub, conn Transport, user *types.User, identity *auth.Identity, language string, doneChan chan struct{}) *Client {
 	lang := language
 	if len(lang) > 2 {
 		lang = lang[0:2]
@@ -56,251 +79,502 @@ func NewClient(hub *Hub, conn *websocket.Conn, user *types.User, language string
 	if len(lang) < 2 {
 		lang = "en"
 	}
-	return &Client{
+	var guest *types.User
+	if user.IsGuest {
+		guest = user
+	}
+	cfg := hub.websocketConfig()
+	c := &Client{
 		hub:        hub,
 		conn:       conn,
-		Send:       make(chan []byte, sendChannelSize),
-		SendEvents: make(chan []*types.Event, sendChannelSize),
+		cfg:        cfg,
+		id:         newConnectionId(),
+		Send:       make(chan Frame, cfg.SendQueueSize),
 		user:       user,
+		guest:      guest,
 		Language:   lang,
 		doneChan:   doneChan,
-		PluginChan: make(chan []*types.Event, pluginChannelSize),
+		PluginChan: make(chan []*types.Event, cfg.PluginQueueSize),
+	}
+	if t, ok := conn.(subprotocolTransport); ok {
+		c.protocol = int32(ProtocolFromSubprotocol(t.Subprotocol()))
+		c.setEncoding(EncodingFromSubprotocol(t.Subprotocol()))
 	}
+	c.setIdentity(identity)
+	return c
 }
 
+// Role returns the role of the user in the room.
+func (c *Client) Role() string {
+	c.roleLock.RLock()
+	defer c.roleLock.RUnlock()
+	return c.role
+}
+
+// setIdentity updates the role after the user has logged in (identity is the result of the authentication) or out
+// (identity is nil).
+func (c *Client) setIdentity(identity *auth.Identity) {
+	authenticated := identity != nil
+	groupRole := ""
+	if authenticated {
+		groupRole = identity.RoleIn(c.hub.Room.Id)
+	}
+	role := c.hub.RoleOf(c.user, authenticated)
+	if role == types.RoleMember && groupRole != "" {
+		role = groupRole
+	}
+	c.roleLock.Lock()
+	defer c.roleLock.Unlock()
+	c.authenticated = authenticated
+	c.identity = identity
+	c.groupRole = groupRole
+	c.role = role
+}
+
+// updateRole sets the role if the client belongs to the authenticated user with the given id.
+func (c *Client) updateRole(userId, role string) {
+	c.roleLock.Lock()
+	defer c.roleLock.Unlock()
+	if c.authenticated && c.user.Id == userId {
+		if role == types.RoleMember && c.groupRole != "" {
+			role = c.groupRole
+		}
+		c.role = role
+	}
+}
+
+// Can reports whether the user has the permission in the room.
+func (c *Client) Can(permission string) bool {
+	return c.hub.allows(c.Role(), permission)
+}
+
+// SendHistory sends the history events suitable for the client language (see selectHistory) to the client.
+// Translations which are missing for the client language are requested from the plugins in the background, they are
+// broadcast once they are available.
 func (c *Client) SendHistory(events []*types.Event, wg *sync.WaitGroup) {
+	language := strings.ToLower(c.Language)
+	c.queueEvents(selectHistory(events, language))
 	if wg != nil {
-		defer wg.Done()
+		wg.Done()
 	}
-	c.hub.RLock()
-	if _, ok := c.hub.clients[c]; ok {
-		c.SendEvents <- events
+
+	go c.hub.translateHistory(context.Background(), events, language)
+}
+
+// selectHistory picks the version of each history message to be shown to a client using language: originals are
+// always kept, of the translations only the latest one into language per original is kept, and only if the original is
+// not written in language already. The order of the events is preserved.
+func selectHistory(events []*types.Event, language string) []*types.Event {
+	if len(language) >= 2 {
+		language = strings.ToLower(language[:2])
+	}
+	originalLanguages := make(map[string]string)
+	latestTranslations := make(map[string]*types.Event)
+	for _, event := range events {
+		if event.Name != types.EventTypeTranslation {
+			originalLanguages[event.Id] = event.Language
+			continue
+		}
+		if !strings.EqualFold(event.Language, language) {
+			continue
+		}
+		if sourceId, ok := event.Tags["source_id"]; ok {
+			latestTranslations[sourceId] = event
+		}
+	}
+	selected := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		if event.Name == types.EventTypeTranslation {
+			sourceId := event.Tags["source_id"]
+			if latestTranslations[sourceId] != event {
+				continue
+			}
+			if originalLanguage, ok := originalLanguages[sourceId]; ok && len(originalLanguage) >= 2 && strings.EqualFold(originalLanguage[:2], language) {
+				continue
+			}
+		}
+		selected = append(selected, event)
 	}
-	c.hub.RUnlock()
+	return selected
 }
 
-// ReadLoop pumps messages from the websocket connection to the hub.
+// ReadLoop pumps messages from the connection to the hub.
 //
 // The application runs ReadLoop in a per-connection goroutine. The application
 // ensures that there is at most one reader on a connection by executing all
 // reads from this goroutine.
 func (c *Client) ReadLoop() {
 	defer func() {
-		c.conn.Close()
+		c.conn.Close(0, "")
 		close(c.doneChan)
 		c.Done()
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
-	message := &types.WebsocketMessage{}
 	for {
-		_, raw, err := c.conn.ReadMessage()
+		raw, err := c.conn.ReadMessage()
+		if err == ErrMessageTooLarge {
+			globals.AppLogger.Info("message too large", "user", c.user.Id)
+			c.sendError("", errorMessageTooLarge, fmt.Sprintf("Messages are limited to %d bytes.", c.cfg.MaxMessageSize))
+			continue
+		}
 		if err != nil {
+			if err == websocket.ErrReadLimit {
+				globals.AppLogger.Info("read limit exceeded, closing connection", "user", c.user.Id)
+			}
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				globals.AppLogger.Info("ws closed unexpected")
 			}
 			return
 		}
-		err = json.Unmarshal(raw, &message)
-		if err != nil {
-			globals.AppLogger.Error("could not unmarshal ws message", "error", err)
+		if !c.handleMessage(raw) {
 			return
 		}
+	}
+}
 
-		if message.Event == types.WireMessageTypeLogout && c.user.Id != "" {
-			var userId string
-			nick := goname.New(goname.FantasyMap).FirstLast() + " (guest)"
-			if ag, ok := c.hub.Room.Tags["_allow_guests"]; ok {
-				if allowGuests, err := strconv.ParseBool(ag); err == nil && allowGuests {
-					userId = nick
-				}
-			}
-			user := types.User{
-				Id:         userId,
-				Nick:       nick,
-				Language:   c.Language,
-				Tags:       make(map[string]string),
-				LastOnline: time.Time{},
+// handleMessage handles a message of the client. It reports whether the connection remains open, a malformed message
+// closes the connection of a client using protocol v0 (see malformed).
+func (c *Client) handleMessage(raw []byte) bool {
+	c.messageLock.Lock()
+	defer c.messageLock.Unlock()
+	message := &types.WebsocketMessage{}
+	err := json.Unmarshal(raw, message)
+	if err != nil {
+		return !c.malformed("", err)
+	}
+
+	if message.Event == types.WireMessageTypeHello {
+		c.hello(message.Id, message.Data)
+		return true
+	}
+	if message.Event == types.WireMessageTypeLogout {
+		if !c.user.IsGuest {
+			if c.guest == nil {
+				c.guest = NewGuest()
 			}
-			c.user = &user
+			c.guest.Language = c.Language
+			c.user = c.guest
+			c.setIdentity(nil)
+			c.SendSession()
 		}
-		if message.Event == types.WireMessageTypeLogin {
-			var sendHistory bool
-			var userId string
-			loginMsgMap := make(map[string]interface{})
-			err = json.Unmarshal(message.Data, &loginMsgMap)
-			if err != nil {
-				globals.AppLogger.Error("could not unmarshal login message", "error", err)
-				return
-			}
-			loginMsg := types.LoginMessage{}
-			err = mapstructure.WeakDecode(loginMsgMap, &loginMsg)
+		if c.Protocol() >= ProtocolV1 {
+			c.ack(message.Id, nil)
+			return true
+		}
+	}
+	if message.Event == types.WireMessageTypeLogin {
+		var sendHistory bool
+		loginMsgMap := make(map[string]interface{})
+		err = json.Unmarshal(message.Data, &loginMsgMap)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		loginMsg := types.LoginMessage{}
+		err = mapstructure.WeakDecode(loginMsgMap, &loginMsg)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		authenticated := true
+		if loginMsg.IdToken != "" && loginMsg.Provider != "" {
+			identity, err := c.hub.Auth.Authenticate(context.Background(), loginMsg.IdToken, loginMsg.Provider)
 			if err != nil {
-				globals.AppLogger.Error("could not decode login message", "error", err)
-				return
+				globals.AppLogger.Error("could not authenticate", "error", err)
 			}
-			if loginMsg.IdToken != "" && loginMsg.Provider != "" {
-				var err error
-				userId, err = auth.Authenticate(loginMsg.IdToken, loginMsg.Provider, c.hub.Cfg)
+			authenticated = identity != nil
+			if identity != nil {
+				sendHistory = true
+				newUser, err := c.hub.LoadUser(identity)
 				if err != nil {
-					globals.AppLogger.Error("could not authenticate", "error", err)
+					globals.AppLogger.Error("could not load user", "error", err)
+					return false
 				}
-				if userId != "" {
-					sendHistory = true
-					newUser := types.User{Id: userId}
-					if c.hub.Persister != nil {
-						err := c.hub.Persister.GetUser(&newUser)
-						if err == gorm.ErrRecordNotFound || err == buntdb.ErrNotFound || err == sql.ErrNoRows {
-							newUser.Nick = newUser.Id
-							newUser.Language = "en"
-							newUser.LastOnline = time.Now()
-							err := c.hub.Persister.StoreUser(newUser)
-							if err != nil {
-								globals.AppLogger.Error("could not store user", "error", err)
-								return
-							}
-						} else {
-							if err != nil {
-								globals.AppLogger.Error("could not get user", "error", err)
-								return
-							}
-						}
-					} else {
-						newUser.Nick = newUser.Id
-						newUser.Language = "en"
-						newUser.LastOnline = time.Now()
-					}
-					c.user = &newUser
-					if newUser.Language != "" {
-						c.Language = newUser.Language
-					}
+				c.user = newUser
+				c.setIdentity(identity)
+				c.SendSession()
+				if newUser.Language != "" {
+					c.Language = newUser.Language
 				}
 			}
-			if len(loginMsg.Language) > 1 && c.user.Id != "" {
-				c.Language = strings.ToLower(loginMsg.Language[:2])
-				sendHistory = true
-			}
-			if sendHistory {
-				go c.SendHistory(c.hub.GetHistory(), nil)
-			}
 		}
-
-		if c.user.Id == "" {
-			filter := fmt.Sprintf(`Target.User.Nick == %s`, strconv.Quote(c.user.Nick))
-			tags := make(map[string]string)
-			tags["message"] = "Please log in to post a message!"
-			source := &types.Source{
-				User: c.user,
-			}
-			event := types.NewEvent(c.hub.Room, source, filter, "en", types.EventTypeChat, tags)
-			events := []*types.Event{event}
-			c.hub.RLock()
-			if _, ok := c.hub.clients[c]; ok {
-				c.SendEvents <- events
-				c.PluginChan <- events
+		if len(loginMsg.Language) > 1 {
+			c.Language = strings.ToLower(loginMsg.Language[:2])
+			sendHistory = true
+		}
+		if sendHistory {
+			go c.SendHistory(c.hub.GetHistory(), nil)
+		}
+		if c.Protocol() >= ProtocolV1 {
+			if authenticated {
+				c.ack(message.Id, nil)
+			} else {
+				c.sendError(message.Id, errorUnauthenticated, "The login failed.")
 			}
-			c.hub.RUnlock()
-			continue
+			return true
 		}
+	}
 
-		switch message.Event {
-		case types.WireMessageTypeChat:
-			chatMsgMap := make(map[string]interface{})
-			err = json.Unmarshal(message.Data, &chatMsgMap)
-			if err != nil {
-				globals.AppLogger.Error("could not unmarshal chat message", "error", err)
-				return
+	switch message.Event {
+	case types.WireMessageTypeChat:
+		chatMsgMap := make(map[string]interface{})
+		err = json.Unmarshal(message.Data, &chatMsgMap)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		chatMsg := types.ChatMessage{}
+		err = mapstructure.WeakDecode(chatMsgMap, &chatMsg)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		chatMsg.Timestamp = time.Now()
+		chatMsg.Nick = c.user.Nick
+		if notice := c.checkChatPermissions(chatMsg.Message); notice != "" {
+			c.sendError(message.Id, errorForbidden, notice)
+			return true
+		}
+		eventName := types.EventTypeChat
+		if strings.HasPrefix(chatMsg.Message, "/") {
+			eventName = types.EventTypeCommand
+		}
+		if notice := c.checkBotEvent(eventName); notice != "" {
+			c.sendError(message.Id, errorForbidden, notice)
+			return true
+		}
+		if notice := c.checkLimits(chatMsg.Message); notice != "" {
+			c.sendError(message.Id, errorLimit, notice)
+			return true
+		}
+		source := &types.Source{
+			User: c.user,
+			Role: c.Role(),
+			Bot:  c.bot() != nil,
+		}
+		tags := map[string]string{
+			"message":   chatMsg.Message,
+			"mime_type": "text/plain",
+		}
+		if !strings.HasPrefix(chatMsg.Message, "/") {
+			event := types.NewEvent(c.hub.Room, source, chatMsg.Filter, c.messageLanguage(chatMsg.Language), types.EventTypeChat, tags)
+			events := c.filterEvents([]*types.Event{event}, message.Id)
+			if len(events) == 0 {
+				return true
 			}
-			chatMsg := types.ChatMessage{}
-			err = mapstructure.WeakDecode(chatMsgMap, &chatMsg)
-			if err != nil {
-				globals.AppLogger.Error("could not decode chat message", "error", err)
-				return
+			_ = c.hub.handleEvents(events)
+			c.ack(message.Id, events[0])
+			c.forwardToPlugins(events)
+		} else {
+			tags["original_target_filter"] = chatMsg.Filter
+			// set the filter to send commands only to the original sender
+			filter := ""
+			if chatMsg.Filter != "" {
+				filter = "(" + chatMsg.Filter + ") && " + fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
+			} else {
+				filter = fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
 			}
-			chatMsg.Timestamp = time.Now()
-			chatMsg.Nick = c.user.Nick
-			source := &types.Source{
-				User: c.user,
+			fields := strings.Fields(chatMsg.Message)
+			args := ""
+			if len(fields) > 1 {
+				args = strings.Join(fields[1:], " ")
 			}
-			tags := map[string]string{
-				"message":   chatMsg.Message,
-				"mime_type": "text/plain",
+			tags["command"] = fields[0]
+			tags["args"] = args
+			cmdEvent := types.NewEvent(c.hub.Room, source, filter, c.messageLanguage(chatMsg.Language), types.EventTypeCommand, tags)
+			events := c.filterEvents([]*types.Event{cmdEvent}, message.Id)
+			if len(events) == 0 {
+				return true
 			}
-			if !strings.HasPrefix(chatMsg.Message, "/") {
-				event := types.NewEvent(c.hub.Room, source, chatMsg.Filter, chatMsg.Language, types.EventTypeChat, tags)
-				events := []*types.Event{event}
-				c.hub.EventHistory <- events
-				c.hub.BroadcastEvents <- events
-				c.hub.RLock()
-				if _, ok := c.hub.clients[c]; ok {
-					c.PluginChan <- events
-				}
-				c.hub.RUnlock()
-			} else {
-				tags["original_target_filter"] = chatMsg.Filter
-				// set the filter to send commands only to the original sender
-				filter := ""
-				if chatMsg.Filter != "" {
-					filter = "(" + chatMsg.Filter + ") && " + fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
-				} else {
-					filter = fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
-				}
-				fields := strings.Fields(chatMsg.Message)
-				args := ""
-				if len(fields) > 1 {
-					args = strings.Join(fields[1:], " ")
-				}
-				tags["command"] = fields[0]
-				tags["args"] = args
-				cmdEvent := types.NewEvent(c.hub.Room, source, filter, chatMsg.Language, types.EventTypeCommand, tags)
-				events := []*types.Event{cmdEvent}
-				c.hub.RLock()
-				if _, ok := c.hub.clients[c]; ok {
-					c.SendEvents <- events
-					c.PluginChan <- events
-				}
-				c.hub.RUnlock()
+			cmd, err := c.hub.prepareCommand(events[0])
+			if err != nil || cmd == nil {
+				c.replyCommand(events[0], message.Id, err)
+				return true
 			}
+			c.ack(message.Id, events[0])
+			c.queueEvents(events)
+			c.forwardToPlugins(events)
+		}
 
-		default:
-			// the client sends "something". We assume it is an event and add source and room information.
-			msgMap := make(map[string]interface{})
-			err = json.Unmarshal(message.Data, &msgMap)
-			if err != nil {
-				globals.AppLogger.Error("could not unmarshal message", "error", err)
-				return
-			}
-			msg := struct {
-				TargetFilter string            `mapstructure:"target_filter"`
-				Language     string            `mapstructure:"language"`
-				Tags         map[string]string `mapstructure:"tags"`
-			}{}
-			err = mapstructure.WeakDecode(msgMap, &msg)
-			if err != nil {
-				globals.AppLogger.Error("could not decode message", "error", err)
-				return
-			}
-			source := &types.Source{
-				User: &types.User{
-					Id:         c.user.Id,
-					Nick:       c.user.Nick,
-					Language:   c.user.Language,
-					Tags:       c.user.Tags,
-					LastOnline: c.user.LastOnline,
-				},
-				PluginName: "",
+	case types.EventTypeCommand:
+		// commands are sent as chat messages, so that they are parsed and checked
+		globals.AppLogger.Warn("ignoring command event sent by client", "user", c.user.Id)
+		if c.Protocol() >= ProtocolV1 {
+			c.sendError(message.Id, errorUnknownEvent, "Commands are sent as chat messages.")
+		}
+
+	default:
+		// the client sends "something". We assume it is an event and add source and room information.
+		if c.Protocol() >= ProtocolV1 && reservedEvent(message.Event) {
+			c.sendError(message.Id, errorUnknownEvent, fmt.Sprintf("Clients cannot send %q events.", message.Event))
+			return true
+		}
+		msgMap := make(map[string]interface{})
+		err = json.Unmarshal(message.Data, &msgMap)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		msg := struct {
+			TargetFilter string            `mapstructure:"target_filter"`
+			Language     string            `mapstructure:"language"`
+			Tags         map[string]string `mapstructure:"tags"`
+		}{}
+		err = mapstructure.WeakDecode(msgMap, &msg)
+		if err != nil {
+			return !c.malformed(message.Id, err)
+		}
+		if !c.Can(types.PermissionPost) {
+			globals.AppLogger.Debug("user is not allowed to post", "user", c.user.Id, "event", message.Event)
+			if c.Protocol() >= ProtocolV1 {
+				c.sendError(message.Id, errorForbidden, "You are not allowed to post in this room.")
 			}
-			event := types.NewEvent(c.hub.Room, source, msg.TargetFilter, msg.Language, message.Event, msg.Tags)
-			events := []*types.Event{event}
-			c.hub.EventHistory <- events
-			c.hub.BroadcastEvents <- events
-			c.hub.RLock()
-			if _, ok := c.hub.clients[c]; ok {
-				c.PluginChan <- events
+			return true
+		}
+		if notice := c.checkBotEvent(message.Event); notice != "" {
+			c.sendError(message.Id, errorForbidden, notice)
+			return true
+		}
+		if notice := c.checkLimits(msg.Tags["message"]); notice != "" {
+			c.sendError(message.Id, errorLimit, notice)
+			return true
+		}
+		source := &types.Source{
+			User: &types.User{
+				Id:         c.user.Id,
+				Nick:       c.user.Nick,
+				Language:   c.user.Language,
+				Tags:       c.user.Tags,
+				LastOnline: c.user.LastOnline,
+				IsGuest:    c.user.IsGuest,
+			},
+			PluginName: "",
+			Role:       c.Role(),
+			Bot:        c.bot() != nil,
+		}
+		event := types.NewEvent(c.hub.Room, source, msg.TargetFilter, c.messageLanguage(msg.Language), message.Event, msg.Tags)
+		events := c.filterEvents([]*types.Event{event}, message.Id)
+		if len(events) == 0 {
+			return true
+		}
+		_ = c.hub.handleEvents(events)
+		c.ack(message.Id, events[0])
+		c.forwardToPlugins(events)
+	}
+	return true
+}
+
+// messageLanguage returns the language of a message sent by the client (2 letters, lower case): the language given in
+// the message, or else the client language, or else the user language.
+func (c *Client) messageLanguage(language string) string {
+	for _, l := range []string{language, c.Language, c.user.Language} {
+		if len(l) >= 2 {
+			return strings.ToLower(l[:2])
+		}
+	}
+	return ""
+}
+
+// filterEvents passes the events sent by the client (in the message with the request id) through the interceptor
+// plugins and returns the events to be broadcast. For each rejected event, the client receives a private notice
+// containing the reason, or an error if it uses protocol v1.
+func (c *Client) filterEvents(events []*types.Event, requestId string) []*types.Event {
+	events, rejected := c.hub.filterEvents(context.Background(), events, nil)
+	if len(rejected) == 0 {
+		return events
+	}
+	if c.Protocol() >= ProtocolV1 {
+		for _, r := range rejected {
+			message := "Your message was rejected."
+			if r.reason != "" {
+				message = fmt.Sprintf("Your message was rejected: %s", r.reason)
 			}
-			c.hub.RUnlock()
+			c.sendError(requestId, errorRejected, message)
+		}
+		return events
+	}
+	notices := make([]*types.Event, 0, len(rejected))
+	for _, r := range rejected {
+		filter := fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
+		message := "Your message was rejected."
+		if r.reason != "" {
+			message = fmt.Sprintf("Your message was rejected: %s", r.reason)
+		}
+		tags := map[string]string{
+			"message":     message,
+			"mime_type":   "text/plain",
+			"rejected":    "true",
+			"rejected_id": r.event.Id,
+			"reason":      r.reason,
+		}
+		source := &types.Source{
+			User:       c.user,
+			PluginName: r.pluginName,
+		}
+		notices = append(notices, types.NewEvent(c.hub.Room, source, filter, "en", types.EventTypeChat, tags))
+	}
+	c.queueEvents(notices)
+	return events
+}
+
+// checkChatPermissions checks whether the user may send the chat message (or command). It returns the notice for the
+// user if not, or the empty string.
+func (c *Client) checkChatPermissions(message string) string {
+	if strings.HasPrefix(message, "/") {
+		if !c.Can(types.PermissionCommands) {
+			return "You are not allowed to use commands in this room."
+		}
+		return ""
+	}
+	if !c.Can(types.PermissionPost) {
+		if c.user.IsGuest {
+			return "Please log in to post a message!"
 		}
+		return "You are not allowed to post in this room."
+	}
+	if !c.Can(types.PermissionPostLinks) && linkRegexp.MatchString(message) {
+		return "You are not allowed to post links in this room."
+	}
+	return ""
+}
+
+// sendNotice sends a chat message from the server to the user of the client only.
+func (c *Client) sendNotice(message string) {
+	filter := fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
+	tags := map[string]string{
+		"message":   message,
+		"mime_type": "text/plain",
+	}
+	source := &types.Source{
+		User:       c.user,
+		PluginName: "main",
+	}
+	events := []*types.Event{types.NewEvent(c.hub.Room, source, filter, "en", types.EventTypeChat, tags)}
+	c.queueEvents(events)
+}
+
+// replyCommand answers a command (sent in the message with the request id) which is not passed on to a plugin: the
+// built-in /help command (err is nil) or a command which cannot be executed. Clients using protocol v1 receive an ack or
+// an error in addition.
+func (c *Client) replyCommand(event *types.Event, requestId string, err error) {
+	filter := fmt.Sprintf(`Target.User.Id == %s`, strconv.Quote(c.user.Id))
+	tags := map[string]string{
+		"mime_type":  "text/plain",
+		"command_id": event.Id,
+	}
+	if err == nil {
+		tags["message"] = c.hub.helpText(event)
+	} else {
+		tags["message"] = err.Error()
+		tags["command_error"] = commandErrorUnknown
+		if cmdErr, ok := err.(*commandError); ok {
+			tags["command_error"] = cmdErr.code
+		}
+	}
+	source := &types.Source{
+		User:       c.user,
+		PluginName: "main",
+	}
+	events := []*types.Event{types.NewEvent(c.hub.Room, source, filter, "en", types.EventTypeChat, tags)}
+	c.queueEvents(events)
+	if err == nil {
+		c.ack(requestId, event)
+	} else if c.Protocol() >= ProtocolV1 {
+		c.sendError(requestId, tags["command_error"], tags["message"])
 	}
 }
 
@@ -311,10 +585,10 @@ func (c *Client) ReadLoop() {
 // executing all writes from this goroutine.
 func (c *Client) WriteLoop() {
 	globals.AppLogger.Info("info: in WriteLoop")
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.cfg.PingPeriod)
 	defer func() {
 		ticker.Stop()
-		c.conn.Close()
+		c.conn.Close(0, "")
 		c.Done()
 	}()
 	for {
@@ -325,78 +599,21 @@ func (c *Client) WriteLoop() {
 		default:
 		}
 		select {
-		case events, ok := <-c.SendEvents:
-			if !ok {
-				// The hub closed the channel.
-				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				globals.AppLogger.Info("Send event channel closed, exiting write loop")
-				return
-			}
-
-			eventsSlices := make(map[string][]json.RawMessage)
-			for _, event := range events {
-				if event.TargetFilter != "" {
-					if !c.EvaluateFilterEvent(event) {
-						continue
-					}
-				}
-				w, err := json.Marshal(types.WireEvent{Event: event})
-				if err != nil {
-					globals.AppLogger.Error("could not marshal event", "error", err)
-					continue
-				}
-				if _, ok := eventsSlices[event.Name]; !ok {
-					eventsSlices[event.Name] = make([]json.RawMessage, 0, len(events))
-				}
-				eventsSlices[event.Name] = append(eventsSlices[event.Name], w)
-			}
-			for eventType, outEvents := range eventsSlices {
-				outEventType := eventType + "s" // make plural...
-				data, err := json.Marshal(outEvents)
-				if err != nil {
-					globals.AppLogger.Error("could not marshal events", "error", err)
-					continue
-				}
-				msg := types.WebsocketMessage{
-					Event: outEventType,
-					Data:  data,
-				}
-				w, err := json.Marshal(msg)
-				if err != nil {
-					globals.AppLogger.Error("could not marshal events", "error", err)
-					continue
-				}
-				c.Send <- w
-			}
-
 		case message, ok := <-c.Send:
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The hub closed the channel.
-				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				_ = c.conn.Close(websocket.CloseNormalClosure, "")
 				globals.AppLogger.Info("send channel closed, exiting write loop")
 				return
 			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				globals.AppLogger.Info("could not write to ws connection, exiting write loop")
-				return
-			}
-			_, err = w.Write(message)
-			if err != nil {
-				globals.AppLogger.Error("could not send message", "error", err)
-				w.Close()
-				return
-			}
-
-			if err := w.Close(); err != nil {
+			if err := c.conn.WriteMessage(message); err != nil {
+				globals.AppLogger.Info("could not write to connection, exiting write loop", "error", err)
 				return
 			}
+			atomic.AddInt64(&c.stats.sent, 1)
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := c.conn.Ping(); err != nil {
 				globals.AppLogger.Info("could not send ping message, exiting write loop")
 				return
 			}
@@ -424,8 +641,7 @@ func (c *Client) PluginLoop() {
 				globals.AppLogger.Info("PluginChan closed, exiting client plugin loop")
 				return
 			}
-			skipPlugins := make(map[string]struct{})
-			err := c.hub.handlePlugins(events, skipPlugins)
+			err := c.hub.handlePlugins(context.Background(), events, nil)
 			if err != nil {
 				globals.AppLogger.Error("could not handle plugins", "error", err)
 				continue
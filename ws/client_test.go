@@ -0,0 +1,32 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestSelectHistory(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	chat := func(language, message string) *types.Event {
+		return types.NewEvent(room, nil, "", language, types.EventTypeChat, map[string]string{"message": message})
+	}
+	translation := func(source *types.Event, language, message string) *types.Event {
+		tags := map[string]string{"message": message, "source_id": source.Id, "source_language": source.Language}
+		return types.NewEvent(room, nil, "", language, types.EventTypeTranslation, tags)
+	}
+	german := chat("de", "hallo")
+	english := chat("en", "hello")
+	germanEn := translation(german, "en", "hello (1)")
+	germanEn2 := translation(german, "en", "hello (2)")
+	germanEs := translation(german, "es", "hola")
+	englishDe := translation(english, "de", "hallo")
+	orphanEn := translation(&types.Event{Id: "gone"}, "en", "orphan")
+	history := []*types.Event{german, germanEn, germanEs, english, englishDe, germanEn2, orphanEn}
+
+	assert.Equal(t, []*types.Event{german, english, germanEn2, orphanEn}, selectHistory(history, "en-US"))
+	assert.Equal(t, []*types.Event{german, english, englishDe}, selectHistory(history, "de"))
+	assert.Equal(t, []*types.Event{german, germanEs, english}, selectHistory(history, "es"))
+	assert.Equal(t, []*types.Event{german, english}, selectHistory(history, "fr"))
+}
@@ -0,0 +1,141 @@
+package ws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/tcriess/lightspeed-chat/filter"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const helpCommand = "/help"
+
+const (
+	commandErrorUnknown    = "unknown_command"
+	commandErrorArgs       = "invalid_arguments"
+	commandErrorPermission = "permission_denied"
+)
+
+// registeredCommand is a command registered by a plugin.
+type registeredCommand struct {
+	plugins.CommandSpec
+	pluginName string
+	permission *vm.Program // nil if everyone may use the command
+}
+
+// commandError is returned to the sender of a command which cannot be executed.
+type commandError struct {
+	code    string
+	message string
+}
+
+func (e *commandError) Error() string {
+	return e.message
+}
+
+// newCommandRegistry collects the commands of the observer plugins. If more than one plugin registers the same command,
+// the plugin with the highest priority wins.
+func newCommandRegistry(pluginMap map[string]plugins.PluginSpec, pluginOrder []string) map[string]*registeredCommand {
+	commands := make(map[string]*registeredCommand)
+	for _, pluginName := range pluginOrder {
+		plg := pluginMap[pluginName]
+		for _, spec := range plg.Commands {
+			if plg.Kind != plugins.KindObserver {
+				globals.AppLogger.Warn("ignoring command of interceptor plugin", "plugin", pluginName, "command", spec.Name)
+				continue
+			}
+			if spec.Name == helpCommand {
+				globals.AppLogger.Warn("ignoring built-in command", "plugin", pluginName, "command", spec.Name)
+				continue
+			}
+			if other, ok := commands[spec.Name]; ok {
+				globals.AppLogger.Warn("command registered twice", "command", spec.Name, "plugin", pluginName, "used", other.pluginName)
+				continue
+			}
+			cmd := &registeredCommand{CommandSpec: spec, pluginName: pluginName}
+			if spec.Permission != "" {
+				prog, err := expr.Compile(spec.Permission, expr.Env(filter.Env{}))
+				if err != nil {
+					globals.AppLogger.Error("could not compile command permission, nobody may use the command", "plugin", pluginName, "command", spec.Name, "error", err)
+					prog, _ = expr.Compile("false")
+				}
+				cmd.permission = prog
+			}
+			commands[spec.Name] = cmd
+		}
+	}
+	return commands
+}
+
+// commandOwner returns the name of the plugin which registered the command of the event, or the empty string if the
+// event is not a registered command.
+func (h *Hub) commandOwner(event *types.Event) string {
+	if event.Name != types.EventTypeCommand {
+		return ""
+	}
+	if cmd, ok := h.commands[event.Tags["command"]]; ok {
+		return cmd.pluginName
+	}
+	return ""
+}
+
+// allowed reports whether the sender of the event may use the command.
+func (h *Hub) allowed(cmd *registeredCommand, event *types.Event) bool {
+	return cmd.permission == nil || h.RunPluginFilterEvent(event, cmd.permission)
+}
+
+// prepareCommand looks up the command of the event, checks the permission of the sender and parses the arguments into
+// the tags "arg.<name>". The built-in /help command returns nil and no error.
+func (h *Hub) prepareCommand(event *types.Event) (*registeredCommand, error) {
+	name := event.Tags["command"]
+	if name == helpCommand {
+		return nil, nil
+	}
+	cmd, ok := h.commands[name]
+	if !ok {
+		return nil, &commandError{code: commandErrorUnknown, message: fmt.Sprintf("Unknown command %s, try %s.", name, helpCommand)}
+	}
+	if !h.allowed(cmd, event) {
+		return nil, &commandError{code: commandErrorPermission, message: fmt.Sprintf("You are not allowed to use %s.", name)}
+	}
+	text := strings.TrimSpace(event.Tags["message"])
+	if strings.HasPrefix(text, name) {
+		text = text[len(name):]
+	} else {
+		text = event.Tags["args"]
+	}
+	args, err := cmd.ParseArgs(text)
+	if err != nil {
+		return nil, &commandError{code: commandErrorArgs, message: fmt.Sprintf("%s: %s. Usage: %s", name, err, cmd.Usage())}
+	}
+	for argName, value := range args {
+		event.Tags["arg."+argName] = value
+	}
+	return cmd, nil
+}
+
+// helpText lists the commands the sender of the event may use.
+func (h *Hub) helpText(event *types.Event) string {
+	names := make([]string, 0, len(h.commands))
+	for name, cmd := range h.commands {
+		if h.allowed(cmd, event) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("### Commands ###\n -> " + helpCommand + " - show this help")
+	for _, name := range names {
+		cmd := h.commands[name]
+		sb.WriteString("\n -> " + cmd.Usage())
+		if cmd.Description != "" {
+			sb.WriteString(" - " + cmd.Description)
+		}
+	}
+	return sb.String()
+}
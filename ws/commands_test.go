@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func commandEvent(user *types.User, message string) *types.Event {
+	tags := map[string]string{"message": message, "command": strings.Fields(message)[0]}
+	return types.NewEvent(&types.Room{Id: "room"}, &types.Source{User: user}, "", "en", types.EventTypeCommand, tags)
+}
+
+func TestCommands(t *testing.T) {
+	to := plugins.CommandSpec{
+		Name:        "/to",
+		Description: "send a private message",
+		Args:        []plugins.CommandArg{{Name: "nick"}, {Name: "message", Type: plugins.ArgTypeText}},
+	}
+	ban := plugins.CommandSpec{
+		Name:       "/ban",
+		Permission: `Source.User.Tags["moderator"] == "true"`,
+		Args:       []plugins.CommandArg{{Name: "nick"}, {Name: "minutes", Type: plugins.ArgTypeInt, Optional: true}},
+	}
+	pluginMap := map[string]plugins.PluginSpec{
+		"base":   {Name: "base", Priority: 2, Commands: []plugins.CommandSpec{to}},
+		"mod":    {Name: "mod", Priority: 1, Commands: []plugins.CommandSpec{ban, {Name: "/to"}, {Name: "/help"}}},
+		"filter": {Name: "filter", Kind: plugins.KindInterceptor, Commands: []plugins.CommandSpec{{Name: "/filter"}}},
+	}
+	h := newTestHub(pluginMap, nil)
+	assert.Len(t, h.commands, 2, "duplicates, /help and commands of interceptors are ignored")
+	assert.Equal(t, "base", h.commands["/to"].pluginName)
+
+	user := &types.User{Id: "u1", Nick: "u1", Tags: map[string]string{}}
+	moderator := &types.User{Id: "m1", Nick: "m1", Tags: map[string]string{"moderator": "true"}}
+
+	event := commandEvent(user, "/to bob  hello  world")
+	cmd, err := h.prepareCommand(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "base", cmd.pluginName)
+	assert.Equal(t, "bob", event.Tags["arg.nick"])
+	assert.Equal(t, "hello  world", event.Tags["arg.message"])
+	assert.Equal(t, "base", h.commandOwner(event))
+
+	_, err = h.prepareCommand(commandEvent(user, "/to bob"))
+	if assert.IsType(t, &commandError{}, err) {
+		assert.Equal(t, commandErrorArgs, err.(*commandError).code)
+		assert.Contains(t, err.Error(), "/to <nick> <message...>")
+	}
+	_, err = h.prepareCommand(commandEvent(user, "/unknown"))
+	if assert.IsType(t, &commandError{}, err) {
+		assert.Equal(t, commandErrorUnknown, err.(*commandError).code)
+	}
+	_, err = h.prepareCommand(commandEvent(user, "/ban bob"))
+	if assert.IsType(t, &commandError{}, err) {
+		assert.Equal(t, commandErrorPermission, err.(*commandError).code)
+	}
+	event = commandEvent(moderator, "/ban bob 10")
+	cmd, err = h.prepareCommand(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "mod", cmd.pluginName)
+	assert.Equal(t, "10", event.Tags["arg.minutes"])
+	_, err = h.prepareCommand(commandEvent(moderator, "/ban bob ten"))
+	assert.Error(t, err)
+
+	cmd, err = h.prepareCommand(commandEvent(user, "/help"))
+	assert.NoError(t, err)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "### Commands ###\n -> /help - show this help\n -> /to <nick> <message...> - send a private message", h.helpText(commandEvent(user, "/help")))
+	assert.Contains(t, h.helpText(commandEvent(moderator, "/help")), "/ban <nick> [minutes]")
+
+	assert.Equal(t, "", h.commandOwner(commandEvent(user, "/unknown")))
+	assert.Equal(t, "", h.commandOwner(types.NewEvent(nil, nil, "", "en", types.EventTypeChat, map[string]string{"command": "/to"})))
+}
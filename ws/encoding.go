@@ -0,0 +1,208 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/proto"
+	"github.com/tcriess/lightspeed-chat/types"
+	"google.golang.org/protobuf/encoding/protowire"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	// EncodingJSON is the default encoding: JSON text messages.
+	EncodingJSON = "json"
+	// EncodingProtobuf sends binary messages, each containing one protobuf proto.WireMessage (protocol v1 only).
+	EncodingProtobuf = "protobuf"
+
+	// encodingSeparator separates the protocol version and the encoding in a websocket subprotocol.
+	encodingSeparator = "+"
+
+	// wire numbers of the fields of proto.WireMessage and proto.WireBatch, which are encoded by hand to reuse the
+	// serialized events
+	wireMessageIdField    = 1
+	wireMessageBatchField = 2
+	wireBatchEventsField  = 1
+)
+
+// Encodings are the supported encodings.
+var Encodings = []string{EncodingJSON, EncodingProtobuf}
+
+// Frame is a message for the websocket connection: JSON text, or a protobuf encoded proto.WireMessage if Binary is set.
+type Frame struct {
+	Binary bool
+	Data   []byte
+}
+
+// EncodingFromSubprotocol returns the encoding of the negotiated websocket subprotocol, EncodingJSON if none was
+// negotiated.
+func EncodingFromSubprotocol(subprotocol string) string {
+	if !strings.HasPrefix(subprotocol, subprotocolPrefix) {
+		return EncodingJSON
+	}
+	parts := strings.SplitN(subprotocol, encodingSeparator, 2)
+	if len(parts) < 2 || parts[1] != EncodingProtobuf || ProtocolFromSubprotocol(subprotocol) < ProtocolV1 {
+		return EncodingJSON
+	}
+	return EncodingProtobuf
+}
+
+// Encoding returns the encoding of the messages sent to the client.
+func (c *Client) Encoding() string {
+	if atomic.LoadInt32(&c.binary) != 0 {
+		return EncodingProtobuf
+	}
+	return EncodingJSON
+}
+
+// setEncoding sets the encoding of the messages sent to the client. The protobuf encoding requires protocol v1 and a
+// transport supporting binary messages, any other (or unknown) encoding results in EncodingJSON.
+func (c *Client) setEncoding(encoding string) {
+	var binary int32
+	if encoding == EncodingProtobuf && c.Protocol() >= ProtocolV1 && !c.eventStream() {
+		binary = 1
+	}
+	atomic.StoreInt32(&c.binary, binary)
+}
+
+// encodedEvent contains the serializations of an event, each of them is created once and shared by all clients
+// receiving the event.
+type encodedEvent struct {
+	event *types.Event
+
+	jsonOnce  sync.Once
+	jsonData  []byte
+	protoOnce sync.Once
+	protoData []byte
+	batchOnce sync.Once
+	batchData []byte
+}
+
+// JSON returns the event as a JSON websocket message (see types.WireEvent), nil if it cannot be serialized.
+func (e *encodedEvent) JSON() []byte {
+	e.jsonOnce.Do(func() {
+		data, err := json.Marshal(types.WireEvent{Event: e.event})
+		if err != nil {
+			globals.AppLogger.Error("could not marshal event", "error", err)
+			return
+		}
+		e.jsonData = data
+	})
+	return e.jsonData
+}
+
+// Proto returns the event as a serialized proto.Event without the target filter, nil if it cannot be serialized.
+func (e *encodedEvent) Proto() []byte {
+	e.protoOnce.Do(func() {
+		event := plugins.EventToProto(e.event)
+		event.TargetFilter = ""
+		data, err := protobuf.Marshal(event)
+		if err != nil {
+			globals.AppLogger.Error("could not marshal event", "error", err)
+			return
+		}
+		e.protoData = data
+	})
+	return e.protoData
+}
+
+// Frame returns the websocket message sending the event alone in the encoding.
+func (e *encodedEvent) Frame(encoding string) Frame {
+	if encoding != EncodingProtobuf {
+		return Frame{Data: e.JSON()}
+	}
+	e.batchOnce.Do(func() {
+		e.batchData = protoBatch("", []*encodedEvent{e})
+	})
+	return Frame{Binary: true, Data: e.batchData}
+}
+
+// protoBatch returns the serialized proto.WireMessage containing the batch of the events. The message is assembled
+// from the serialized events instead of marshalling the proto.WireMessage, so that each event is serialized only once.
+func protoBatch(requestId string, events []*encodedEvent) []byte {
+	batch := make([]byte, 0)
+	for _, e := range events {
+		data := e.Proto()
+		if data == nil {
+			continue
+		}
+		batch = protowire.AppendTag(batch, wireBatchEventsField, protowire.BytesType)
+		batch = protowire.AppendBytes(batch, data)
+	}
+	msg := make([]byte, 0, len(batch)+len(requestId)+16)
+	if requestId != "" {
+		msg = protowire.AppendTag(msg, wireMessageIdField, protowire.BytesType)
+		msg = protowire.AppendString(msg, requestId)
+	}
+	msg = protowire.AppendTag(msg, wireMessageBatchField, protowire.BytesType)
+	return protowire.AppendBytes(msg, batch)
+}
+
+// wireMessage returns the protobuf representation of a message of the server (which is not an event), nil if there is
+// none.
+func wireMessage(requestId string, data interface{}) *proto.WireMessage {
+	msg := &proto.WireMessage{Id: requestId}
+	switch d := data.(type) {
+	case types.HelloMessage:
+		versions := make([]int32, 0, len(d.Versions))
+		for _, v := range d.Versions {
+			versions = append(versions, int32(v))
+		}
+		msg.Message = &proto.WireMessage_Hello{Hello: &proto.WireHello{Version: int32(d.Version), Versions: versions, Encoding: d.Encoding, Encodings: d.Encodings}}
+	case types.ErrorMessage:
+		msg.Message = &proto.WireMessage_Error{Error: &proto.WireError{Code: d.Code, Message: d.Message}}
+	case types.AckMessage:
+		msg.Message = &proto.WireMessage_Ack{Ack: &proto.WireAck{EventId: d.EventId, Seq: d.Seq}}
+	case types.SessionMessage:
+		msg.Message = &proto.WireMessage_Session{Session: &proto.WireSession{Token: d.Token, ExpiresNs: d.Expires.UnixNano()}}
+	case types.GapMessage:
+		msg.Message = &proto.WireMessage_Gap{Gap: &proto.WireGap{LastSeq: d.LastSeq, Seq: d.Seq}}
+	default:
+		return nil
+	}
+	return msg
+}
+
+// encodingCache keeps the serializations of the recently sent events. It holds up to two generations of size events,
+// when the current generation is full, it replaces the previous one.
+type encodingCache struct {
+	sync.Mutex
+	size     int
+	current  map[*types.Event]*encodedEvent
+	previous map[*types.Event]*encodedEvent
+}
+
+func newEncodingCache(size int) *encodingCache {
+	if size < 1 {
+		size = 1
+	}
+	return &encodingCache{
+		size:     size,
+		current:  make(map[*types.Event]*encodedEvent),
+		previous: make(map[*types.Event]*encodedEvent),
+	}
+}
+
+// get returns the (possibly not yet serialized) encodedEvent of the event.
+func (c *encodingCache) get(event *types.Event) *encodedEvent {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.current[event]; ok {
+		return e
+	}
+	e, ok := c.previous[event]
+	if !ok {
+		e = &encodedEvent{event: event}
+	}
+	if len(c.current) >= c.size {
+		c.previous = c.current
+		c.current = make(map[*types.Event]*encodedEvent)
+	}
+	c.current[event] = e
+	return e
+}
@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/proto"
+	"github.com/tcriess/lightspeed-chat/types"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+func TestEncodingFromSubprotocol(t *testing.T) {
+	assert.Equal(t, EncodingProtobuf, EncodingFromSubprotocol("lightspeed-chat.v1+protobuf"))
+	assert.Equal(t, EncodingJSON, EncodingFromSubprotocol("lightspeed-chat.v1"))
+	assert.Equal(t, EncodingJSON, EncodingFromSubprotocol("lightspeed-chat.v0+protobuf"), "protobuf requires protocol v1")
+	assert.Equal(t, EncodingJSON, EncodingFromSubprotocol("lightspeed-chat.v1+xml"))
+	assert.Equal(t, EncodingJSON, EncodingFromSubprotocol(""))
+
+	c := newProtocolTestClient(ProtocolV0)
+	c.hello("", json.RawMessage(`{"versions":[0],"encoding":"protobuf"}`))
+	assert.Equal(t, EncodingJSON, c.Encoding())
+	frame := <-c.Send
+	assert.False(t, frame.Binary)
+
+	c.hello("", json.RawMessage(`{"versions":[0,1],"encoding":"protobuf"}`))
+	assert.Equal(t, EncodingProtobuf, c.Encoding())
+	frame = <-c.Send
+	assert.True(t, frame.Binary, "the answer uses the new encoding")
+	msg := &proto.WireMessage{}
+	assert.NoError(t, protobuf.Unmarshal(frame.Data, msg))
+	assert.Equal(t, EncodingProtobuf, msg.GetHello().GetEncoding())
+	assert.Equal(t, []int32{0, 1}, msg.GetHello().GetVersions())
+
+	c.hello("", json.RawMessage(`{"versions":[0,1]}`))
+	assert.Equal(t, EncodingProtobuf, c.Encoding(), "the encoding is kept")
+}
+
+func TestProtobufEncoding(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	events := []*types.Event{
+		types.NewEvent(room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "one"}),
+		types.NewEvent(room, nil, `Target.User.Id == "bob"`, "en", types.EventTypeChat, map[string]string{"message": "private"}),
+		types.NewEvent(room, nil, `Target.User.Id == "alice"`, "en", types.EventTypeUser, nil),
+	}
+	events[0].Seq = 1
+
+	c := newProtocolTestClient(ProtocolV1)
+	c.setEncoding(EncodingProtobuf)
+	frames := c.encodeEvents(events)
+	if assert.Len(t, frames, 1) {
+		assert.True(t, frames[0].Binary)
+		msg := &proto.WireMessage{}
+		assert.NoError(t, protobuf.Unmarshal(frames[0].Data, msg))
+		batch := msg.GetBatch().GetEvents()
+		if assert.Len(t, batch, 2) {
+			assert.Equal(t, events[0].Id, batch[0].Id)
+			assert.Equal(t, uint64(1), batch[0].Seq)
+			assert.Equal(t, "one", batch[0].Tags["message"])
+			assert.Equal(t, events[0].Created.UnixNano(), batch[0].CreatedNs)
+			assert.Equal(t, types.EventTypeUser, batch[1].Name)
+			assert.Empty(t, batch[1].TargetFilter, "target filters are not sent")
+		}
+	}
+
+	c.sendError("1", errorLimit, "slow down")
+	frame := <-c.Send
+	assert.True(t, frame.Binary)
+	msg := &proto.WireMessage{}
+	assert.NoError(t, protobuf.Unmarshal(frame.Data, msg))
+	assert.Equal(t, "1", msg.Id)
+	assert.Equal(t, errorLimit, msg.GetError().GetCode())
+
+	batch := protoBatch("2", []*encodedEvent{c.hub.encoded.get(events[0])})
+	msg = &proto.WireMessage{}
+	assert.NoError(t, protobuf.Unmarshal(batch, msg))
+	assert.Equal(t, "2", msg.Id)
+	assert.Len(t, msg.GetBatch().GetEvents(), 1)
+}
+
+func TestEncodingCache(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	events := make([]*types.Event, 0)
+	for i := 0; i < 4; i++ {
+		events = append(events, types.NewEvent(room, nil, "", "en", types.EventTypeChat, nil))
+	}
+	cache := newEncodingCache(2)
+	first := cache.get(events[0])
+	assert.Same(t, first, cache.get(events[0]))
+	first.JSON()
+	cache.get(events[1])
+	cache.get(events[2]) // events[0] and events[1] are the previous generation now
+	assert.Same(t, first, cache.get(events[0]))
+	cache.get(events[3])
+	cache.get(events[1])
+	cache.get(events[2])
+	assert.NotSame(t, first, cache.get(events[0]))
+}
+
+func BenchmarkEncodeEvents(b *testing.B) {
+	room := &types.Room{Id: "room", Owner: &types.User{Id: "owner"}}
+	source := &types.Source{User: &types.User{Id: "alice", Nick: "Alice", Language: "en"}, Role: types.RoleMember}
+	events := make([]*types.Event, 0, 100)
+	for i := 0; i < 100; i++ {
+		tags := map[string]string{"message": fmt.Sprintf("message number %d of the benchmark", i), "mime_type": "text/plain"}
+		event := types.NewEvent(room, source, "", "en", types.EventTypeChat, tags)
+		event.Seq = uint64(i + 1)
+		events = append(events, event)
+	}
+	// the debug output would dominate the benchmark
+	globals.AppLogger.SetLevel(hclog.Warn)
+	defer globals.AppLogger.SetLevel(hclog.Debug)
+	for _, encoding := range Encodings {
+		b.Run(encoding, func(b *testing.B) {
+			c := newProtocolTestClient(ProtocolV1)
+			c.setEncoding(encoding)
+			size := 0
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.hub.encoded = newEncodingCache(len(events))
+				size = 0
+				for _, frame := range c.encodeEvents(events) {
+					size += len(frame.Data)
+				}
+			}
+			b.ReportMetric(float64(size), "bytes/batch")
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package ws
 
 import (
-	"github.com/tcriess/lightspeed-chat/auth"
+	"context"
+	"fmt"
+
 	"github.com/tcriess/lightspeed-chat/globals"
 	"github.com/tcriess/lightspeed-chat/types"
 )
@@ -12,7 +14,7 @@ type emitEventsHelper struct {
 }
 
 // Here, we receive the events that were emitted by the plugins
-func (eh *emitEventsHelper) EmitEvents(events []*types.Event) error {
+func (eh *emitEventsHelper) EmitEvents(ctx context.Context, events []*types.Event) error {
 	keepEvents := events[:0]
 	for _, event := range events {
 		if event.Name == types.EventTypeInternal {
@@ -26,14 +28,7 @@ func (eh *emitEventsHelper) EmitEvents(events []*types.Event) error {
 	for i := len(keepEvents); i < len(events); i++ {
 		events[i] = nil
 	}
-	skipPlugins := make(map[string]struct{})
-	skipPlugins[eh.pluginName] = struct{}{}
-	err := eh.hub.handlePlugins(keepEvents, skipPlugins)
-	if err != nil {
-		globals.AppLogger.Error("could not handle events", "error", err)
-		return err
-	}
-	err = eh.hub.handleEvents(keepEvents)
+	err := eh.hub.publishEvents(ctx, keepEvents, []string{eh.pluginName})
 	if err != nil {
 		globals.AppLogger.Error("could not handle events", "error", err)
 		return err
@@ -45,14 +40,17 @@ func (eh *emitEventsHelper) handleInternalEvent(event *types.Event) {
 	// TODO: for future use, currently no internal events are processed
 }
 
-func (eh *emitEventsHelper) AuthenticateUser(idToken string, provider string) (*types.User, error) {
+func (eh *emitEventsHelper) AuthenticateUser(ctx context.Context, idToken string, provider string) (*types.User, error) {
 	// TODO: possibly allow for new users to be accepted here
-	userId, err := auth.Authenticate(idToken, provider, eh.hub.Cfg)
+	identity, err := eh.hub.Auth.Authenticate(ctx, idToken, provider)
 	if err != nil {
 		return nil, err
 	}
-	user := &types.User{Id: userId}
-	if userId != "" && eh.hub.Persister != nil {
+	user := &types.User{}
+	if identity != nil {
+		user.Id = identity.UserId
+	}
+	if user.Id != "" && eh.hub.Persister != nil {
 		err := eh.hub.Persister.GetUser(user)
 		if err != nil {
 			return nil, err
@@ -61,7 +59,11 @@ func (eh *emitEventsHelper) AuthenticateUser(idToken string, provider string) (*
 	return user, nil
 }
 
-func (eh *emitEventsHelper) GetUser(userId string) (*types.User, error) {
+func (eh *emitEventsHelper) GetUser(ctx context.Context, userId string) (*types.User, error) {
+	// guests are not persisted, they are only known while they are connected
+	if guest := eh.hub.connectedGuest(userId); guest != nil {
+		return guest, nil
+	}
 	user := &types.User{Id: userId}
 	if eh.hub.Persister != nil {
 		err := eh.hub.Persister.GetUser(user)
@@ -72,7 +74,7 @@ func (eh *emitEventsHelper) GetUser(userId string) (*types.User, error) {
 	return user, nil
 }
 
-func (eh *emitEventsHelper) GetRoom(roomId string) (*types.Room, error) {
+func (eh *emitEventsHelper) GetRoom(ctx context.Context, roomId string) (*types.Room, error) {
 	room := &types.Room{Id: roomId}
 	if eh.hub.Persister != nil {
 		err := eh.hub.Persister.GetRoom(room)
@@ -83,7 +85,12 @@ func (eh *emitEventsHelper) GetRoom(roomId string) (*types.Room, error) {
 	return room, nil
 }
 
-func (eh *emitEventsHelper) ChangeUserTags(userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
+// ChangeUserTags changes the tags of the user on behalf of the acting user, who needs the permission to moderate in the
+// room. An empty actorId skips the check (the change is made by the plugin itself).
+func (eh *emitEventsHelper) ChangeUserTags(ctx context.Context, actorId, userId string, updates []*types.TagUpdate) (*types.User, []bool, error) {
+	if actorId != "" && !eh.hub.allows(eh.hub.userRole(actorId), types.PermissionModerate) {
+		return nil, nil, fmt.Errorf("user %s is not allowed to change user tags", actorId)
+	}
 	resOk := make([]bool, len(updates))
 	user := &types.User{Id: userId}
 	if eh.hub.Persister != nil {
@@ -96,7 +103,16 @@ func (eh *emitEventsHelper) ChangeUserTags(userId string, updates []*types.TagUp
 	return user, resOk, nil
 }
 
-func (eh *emitEventsHelper) ChangeRoomTags(roomId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
+// ChangeRoomTags changes the tags of the room on behalf of the acting user, who needs the permission to manage
+// plugins. An empty actorId skips the check (the change is made by the plugin itself). The changed tags apply to the
+// room right away.
+func (eh *emitEventsHelper) ChangeRoomTags(ctx context.Context, roomId, actorId string, updates []*types.TagUpdate) (*types.Room, []bool, error) {
+	if roomId != eh.hub.Room.Id {
+		return nil, nil, fmt.Errorf("unknown room %s", roomId)
+	}
+	if actorId != "" && !eh.hub.allows(eh.hub.userRole(actorId), types.PermissionManagePlugins) {
+		return nil, nil, fmt.Errorf("user %s is not allowed to change room tags", actorId)
+	}
 	resOk := make([]bool, len(updates))
 	room := &types.Room{Id: roomId}
 	if eh.hub.Persister != nil {
@@ -105,6 +121,28 @@ func (eh *emitEventsHelper) ChangeRoomTags(roomId string, updates []*types.TagUp
 		if err != nil {
 			return nil, nil, err
 		}
+		eh.hub.setRoomTags(room.Tags)
 	}
 	return room, resOk, nil
 }
+
+func (eh *emitEventsHelper) GetRoomLanguages(ctx context.Context, roomId string) ([]string, error) {
+	if roomId != eh.hub.Room.Id {
+		return nil, fmt.Errorf("unknown room %s", roomId)
+	}
+	return eh.hub.GetLanguages(), nil
+}
+
+func (eh *emitEventsHelper) GetUserRole(ctx context.Context, roomId, userId string) (string, error) {
+	if roomId != eh.hub.Room.Id {
+		return "", fmt.Errorf("unknown room %s", roomId)
+	}
+	return eh.hub.userRole(userId), nil
+}
+
+func (eh *emitEventsHelper) SetUserRole(ctx context.Context, roomId, actorId, userId, role string) error {
+	if roomId != eh.hub.Room.Id {
+		return fmt.Errorf("unknown room %s", roomId)
+	}
+	return eh.hub.ChangeRole(actorId, userId, role)
+}
@@ -37,8 +37,9 @@ func (c *Client) RunFilterEvent(event *types.Event, prog *vm.Program) bool {
 				Language:   c.hub.Room.Owner.Language,
 				Tags:       c.hub.Room.Owner.Tags,
 				LastOnline: c.hub.Room.Owner.LastOnline.Unix(),
+				IsGuest:    c.hub.Room.Owner.IsGuest,
 			},
-			Tags: c.hub.Room.Tags,
+			Tags: c.hub.roomTags(),
 		},
 		Source: filter.Source{
 			User: filter.User{
@@ -47,8 +48,11 @@ func (c *Client) RunFilterEvent(event *types.Event, prog *vm.Program) bool {
 				Language:   event.Source.User.Language,
 				Tags:       event.Source.User.Tags,
 				LastOnline: event.Source.User.LastOnline.Unix(),
+				IsGuest:    event.Source.User.IsGuest,
 			},
 			PluginName: event.Source.PluginName,
+			Role:       event.Source.Role,
+			Bot:        event.Source.Bot,
 		},
 		Target: filter.Target{
 			User: filter.User{
@@ -57,10 +61,12 @@ func (c *Client) RunFilterEvent(event *types.Event, prog *vm.Program) bool {
 				Language:   c.user.Language,
 				Tags:       c.user.Tags,
 				LastOnline: c.user.LastOnline.Unix(),
+				IsGuest:    c.user.IsGuest,
 			},
 			Client: filter.Client{
 				ClientLanguage: c.Language,
 			},
+			Role: c.Role(),
 		},
 		Created:       event.Created.Unix(),
 		Language:      event.Language,
@@ -87,7 +93,7 @@ func (c *Client) RunFilterEvent(event *types.Event, prog *vm.Program) bool {
 }
 
 func (h *Hub) EvaluatePluginFilterEvent(event *types.Event, pluginFilter string) bool {
-	if event.TargetFilter == "" {
+	if pluginFilter == "" {
 		return true
 	}
 	prog, err := expr.Compile(pluginFilter, expr.Env(filter.Env{}))
@@ -105,42 +111,7 @@ func (h *Hub) RunPluginFilterEvent(event *types.Event, prog *vm.Program) bool {
 	if prog == nil {
 		return true
 	}
-	env := filter.Env{
-		Room: filter.Room{
-			Id: h.Room.Id,
-			Owner: filter.User{
-				Id:         h.Room.Owner.Id,
-				Nick:       h.Room.Owner.Nick,
-				Language:   h.Room.Owner.Language,
-				Tags:       h.Room.Owner.Tags,
-				LastOnline: h.Room.Owner.LastOnline.Unix(),
-			},
-			Tags: h.Room.Tags,
-		},
-		Source: filter.Source{
-			User: filter.User{
-				Id:         event.Source.User.Id,
-				Nick:       event.Source.User.Nick,
-				Language:   event.Source.User.Language,
-				Tags:       event.Source.User.Tags,
-				LastOnline: event.Source.User.LastOnline.Unix(),
-			},
-			PluginName: event.Source.PluginName,
-		},
-		Target: filter.Target{
-			User:   filter.User{},
-			Client: filter.Client{},
-		},
-		Created:       event.Created.Unix(),
-		Language:      event.Language,
-		Name:          event.Name,
-		Tags:          event.Tags,
-		AsInt:         filter.AsInt,
-		AsFloat:       filter.AsFloat,
-		AsStringSlice: filter.AsStringSlice,
-		AsIntSlice:    filter.AsIntSlice,
-		AsFloatSlice:  filter.AsFloatSlice,
-	}
+	env := filter.PluginEnv(h.Room, event)
 
 	res, err := expr.Run(prog, env)
 	if err != nil {
@@ -0,0 +1,123 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/folkengine/goname"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	guestIdPrefix   = "guest-"
+	guestNickSuffix = " (guest)"
+)
+
+// guestCookieData is the guest identity stored in the guest cookie.
+type guestCookieData struct {
+	Id   string `json:"id"`
+	Nick string `json:"nick"`
+}
+
+// NewGuest returns a guest user with a random id and a generated nick.
+func NewGuest() *types.User {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		globals.AppLogger.Error("could not generate guest id", "error", err)
+	}
+	return &types.User{
+		Id:      guestIdPrefix + hex.EncodeToString(id),
+		Nick:    goname.New(goname.FantasyMap).FirstLast() + guestNickSuffix,
+		Tags:    make(map[string]string),
+		IsGuest: true,
+	}
+}
+
+// GuestFromRequest returns the guest stored in the guest cookie of the request, or a new guest if there is no valid
+// cookie.
+func GuestFromRequest(r *http.Request, cfg *config.Config) *types.User {
+	cookie, err := r.Cookie(guestCookieName(cfg))
+	if err != nil {
+		return NewGuest()
+	}
+	guest := parseGuestCookie(cookie.Value, guestSecret(cfg))
+	if guest == nil {
+		globals.AppLogger.Debug("ignoring invalid guest cookie")
+		return NewGuest()
+	}
+	return guest
+}
+
+// GuestCookie returns the cookie which lets the guest resume its identity on the next connection.
+func GuestCookie(guest *types.User, cfg *config.Config, secure bool) *http.Cookie {
+	maxAge := 0
+	if cfg != nil {
+		maxAge = int(cfg.GuestConfig.CookieMaxAge / time.Second)
+	}
+	return &http.Cookie{
+		Name:     guestCookieName(cfg),
+		Value:    guestCookieValue(guest, guestSecret(cfg)),
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func guestCookieName(cfg *config.Config) string {
+	if cfg == nil || cfg.GuestConfig.CookieName == "" {
+		return "lightspeed_guest"
+	}
+	return cfg.GuestConfig.CookieName
+}
+
+// guestSecret returns the configured secret for signing guest cookies, or a random secret which is valid until the
+// process exits.
+func guestSecret(cfg *config.Config) []byte {
+	if cfg != nil && cfg.GuestConfig.Secret != "" {
+		return []byte(cfg.GuestConfig.Secret)
+	}
+	return randomSecret()
+}
+
+// guestCookieValue encodes the guest identity as a signed value (see encodeSigned).
+func guestCookieValue(guest *types.User, secret []byte) string {
+	return encodeSigned(guestCookieData{Id: guest.Id, Nick: guest.Nick}, secret)
+}
+
+// parseGuestCookie returns the guest encoded in value, or nil if value is malformed or the signature does not match.
+func parseGuestCookie(value string, secret []byte) *types.User {
+	data := guestCookieData{}
+	if !decodeSigned(value, secret, &data) {
+		return nil
+	}
+	if !strings.HasPrefix(data.Id, guestIdPrefix) || data.Nick == "" || !utf8.ValidString(data.Nick) {
+		return nil
+	}
+	return &types.User{
+		Id:      data.Id,
+		Nick:    data.Nick,
+		Tags:    make(map[string]string),
+		IsGuest: true,
+	}
+}
+
+// connectedGuest returns a copy of the guest user with the given id if it is connected to the room, or nil.
+func (h *Hub) connectedGuest(userId string) *types.User {
+	h.RLock()
+	defer h.RUnlock()
+	for c := range h.clients {
+		if c.user.IsGuest && c.user.Id == userId {
+			guest := *c.user
+			return &guest
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/filter"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestGuestCookie(t *testing.T) {
+	cfg := &config.Config{GuestConfig: config.GuestConfig{Secret: "secret", CookieName: "guest", CookieMaxAge: time.Hour}}
+	guest := NewGuest()
+	assert.True(t, guest.IsGuest)
+	assert.True(t, strings.HasPrefix(guest.Id, guestIdPrefix))
+	assert.NotEqual(t, guest.Id, NewGuest().Id)
+
+	cookie := GuestCookie(guest, cfg, true)
+	assert.Equal(t, "guest", cookie.Name)
+	assert.Equal(t, 3600, cookie.MaxAge)
+	r := httptest.NewRequest("GET", "/ws", nil)
+	r.AddCookie(cookie)
+	resumed := GuestFromRequest(r, cfg)
+	assert.Equal(t, guest.Id, resumed.Id)
+	assert.Equal(t, guest.Nick, resumed.Nick)
+	assert.True(t, resumed.IsGuest)
+
+	other := &config.Config{GuestConfig: config.GuestConfig{Secret: "other", CookieName: "guest"}}
+	assert.NotEqual(t, guest.Id, GuestFromRequest(r, other).Id, "the signature must match the secret")
+
+	forged := guestCookieValue(&types.User{Id: "admin", Nick: "admin"}, []byte("secret"))
+	assert.Nil(t, parseGuestCookie(forged, []byte("secret")), "only guest ids are accepted")
+	assert.Nil(t, parseGuestCookie("garbage", []byte("secret")))
+	assert.Nil(t, parseGuestCookie(cookie.Value+"x", []byte("secret")))
+}
+
+func TestLimits(t *testing.T) {
+	limiter := &rateLimiter{}
+	limits := config.LimitsConfig{Messages: 2, Interval: time.Minute}
+	now := time.Now()
+	assert.True(t, limiter.allow(limits, now))
+	assert.True(t, limiter.allow(limits, now.Add(time.Second)))
+	assert.False(t, limiter.allow(limits, now.Add(2*time.Second)))
+	assert.True(t, limiter.allow(limits, now.Add(time.Minute+time.Second)), "the first message left the window")
+	assert.True(t, limiter.allow(config.LimitsConfig{}, now), "no limits")
+
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &config.Config{
+		Limits:      config.LimitsConfig{Messages: 3, Interval: time.Minute},
+		GuestConfig: config.GuestConfig{Limits: config.LimitsConfig{Messages: 1, Interval: time.Minute, MaxMessageLength: 5}},
+	}
+	guest := &Client{hub: h, user: NewGuest()}
+	assert.NotEqual(t, "", guest.checkLimits("too long"))
+	assert.Equal(t, "", guest.checkLimits("short"))
+	assert.NotEqual(t, "", guest.checkLimits("again"))
+
+	user := &Client{hub: h, user: &types.User{Id: "user"}, authenticated: true}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "", user.checkLimits("a message which is longer than the guest limit"))
+	}
+	assert.NotEqual(t, "", user.checkLimits("one more"))
+}
+
+func TestGuestFilter(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	event := types.NewEvent(room, &types.Source{User: NewGuest()}, "", "en", types.EventTypeChat, map[string]string{"message": "hi"})
+	res, err := expr.Eval(`!Source.User.IsGuest`, filter.PluginEnv(room, event))
+	assert.NoError(t, err)
+	assert.Equal(t, false, res)
+	event.Source.User = &types.User{Id: "user"}
+	res, err = expr.Eval(`!Source.User.IsGuest`, filter.PluginEnv(room, event))
+	assert.NoError(t, err)
+	assert.Equal(t, true, res)
+}
@@ -0,0 +1,153 @@
+package ws
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// historyTranslations keeps track of the translations of history messages which were requested on demand (see
+// Hub.translateHistory), so that each translation is requested only once at a time.
+type historyTranslations struct {
+	inFlight map[string]struct{} // source id + "\x00" + language, requested but not yet answered
+	sync.Mutex
+}
+
+func newHistoryTranslations() *historyTranslations {
+	return &historyTranslations{
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// reserve marks the translation of the event into language as requested, it returns false if it is requested already.
+func (ht *historyTranslations) reserve(sourceId, language string) bool {
+	ht.Lock()
+	defer ht.Unlock()
+	key := sourceId + "\x00" + language
+	if _, ok := ht.inFlight[key]; ok {
+		return false
+	}
+	ht.inFlight[key] = struct{}{}
+	return true
+}
+
+func (ht *historyTranslations) release(sourceId, language string) {
+	ht.Lock()
+	defer ht.Unlock()
+	delete(ht.inFlight, sourceId+"\x00"+language)
+}
+
+// missingTranslations returns the chat events of the history which are neither written in language nor have a
+// translation into language.
+func missingTranslations(history []*types.Event, language string) []*types.Event {
+	translated := make(map[string]struct{})
+	for _, event := range history {
+		if event.Name == types.EventTypeTranslation && strings.EqualFold(event.Language, language) {
+			translated[event.Tags["source_id"]] = struct{}{}
+		}
+	}
+	missing := make([]*types.Event, 0)
+	for _, event := range history {
+		if event.Name != types.EventTypeChat || event.Tags["message"] == "" {
+			continue
+		}
+		if len(event.Language) >= 2 && strings.EqualFold(event.Language[:2], language) {
+			continue
+		}
+		if _, ok := translated[event.Id]; ok {
+			continue
+		}
+		missing = append(missing, event)
+	}
+	return missing
+}
+
+// translateHistory asks the translation-capable plugins (the observers whose event filter accepts translation
+// requests) in one batched call per plugin for the missing translations of the history into language. The results are
+// handled like any other event (see handleEvents): they are numbered, added to the history, persisted and broadcast,
+// so that the next client using language finds them in the history. translateHistory returns the translations.
+func (h *Hub) translateHistory(ctx context.Context, history []*types.Event, language string) []*types.Event {
+	if len(language) < 2 {
+		return nil
+	}
+	language = strings.ToLower(language[:2])
+	requests := make([]*types.Event, 0)
+	requested := make(map[string]struct{})
+	reserved := make([]string, 0)
+	source := &types.Source{
+		PluginName: "main",
+		User:       &types.User{Id: "", Nick: "main", Tags: make(map[string]string)},
+	}
+	for _, event := range missingTranslations(history, language) {
+		if !h.historyTranslations.reserve(event.Id, language) {
+			continue
+		}
+		requested[event.Id] = struct{}{}
+		reserved = append(reserved, event.Id)
+		tags := map[string]string{
+			"source_id":       event.Id,
+			"message":         event.Tags["message"],
+			"target_language": language,
+		}
+		requests = append(requests, types.NewEvent(h.Room, source, event.TargetFilter, event.Language, types.EventTypeTranslationRequest, tags))
+	}
+	// the reservations are kept until the translations are in the history
+	defer func() {
+		for _, sourceId := range reserved {
+			h.historyTranslations.release(sourceId, language)
+		}
+	}()
+	if len(requests) == 0 {
+		return nil
+	}
+
+	translations := make([]*types.Event, 0, len(requests))
+	for _, pluginName := range h.pluginOrder {
+		if len(requested) == 0 {
+			break
+		}
+		plg := h.pluginMap[pluginName]
+		if plg.Kind != plugins.KindObserver {
+			continue
+		}
+		passEvents := make([]*types.Event, 0, len(requests))
+		for _, request := range requests {
+			if _, ok := requested[request.Tags["source_id"]]; !ok {
+				continue // answered by a previous plugin
+			}
+			if h.EvaluatePluginFilterEvent(request, plg.EventFilter) {
+				passEvents = append(passEvents, request)
+			}
+		}
+		if len(passEvents) == 0 {
+			continue
+		}
+		res, err := plg.HandleEvents(ctx, passEvents)
+		if err != nil {
+			globals.AppLogger.Error("could not translate history", "plugin", pluginName, "error", err)
+			continue
+		}
+		for _, event := range res {
+			sourceId := event.Tags["source_id"]
+			if event.Name != types.EventTypeTranslation || !strings.EqualFold(event.Language, language) {
+				continue
+			}
+			if _, ok := requested[sourceId]; !ok {
+				continue
+			}
+			delete(requested, sourceId)
+			translations = append(translations, event)
+		}
+	}
+	if len(translations) == 0 {
+		return nil
+	}
+	if err := h.handleEvents(translations); err != nil {
+		globals.AppLogger.Error("could not handle history translations", "error", err)
+	}
+	return translations
+}
@@ -0,0 +1,106 @@
+package ws
+
+import (
+	"container/ring"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// fakeTranslator answers translation requests with "<language>: <message>".
+type fakeTranslator struct {
+	plugins.EventHandler
+	sync.Mutex
+	calls    int
+	requests int
+}
+
+func (f *fakeTranslator) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	f.Lock()
+	f.calls++
+	f.requests += len(events)
+	f.Unlock()
+	res := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		tags := map[string]string{
+			"source_id": event.Tags["source_id"],
+			"message":   event.Tags["target_language"] + ": " + event.Tags["message"],
+		}
+		res = append(res, types.NewEvent(event.Room, nil, "", event.Tags["target_language"], types.EventTypeTranslation, tags))
+	}
+	return res, nil
+}
+
+func newTestHub(pluginMap map[string]plugins.PluginSpec, history []*types.Event) *Hub {
+	eventHistory := ring.New(len(history) + 1)
+	pluginOrder := plugins.SortPlugins(pluginMap)
+	h := &Hub{
+		Room:                &types.Room{Id: "room", Owner: &types.User{}},
+		clients:             make(map[*Client]struct{}),
+		eventHistoryStart:   eventHistory,
+		eventHistoryEnd:     eventHistory,
+		pluginMap:           pluginMap,
+		pluginOrder:         pluginOrder,
+		encoded:             newEncodingCache(len(history) + 1),
+		historyTranslations: newHistoryTranslations(),
+		commands:            newCommandRegistry(pluginMap, pluginOrder),
+		roles:               make(map[string]string),
+	}
+	for _, event := range history {
+		h.eventHistoryEnd.Value = event
+		h.eventHistoryEnd = h.eventHistoryEnd.Next()
+	}
+	return h
+}
+
+func TestTranslateHistory(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	german := types.NewEvent(room, nil, "", "de", types.EventTypeChat, map[string]string{"message": "hallo"})
+	english := types.NewEvent(room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "hello"})
+	englishDe := types.NewEvent(room, nil, "", "de", types.EventTypeTranslation, map[string]string{"message": "hallo", "source_id": english.Id})
+	history := []*types.Event{german, english, englishDe}
+
+	assert.Equal(t, []*types.Event{german, english}, missingTranslations(history, "fr"))
+	assert.Equal(t, []*types.Event{german}, missingTranslations(history, "en"))
+	assert.Empty(t, missingTranslations(history, "de"))
+
+	translator := &fakeTranslator{}
+	other := &fakeTranslator{}
+	pluginMap := map[string]plugins.PluginSpec{
+		"translator": {Name: "translator", Plugin: translator, EventFilter: `Name == "translation_request"`, Priority: 1},
+		"other":      {Name: "other", Plugin: other, EventFilter: `Name == "chat"`},
+	}
+	h := newTestHub(pluginMap, nil)
+	h.eventHistoryStart = ring.New(10)
+	h.eventHistoryEnd = h.eventHistoryStart
+	h.EventHistory = make(chan []*types.Event, 10)
+	h.BroadcastEvents = make(chan []*types.Event, 10)
+	assert.NoError(t, h.handleEvents(history))
+
+	res := h.translateHistory(context.Background(), h.GetHistory(), "fr-FR")
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, "fr: hallo", res[0].Tags["message"])
+		assert.Equal(t, german.Id, res[0].Tags["source_id"])
+		assert.Equal(t, "fr: hello", res[1].Tags["message"])
+		assert.Equal(t, []uint64{4, 5}, seqs(res), "the translations are numbered like any other event")
+	}
+	assert.Equal(t, 1, translator.calls, "all translations are requested in one call")
+	assert.Equal(t, 0, other.calls, "plugins which do not accept translation requests are not called")
+	<-h.EventHistory
+	assert.Equal(t, res, <-h.EventHistory, "the translations are persisted by the hub")
+	<-h.BroadcastEvents
+	assert.Equal(t, res, <-h.BroadcastEvents, "the translations are broadcast")
+
+	// the translations are in the history, there is no second request
+	assert.Empty(t, h.translateHistory(context.Background(), h.GetHistory(), "fr"))
+	assert.Equal(t, 1, translator.calls)
+
+	// another language only requests the missing ones
+	assert.Len(t, h.translateHistory(context.Background(), h.GetHistory(), "de"), 0)
+	assert.Len(t, h.translateHistory(context.Background(), h.GetHistory(), "en"), 1)
+	assert.Equal(t, 3, translator.requests)
+}
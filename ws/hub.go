@@ -2,35 +2,32 @@ package ws
 
 import (
 	"container/ring"
-	"encoding/json"
+	"context"
+	"database/sql"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/antonmedv/expr"
-	"github.com/antonmedv/expr/vm"
 	"github.com/robfig/cron/v3"
+	"github.com/tcriess/lightspeed-chat/auth"
 	"github.com/tcriess/lightspeed-chat/config"
-	"github.com/tcriess/lightspeed-chat/filter"
 	"github.com/tcriess/lightspeed-chat/globals"
 	"github.com/tcriess/lightspeed-chat/persistence"
 	"github.com/tcriess/lightspeed-chat/plugins"
 	"github.com/tcriess/lightspeed-chat/types"
+	"github.com/tidwall/buntdb"
+	"gorm.io/gorm"
 )
 
 const (
-	maxMessageSize          = 4096
-	pongWait                = 2 * time.Minute
-	pingPeriod              = time.Minute
-	writeWait               = 10 * time.Second
 	defaultEventHistorySize = 100
-	broadcastChannelSize    = 1000
-	historyChannelSize      = 1000
 )
 
 type Hub struct {
-	// there is one hub per room
+	// there is one hub per room, the tags of the room may be changed by plugins (see roomTags)
 	*types.Room
+	roomTagsLock sync.RWMutex
 
 	// Registered clients.
 	clients map[*Client]struct{}
@@ -44,44 +41,94 @@ type Hub struct {
 	// Unregister a client from the hub.
 	Unregister chan *Client
 
-	// keep the chat history in a ring buffer
+	// keep the chat history in a ring buffer, events sent to EventHistory are persisted
 	EventHistory                       chan []*types.Event
 	eventHistoryStart, eventHistoryEnd *ring.Ring
 	lockEventHistory                   sync.RWMutex
 
+	// sequence number of the last event added to the history, the events are numbered and added to the history under
+	// seqLock so that they are added in the order of their sequence numbers
+	seq     uint64
+	seqLock sync.Mutex
+
+	// serializes handling events, so that they are stored and broadcast in the order of their sequence numbers without
+	// holding seqLock during the (blocking) sends
+	handleLock sync.Mutex
+
 	// global configuration
 	Cfg *config.Config
 
+	// OIDC providers used to authenticate users
+	Auth *auth.Registry
+
 	// persistence
 	Persister persistence.Persister
 
 	// global plugins map
 	pluginMap map[string]plugins.PluginSpec
 
+	// plugin names ordered by priority
+	pluginOrder []string
+
+	// one worker (with its own queue) per observer plugin
+	pluginWorkers map[string]*pluginWorker
+
+	// serializations of the recently sent events, shared by the clients
+	encoded *encodingCache
+
+	// translations of history messages requested on demand
+	historyTranslations *historyTranslations
+
+	// commands registered by the plugins
+	commands map[string]*registeredCommand
+
+	// roles of the users of the room (user id -> role) which are not the default role
+	roles     map[string]string
+	rolesLock sync.RWMutex
+
+	// rate limiters of the bots (user id -> limiter), shared by the connections of a bot
+	botLimiters     map[string]*rateLimiter
+	botLimitersLock sync.Mutex
+
 	// mutex for manipulating the clients
 	sync.RWMutex
 }
 
-func NewHub(room *types.Room, cfg *config.Config, persister persistence.Persister, pluginMap map[string]plugins.PluginSpec) *Hub {
+func NewHub(room *types.Room, cfg *config.Config, authRegistry *auth.Registry, persister persistence.Persister, pluginMap map[string]plugins.PluginSpec) *Hub {
 	eventHistorySize := defaultEventHistorySize
 	if cfg.HistoryConfig.HistorySize > 0 {
 		eventHistorySize = cfg.HistoryConfig.HistorySize
 	}
 	eventHistory := ring.New(eventHistorySize)
+	pluginOrder := plugins.SortPlugins(pluginMap)
 	hub := &Hub{
-		Room:              room,
-		clients:           make(map[*Client]struct{}),
-		BroadcastEvents:   make(chan []*types.Event, broadcastChannelSize),
-		Register:          make(chan *Client),
-		Unregister:        make(chan *Client),
-		EventHistory:      make(chan []*types.Event, historyChannelSize),
-		eventHistoryStart: eventHistory,
-		eventHistoryEnd:   eventHistory,
-		Cfg:               cfg,
-		Persister:         persister,
-		pluginMap:         pluginMap,
+		Room:                room,
+		clients:             make(map[*Client]struct{}),
+		Register:            make(chan *Client),
+		Unregister:          make(chan *Client),
+		eventHistoryStart:   eventHistory,
+		eventHistoryEnd:     eventHistory,
+		Cfg:                 cfg,
+		Auth:                authRegistry,
+		Persister:           persister,
+		pluginMap:           pluginMap,
+		pluginOrder:         pluginOrder,
+		pluginWorkers:       make(map[string]*pluginWorker),
+		encoded:             newEncodingCache(eventHistorySize),
+		historyTranslations: newHistoryTranslations(),
+		commands:            newCommandRegistry(pluginMap, pluginOrder),
+		roles:               make(map[string]string),
 	}
+	wsCfg := hub.websocketConfig()
+	hub.BroadcastEvents = make(chan []*types.Event, wsCfg.BroadcastQueueSize)
+	hub.EventHistory = make(chan []*types.Event, wsCfg.HistoryQueueSize)
+	hub.loadRoles()
 	if persister != nil {
+		// events stored before sequence numbers were introduced are numbered once
+		err := persister.AssignEventSeqs(hub.Room)
+		if err != nil {
+			globals.AppLogger.Error("could not number persisted events", "error", err)
+		}
 		var t time.Time
 		n := time.Now().Add(time.Minute)
 		events, err := persister.GetEventHistory(hub.Room, t, n, 0, eventHistorySize)
@@ -89,15 +136,25 @@ func NewHub(room *types.Room, cfg *config.Config, persister persistence.Persiste
 			globals.AppLogger.Error("could not load persisted events", "error", err)
 		}
 		globals.AppLogger.Debug("loaded events", "events", events)
-		hub.lockEventHistory.Lock()
+		// the newest events come first
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+		hub.appendHistory(events)
+		hub.seq, err = persister.GetLastEventSeq(hub.Room)
+		if err != nil {
+			globals.AppLogger.Error("could not load the last event sequence number", "error", err)
+		}
 		for _, event := range events {
-			hub.eventHistoryEnd.Value = event
-			hub.eventHistoryEnd = hub.eventHistoryEnd.Next()
-			if hub.eventHistoryEnd == hub.eventHistoryStart {
-				hub.eventHistoryStart = hub.eventHistoryStart.Next()
+			if event.Seq > hub.seq {
+				hub.seq = event.Seq
 			}
 		}
-		hub.lockEventHistory.Unlock()
+	}
+	for pluginName, plg := range pluginMap {
+		if plg.Kind == plugins.KindObserver {
+			hub.pluginWorkers[pluginName] = newPluginWorker(hub, plg)
+		}
 	}
 	for pluginName, plg := range pluginMap {
 		eh := emitEventsHelper{
@@ -106,9 +163,9 @@ func NewHub(room *types.Room, cfg *config.Config, persister persistence.Persiste
 		}
 		go func(eeh emitEventsHelper, plg plugins.PluginSpec) {
 			for {
-				err := plg.Plugin.InitEmitEvents(hub.Room, &eeh) // never exits
+				err := plg.Plugin.InitEmitEvents(context.Background(), hub.Room, &eeh) // never exits
 				if err != nil {
-					globals.AppLogger.Error("could not init emit events for plugin", "pluginName", pluginName)
+					globals.AppLogger.Error("could not init emit events for plugin", "pluginName", plg.Name, "error", err)
 					<-time.After(time.Second)
 				}
 			}
@@ -124,13 +181,41 @@ func (h *Hub) NoClients() int {
 	return len(h.clients)
 }
 
-func (h *Hub) handlePlugins(events []*types.Event, skipPlugins map[string]struct{}) error {
-	for pluginName, plg := range h.pluginMap {
-		if _, ok := skipPlugins[pluginName]; ok {
+// handlePlugins queues the events for all observer plugins whose event filter matches (in order of their priority), it
+// does not wait for the plugins to process them. Registered commands are only queued for the plugin which registered
+// them, regardless of the event filters. chain is the list of plugins that (in this order) produced the events, those plugins do not receive
+// the events again and if the chain is already too long, the events are not dispatched at all.
+func (h *Hub) handlePlugins(ctx context.Context, events []*types.Event, chain []string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if len(chain) >= maxPluginChainLength {
+		pluginCycles.Add(chain[len(chain)-1], int64(len(events)))
+		globals.AppLogger.Warn("plugin chain too long, not dispatching events", "chain", chain)
+		return nil
+	}
+	skipPlugins := make(map[string]struct{})
+	for _, pluginName := range chain {
+		skipPlugins[pluginName] = struct{}{}
+	}
+	for _, pluginName := range h.pluginOrder {
+		plg := h.pluginMap[pluginName]
+		if _, ok := skipPlugins[pluginName]; ok || plg.Kind != plugins.KindObserver {
 			continue
 		}
 		passEvents := make([]*types.Event, 0)
 		for _, event := range events {
+			if event.Source != nil && event.Source.PluginName == pluginName {
+				pluginCycles.Add(pluginName, 1)
+				continue
+			}
+			if owner := h.commandOwner(event); owner != "" {
+				// registered commands are only routed to the plugin which registered them
+				if owner == pluginName {
+					passEvents = append(passEvents, event)
+				}
+				continue
+			}
 			if plg.EventFilter != "" {
 				if h.EvaluatePluginFilterEvent(event, plg.EventFilter) {
 					passEvents = append(passEvents, event)
@@ -142,60 +227,155 @@ func (h *Hub) handlePlugins(events []*types.Event, skipPlugins map[string]struct
 		if len(passEvents) == 0 {
 			continue
 		}
-		resEvents, err := plg.Plugin.HandleEvents(passEvents)
-		if err != nil {
-			globals.AppLogger.Error("could not call plugin to handle message", "error", err)
+		if w, ok := h.pluginWorkers[pluginName]; ok {
+			w.enqueue(ctx, pluginJob{events: passEvents, chain: chain})
+		}
+	}
+	return nil
+}
+
+// rejectedEvent is an event that was rejected by an interceptor plugin.
+type rejectedEvent struct {
+	event      *types.Event
+	pluginName string
+	reason     string
+}
+
+// filterEvents passes the events through all interceptor plugins (except the ones in chain) in order of their
+// priority, each interceptor receives the output of the previous one. Only the events passing a plugin's event filter
+// are passed to the plugin, the other events are kept as they are. A modified event only takes over the tags, the
+// target filter and the language (if set, f.e. by language detection) of the plugin's version, the identity of the
+// event cannot be changed. If an interceptor fails (or
+// times out), the events are passed on unchanged.
+// filterEvents returns the events that are to be broadcast (in their original order), which may be none, and the
+// rejected events.
+func (h *Hub) filterEvents(ctx context.Context, events []*types.Event, chain []string) ([]*types.Event, []rejectedEvent) {
+	skipPlugins := make(map[string]struct{})
+	for _, pluginName := range chain {
+		skipPlugins[pluginName] = struct{}{}
+	}
+	rejected := make([]rejectedEvent, 0)
+	for _, pluginName := range h.pluginOrder {
+		if len(events) == 0 {
+			break
+		}
+		plg := h.pluginMap[pluginName]
+		if _, ok := skipPlugins[pluginName]; ok || plg.Kind != plugins.KindInterceptor {
 			continue
 		}
-		newSkipPlugins := make(map[string]struct{})
-		for key, val := range skipPlugins {
-			newSkipPlugins[key] = val
+		passIdx := make([]int, 0, len(events))
+		passEvents := make([]*types.Event, 0, len(events))
+		for i, event := range events {
+			if plg.EventFilter == "" || h.EvaluatePluginFilterEvent(event, plg.EventFilter) {
+				passIdx = append(passIdx, i)
+				passEvents = append(passEvents, event)
+			}
 		}
-		newSkipPlugins[pluginName] = struct{}{}
-		err = h.handlePlugins(resEvents, newSkipPlugins)
-		if err != nil {
-			globals.AppLogger.Error("could not handle plugins", "error", err)
+		if len(passEvents) == 0 {
 			continue
 		}
-		globals.AppLogger.Info("plugin handled", "plugin", pluginName, "resEvents", resEvents)
-		err = h.handleEvents(resEvents)
+		results, err := plg.FilterEvents(ctx, passEvents)
 		if err != nil {
-			globals.AppLogger.Error("could not handle events", "error", err)
+			globals.AppLogger.Error("could not call plugin to filter events, passing events on", "plugin", pluginName, "error", err)
 			continue
 		}
+		rejectIdx := make(map[int]struct{})
+		for i, result := range results {
+			event := events[passIdx[i]]
+			switch result.Action {
+			case plugins.FilterActionModified:
+				modEvent := *event
+				modEvent.Tags = result.Event.Tags
+				modEvent.TargetFilter = result.Event.TargetFilter
+				if result.Event.Language != "" {
+					modEvent.Language = result.Event.Language
+				}
+				events[passIdx[i]] = &modEvent
+			case plugins.FilterActionRejected:
+				rejectIdx[passIdx[i]] = struct{}{}
+				rejected = append(rejected, rejectedEvent{event: event, pluginName: pluginName, reason: result.Reason})
+				globals.AppLogger.Info("plugin rejected event", "plugin", pluginName, "id", event.Id, "reason", result.Reason)
+			}
+		}
+		if len(rejectIdx) > 0 {
+			keepEvents := make([]*types.Event, 0, len(events)-len(rejectIdx))
+			for i, event := range events {
+				if _, ok := rejectIdx[i]; !ok {
+					keepEvents = append(keepEvents, event)
+				}
+			}
+			events = keepEvents
+		}
 	}
-	return nil
+	return events, rejected
+}
+
+// publishEvents handles events that were produced by the plugins in chain (the last one being the actual source):
+// the events are filtered, broadcast and stored, and finally queued for the observer plugins. Rejected events are
+// dropped silently, there is no client to notify.
+func (h *Hub) publishEvents(ctx context.Context, events []*types.Event, chain []string) error {
+	events, _ = h.filterEvents(ctx, events, chain)
+	err := h.handleEvents(events)
+	if err != nil {
+		return err
+	}
+	return h.handlePlugins(ctx, events, chain)
 }
 
+// handleEvents numbers the events, adds them to the history, stores them and broadcasts them.
 func (h *Hub) handleEvents(events []*types.Event) error {
 	globals.AppLogger.Debug("in main handle Events", "events", events)
 	if len(events) > 0 {
-		h.BroadcastEvents <- events
+		h.handleLock.Lock()
+		defer h.handleLock.Unlock()
+		h.seqLock.Lock()
+		for _, event := range events {
+			h.seq++
+			event.Seq = h.seq
+		}
+		h.appendHistory(events)
+		h.seqLock.Unlock()
 		h.EventHistory <- events
+		h.BroadcastEvents <- events
 	}
 	return nil
 }
 
+// appendHistory adds the events to the ring buffer.
+func (h *Hub) appendHistory(events []*types.Event) {
+	h.lockEventHistory.Lock()
+	defer h.lockEventHistory.Unlock()
+	for _, event := range events {
+		h.eventHistoryEnd.Value = event
+		h.eventHistoryEnd = h.eventHistoryEnd.Next()
+		if h.eventHistoryEnd == h.eventHistoryStart {
+			h.eventHistoryStart = h.eventHistoryStart.Next()
+		}
+	}
+}
+
+// LastSeq returns the sequence number of the last event of the room.
+func (h *Hub) LastSeq() uint64 {
+	h.seqLock.Lock()
+	defer h.seqLock.Unlock()
+	return h.seq
+}
+
 // Run is the main hub event loop handling register, unregister and broadcast events.
 func (h *Hub) Run() {
 	cronRunner := cron.New(cron.WithLocation(time.UTC), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
 	for pluginName, plg := range h.pluginMap {
 		if plg.CronSpec != "" && pluginName != "" {
+			pluginName := pluginName
 			if plg, ok := h.pluginMap[pluginName]; ok {
 				entryId, err := cronRunner.AddFunc(plg.CronSpec, func() {
-					events, err := plg.Plugin.Cron(h.Room)
+					ctx := context.Background()
+					events, err := plg.Cron(ctx, h.Room)
 					if err != nil {
-						globals.AppLogger.Error("error calling cron", "error", err)
+						globals.AppLogger.Error("error calling cron", "plugin", pluginName, "error", err)
 						return
 					}
-					skipPlugins := make(map[string]struct{})
-					skipPlugins[pluginName] = struct{}{}
-					err = h.handlePlugins(events, skipPlugins)
-					if err != nil {
-						globals.AppLogger.Error("error handling plugins", "error", err)
-						return
-					}
-					err = h.handleEvents(events)
+					err = h.publishEvents(ctx, events, []string{pluginName})
 					if err != nil {
 						globals.AppLogger.Error("error handling events", "error", err)
 						return
@@ -230,7 +410,7 @@ func (h *Hub) Run() {
 					h.Lock()
 					delete(h.clients, client)
 					h.Unlock()
-					client.conn.Close()
+					client.conn.Close(0, "")
 					client.Wait()
 					// here we have two options, both have their drawbacks:
 					// - have some locking mechanism in place to avoid writing to a closed channel, because
@@ -253,7 +433,6 @@ func (h *Hub) Run() {
 					// }()
 					// close the channel and hope there is no more write to it
 					close(client.Send)
-					close(client.SendEvents)
 					close(client.PluginChan)
 					go h.SendInfo(h.GetInfo()) // this way the number of clients does not change between calling the goroutine and executing it
 				} else {
@@ -262,57 +441,9 @@ func (h *Hub) Run() {
 			}()
 
 		case events := <-h.BroadcastEvents:
-			for i, event := range events {
-				if event.Name == types.EventTypeInternal {
-					continue
-				}
-				var prog *vm.Program
-				if event.TargetFilter != "" {
-					var err error
-					prog, err = expr.Compile(event.TargetFilter, expr.Env(filter.Env{}))
-					if err != nil {
-						globals.AppLogger.Error("could not compile filter", "error", err)
-					}
-				}
-				globals.AppLogger.Debug("checking event", "event", event)
-				go func(evt *types.Event, prg *vm.Program) {
-					var wg sync.WaitGroup
-					h.RLock()
-					for client := range h.clients {
-						if !client.RunFilterEvent(evt, prg) {
-							globals.AppLogger.Debug("filter prevented event!", "client", client, "client.Language", client.Language)
-							continue
-						}
-						globals.AppLogger.Debug("event passed filter!", "client", client, "client.Language", client.Language)
-						if data, err := json.Marshal(types.WireEvent{Event: evt}); err == nil {
-							wg.Add(1)
-							client.Add(1)
-							go func(c *Client, d []byte) {
-								defer wg.Done()
-								defer c.Done()
-								globals.AppLogger.Debug("about to send", "data", string(d))
-								c.Send <- d
-							}(client, data)
-						}
-					}
-					globals.AppLogger.Debug("wait for broadcast to finish")
-					wg.Wait()
-					h.RUnlock()
-					globals.AppLogger.Debug("broadcast finished")
-				}(events[i], prog)
-			}
+			h.broadcast(events)
 
 		case events := <-h.EventHistory:
-			h.lockEventHistory.Lock()
-			for _, event := range events {
-				h.eventHistoryEnd.Value = event
-				h.eventHistoryEnd = h.eventHistoryEnd.Next()
-				if h.eventHistoryEnd == h.eventHistoryStart {
-					h.eventHistoryStart = h.eventHistoryStart.Next()
-				}
-			}
-			h.lockEventHistory.Unlock()
-
 			if h.Persister != nil {
 				err := h.Persister.StoreEvents(h.Room, events)
 				if err != nil {
@@ -356,7 +487,74 @@ func (h *Hub) GetInfo() *types.Event {
 	return types.NewEvent(h.Room, source, "", "", types.EventTypeInfo, tags)
 }
 
+// roomTags returns the tags of the room. The map is replaced, not modified, when the tags change (see setRoomTags).
+func (h *Hub) roomTags() map[string]string {
+	h.roomTagsLock.RLock()
+	defer h.roomTagsLock.RUnlock()
+	if h.Room == nil {
+		return nil
+	}
+	return h.Room.Tags
+}
+
+// setRoomTags replaces the tags of the room, f.e. after a plugin changed them. The permissions and the other settings
+// of the room taken from its tags apply immediately.
+func (h *Hub) setRoomTags(tags map[string]string) {
+	h.roomTagsLock.Lock()
+	defer h.roomTagsLock.Unlock()
+	h.Room.Tags = tags
+}
+
+// GetLanguages returns the sorted set of languages (2 letters, lower case) of all registered clients.
+func (h *Hub) GetLanguages() []string {
+	languageSet := make(map[string]struct{})
+	h.RLock()
+	for c := range h.clients {
+		if len(c.Language) >= 2 {
+			languageSet[strings.ToLower(c.Language[:2])] = struct{}{}
+		}
+	}
+	h.RUnlock()
+	languages := make([]string, 0, len(languageSet))
+	for language := range languageSet {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
 // SendInfo broadcasts hub statistics to all clients.
 func (h *Hub) SendInfo(event *types.Event) {
 	h.BroadcastEvents <- []*types.Event{event}
 }
+
+// LoadUser returns the user of the authenticated identity. A user who is not persisted yet is created, with the nick
+// and language provided by the identity provider (or the user id and "en").
+func (h *Hub) LoadUser(identity *auth.Identity) (*types.User, error) {
+	user := &types.User{Id: identity.UserId, Tags: make(map[string]string)}
+	if h.Persister != nil {
+		err := h.Persister.GetUser(user)
+		if err == nil {
+			return user, nil
+		}
+		if err != gorm.ErrRecordNotFound && err != buntdb.ErrNotFound && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	user.Nick = identity.Nick
+	if user.Nick == "" {
+		user.Nick = user.Id
+	}
+	user.Language = identity.Language
+	if user.Language == "" {
+		user.Language = "en"
+	}
+	user.LastOnline = time.Now()
+	if h.Persister != nil {
+		err := h.Persister.StoreUser(*user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,301 @@
+package ws_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/persistence"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/plugins/sdk"
+	"github.com/tcriess/lightspeed-chat/types"
+	"github.com/tcriess/lightspeed-chat/ws"
+	"github.com/tcriess/lightspeed-chat/ws/wstest"
+)
+
+const timeout = 5 * time.Second
+
+// newRunningHub runs a hub without persistence and authentication, with the plugins running in-process.
+func newRunningHub(t *testing.T, handlers ...*sdk.Base) *ws.Hub {
+	pluginMap := make(map[string]plugins.PluginSpec)
+	for _, handler := range handlers {
+		cfg, err := handler.Configure(context.Background(), nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		pluginMap[handler.Name] = plugins.PluginSpec{
+			Name:        handler.Name,
+			Plugin:      handler,
+			EventFilter: cfg.EventsFilter,
+			Priority:    cfg.Priority,
+			Kind:        cfg.Kind,
+			Commands:    cfg.Commands,
+		}
+	}
+	room := &types.Room{Id: "room", Owner: &types.User{Id: "owner", Nick: "owner", Tags: make(map[string]string)}, Tags: make(map[string]string)}
+	hub := ws.NewHub(room, &config.Config{}, nil, nil, pluginMap)
+	go hub.Run()
+	return hub
+}
+
+// connect connects an authenticated member of the room.
+func connect(hub *ws.Hub, userId, subprotocol string) *wstest.Client {
+	user := &types.User{Id: userId, Nick: userId, Language: "en", Tags: make(map[string]string)}
+	return wstest.Connect(hub, user, &auth.Identity{UserId: userId}, subprotocol)
+}
+
+// chats returns the messages of the chat events of the next message containing chat events.
+func chats(t *testing.T, c *wstest.Client) []string {
+	messages := make([]string, 0)
+	for len(messages) == 0 {
+		events, err := c.NextEvents(timeout)
+		if !assert.NoError(t, err) {
+			break
+		}
+		for _, event := range events {
+			if event.Name == types.EventTypeChat {
+				messages = append(messages, event.Tags["message"])
+			}
+		}
+	}
+	return messages
+}
+
+func TestHubRegister(t *testing.T) {
+	hub := newRunningHub(t)
+	alice := connect(hub, "alice", "")
+	assert.Equal(t, 1, hub.NoClients())
+	info, err := alice.NextEvent(types.EventTypeInfo, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "alice", info.Tags["nicks"])
+	}
+
+	bob := connect(hub, "bob", "lightspeed-chat.v1")
+	assert.Equal(t, 2, hub.NoClients())
+	for {
+		msg, err := bob.Next(timeout)
+		if !assert.NoError(t, err) || msg.Event == types.WireMessageTypeHello {
+			break
+		}
+		assert.Equal(t, types.EventTypeInfo, msg.Event, "only the info may be sent before the hello")
+	}
+
+	bob.Disconnect()
+	assert.Eventually(t, func() bool { return hub.NoClients() == 1 }, timeout, time.Millisecond)
+	info, err = alice.NextEvent(types.EventTypeInfo, timeout)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{"alice", "bob"}, strings.Split(info.Tags["nicks"], ","))
+	}
+	info, err = alice.NextEvent(types.EventTypeInfo, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "alice", info.Tags["nicks"], "the info is sent again after the client is unregistered")
+	}
+	assert.Error(t, bob.Chat("hello", ""), "the transport is closed")
+	alice.Disconnect()
+	assert.Eventually(t, func() bool { return hub.NoClients() == 0 }, timeout, time.Millisecond)
+}
+
+func TestHubHistory(t *testing.T) {
+	hub := newRunningHub(t)
+	alice := connect(hub, "alice", "lightspeed-chat.v1")
+	assert.NoError(t, alice.Chat("first", ""))
+	assert.NoError(t, alice.Chat("second", ""))
+	for _, message := range []string{"first", "second"} {
+		event, err := alice.NextEvent(types.EventTypeChat, timeout)
+		if assert.NoError(t, err) {
+			assert.Equal(t, message, event.Tags["message"])
+			assert.Equal(t, "alice", event.Source.User.Id)
+		}
+	}
+
+	bob := connect(hub, "bob", "")
+	assert.Equal(t, []string{"first", "second"}, chats(t, bob), "the history is sent to new clients")
+	history := hub.GetHistory()
+	if assert.Len(t, history, 2) {
+		assert.Less(t, history[0].Seq, history[1].Seq)
+	}
+}
+
+func TestHubTargetFilter(t *testing.T) {
+	hub := newRunningHub(t)
+	alice := connect(hub, "alice", "lightspeed-chat.v1")
+	bob := connect(hub, "bob", "lightspeed-chat.v1")
+	carol := connect(hub, "carol", "lightspeed-chat.v1")
+
+	assert.NoError(t, alice.Chat("only for bob", `Target.User.Id == "bob"`))
+	assert.NoError(t, alice.Chat("for everyone", ""))
+	assert.Equal(t, []string{"only for bob"}, chats(t, bob))
+	assert.Equal(t, []string{"for everyone"}, chats(t, bob))
+	for _, c := range []*wstest.Client{alice, carol} {
+		assert.Equal(t, []string{"for everyone"}, chats(t, c), "%s does not receive the private message", c.Metrics().UserId)
+	}
+
+	// Source.Bot tells the messages of bots apart
+	assert.NoError(t, alice.Chat("not from a bot", "Source.Bot == true"))
+	assert.NoError(t, alice.Chat("from alice", ""))
+	assert.Equal(t, []string{"from alice"}, chats(t, bob))
+	bot := &types.Bot{UserId: "alerts", Events: "chat", Messages: 10, Interval: time.Minute}
+	assert.Equal(t, http.StatusAccepted, post(t, hub, bot, types.WireMessageTypeChat, "", types.ChatMessage{Message: "from a bot", Filter: "Source.Bot == true"}))
+	assert.Equal(t, []string{"from a bot"}, chats(t, bob))
+}
+
+func TestHubCommands(t *testing.T) {
+	echo := sdk.NewBase("echo")
+	echo.Commands.Handle(plugins.CommandSpec{
+		Name:        "/echo",
+		Description: "repeat the message",
+		Args:        []plugins.CommandArg{{Name: "message", Type: plugins.ArgTypeText}},
+	}, func(ctx context.Context, cmd *sdk.Command) ([]*types.Event, error) {
+		return []*types.Event{cmd.Reply("echo: " + cmd.Arg("message"))}, nil
+	})
+	hub := newRunningHub(t, echo)
+	alice := connect(hub, "alice", "lightspeed-chat.v1")
+	bob := connect(hub, "bob", "lightspeed-chat.v1")
+
+	assert.NoError(t, alice.Chat("/echo hello  world", ""))
+	command, err := alice.NextEvent(types.EventTypeCommand, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/echo", command.Tags["command"])
+	}
+	reply, err := alice.NextEvent(types.EventTypeChat, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "echo: hello  world", reply.Tags["message"])
+		assert.Equal(t, "echo", reply.Source.PluginName)
+	}
+
+	assert.NoError(t, alice.Chat("/unknown", ""))
+	for {
+		msg, err := alice.Next(timeout)
+		if !assert.NoError(t, err) {
+			break
+		}
+		if msg.Event == types.WireMessageTypeError {
+			assert.Contains(t, string(msg.Data), "unknown_command", "unknown commands are not routed")
+			break
+		}
+	}
+
+	assert.NoError(t, alice.Chat("done", ""))
+	event, err := bob.NextEvent(types.EventTypeChat, timeout)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "done", event.Tags["message"], "the command and the reply are only sent to alice")
+	}
+}
+
+func TestHubUnconfiguredPluginCommands(t *testing.T) {
+	echo := sdk.NewBase("echo")
+	echo.Commands.Handle(plugins.CommandSpec{
+		Name:        "/echo",
+		Description: "repeat the message",
+		Args:        []plugins.CommandArg{{Name: "message", Type: plugins.ArgTypeText}},
+	}, func(ctx context.Context, cmd *sdk.Command) ([]*types.Event, error) {
+		return []*types.Event{cmd.Reply("echo: " + cmd.Arg("message"))}, nil
+	})
+	// the plugin has no configuration block
+	spec, err := plugins.NewPluginSpec(context.Background(), "echo", echo, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	room := &types.Room{Id: "room", Owner: &types.User{Id: "owner", Nick: "owner", Tags: make(map[string]string)}, Tags: make(map[string]string)}
+	hub := ws.NewHub(room, &config.Config{}, nil, nil, map[string]plugins.PluginSpec{"echo": spec})
+	go hub.Run()
+	alice := connect(hub, "alice", "lightspeed-chat.v1")
+	defer alice.Disconnect()
+
+	assert.NoError(t, alice.Chat("/echo hello", ""))
+	for {
+		event, err := alice.NextEvent(types.EventTypeChat, timeout)
+		if !assert.NoError(t, err) {
+			break
+		}
+		if event.Source.PluginName == "echo" {
+			assert.Equal(t, "echo: hello", event.Tags["message"], "the command is routed to the plugin")
+			break
+		}
+	}
+}
+
+// moderator rejects "spam" and censors "darn".
+type moderator struct {
+	*sdk.Base
+}
+
+func (m *moderator) FilterEvents(ctx context.Context, events []*types.Event) ([]*plugins.FilterResult, error) {
+	results := make([]*plugins.FilterResult, 0, len(events))
+	for _, event := range events {
+		switch message := event.Tags["message"]; {
+		case message == "spam":
+			results = append(results, plugins.Rejected("no spam"))
+		case strings.Contains(message, "darn"):
+			modified := *event
+			modified.Tags = map[string]string{"message": strings.ReplaceAll(message, "darn", "****"), "censored": "true"}
+			results = append(results, plugins.Modified(&modified))
+		default:
+			results = append(results, plugins.Unchanged())
+		}
+	}
+	return results, nil
+}
+
+func TestHubInterceptor(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.PersistenceConfig.BuntDBConfig.GlobalName = filepath.Join(dir, "global.buntdb")
+	cfg.PersistenceConfig.BuntDBConfig.RoomNameTemplate = filepath.Join(dir, "room_{{ .RoomId }}.buntdb")
+	persister, err := persistence.NewBuntPersister(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer persister.Close()
+	room := &types.Room{Id: "room", Owner: &types.User{Id: "owner", Nick: "owner", Tags: make(map[string]string)}, Tags: make(map[string]string)}
+	assert.NoError(t, persister.StoreRoom(*room))
+
+	mod := &moderator{Base: sdk.NewBase("moderator")}
+	mod.Kind = plugins.KindInterceptor
+	pluginMap := map[string]plugins.PluginSpec{
+		"moderator": {Name: "moderator", Plugin: mod, Kind: plugins.KindInterceptor},
+	}
+	hub := ws.NewHub(room, cfg, nil, persister, pluginMap)
+	go hub.Run()
+	alice := connect(hub, "alice", "lightspeed-chat.v1")
+	bob := connect(hub, "bob", "")
+
+	assert.NoError(t, alice.SendMessage(types.WireMessageTypeChat, "1", types.ChatMessage{Message: "spam"}))
+	for {
+		msg, err := alice.Next(timeout)
+		if !assert.NoError(t, err) {
+			break
+		}
+		if msg.Event == types.WireMessageTypeError {
+			assert.Contains(t, string(msg.Data), "rejected")
+			assert.Contains(t, string(msg.Data), "no spam")
+			break
+		}
+	}
+
+	assert.NoError(t, alice.Chat("darn it", ""))
+	assert.Equal(t, []string{"**** it"}, chats(t, bob), "the rejected message is not broadcast, the modified one replaces the original")
+
+	var stored []*types.Event
+	assert.Eventually(t, func() bool {
+		stored, err = persister.GetEventsAfterSeq(room, 0, 10)
+		return err == nil && len(stored) > 0
+	}, timeout, time.Millisecond)
+	messages := make([]string, 0)
+	for _, event := range stored {
+		if event.Name == types.EventTypeChat {
+			messages = append(messages, event.Tags["message"])
+			assert.Equal(t, "true", event.Tags["censored"])
+		}
+	}
+	assert.Equal(t, []string{"**** it"}, messages, "only the modified message is stored")
+	for _, event := range hub.GetHistory() {
+		assert.NotEqual(t, "spam", event.Tags["message"], "the rejected message is not in the history")
+	}
+}
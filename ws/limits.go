@@ -0,0 +1,75 @@
+package ws
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tcriess/lightspeed-chat/config"
+)
+
+// rateLimiter keeps track of the messages sent by a client within a sliding window. The limiter of a bot is shared by
+// all its connections (see Hub.botLimiter).
+type rateLimiter struct {
+	sync.Mutex
+	sent []time.Time
+}
+
+// allow reports whether another message may be sent at now under the limits, and records the message if so.
+func (l *rateLimiter) allow(limits config.LimitsConfig, now time.Time) bool {
+	if limits.Messages <= 0 || limits.Interval <= 0 {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	cutoff := now.Add(-limits.Interval)
+	i := 0
+	for i < len(l.sent) && !l.sent[i].After(cutoff) {
+		i++
+	}
+	l.sent = l.sent[i:]
+	if len(l.sent) >= limits.Messages {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
+
+// limits returns the limits for the user of the client: the guest limits for guests, the global limits otherwise. The
+// rate of a bot replaces the global rate, if it is set.
+func (c *Client) limits() config.LimitsConfig {
+	limits := config.LimitsConfig{}
+	if c.hub.Cfg != nil {
+		limits = c.hub.Cfg.Limits
+		if c.user.IsGuest {
+			limits = c.hub.Cfg.GuestConfig.Limits
+		}
+	}
+	if bot := c.bot(); bot != nil && bot.Messages > 0 {
+		limits.Messages = bot.Messages
+		limits.Interval = bot.Interval
+	}
+	return limits
+}
+
+// messageLimiter returns the limiter of the messages sent by the client.
+func (c *Client) messageLimiter() *rateLimiter {
+	if bot := c.bot(); bot != nil {
+		return c.hub.botLimiter(bot.UserId)
+	}
+	return &c.limiter
+}
+
+// checkLimits checks the length of the message and the rate of messages sent by the client. It returns the notice for
+// the user if a limit is exceeded, or the empty string.
+func (c *Client) checkLimits(message string) string {
+	limits := c.limits()
+	if limits.MaxMessageLength > 0 && utf8.RuneCountInString(message) > limits.MaxMessageLength {
+		return fmt.Sprintf("Your message is too long (at most %d characters).", limits.MaxMessageLength)
+	}
+	if !c.messageLimiter().allow(limits, time.Now()) {
+		return "You are sending messages too fast, please wait a moment."
+	}
+	return ""
+}
@@ -0,0 +1,11 @@
+package ws
+
+import "expvar"
+
+// Counters published via expvar (see /debug/vars).
+var (
+	pluginQueueDropped = expvar.NewMap("plugin_queue_dropped") // events dropped because a plugin queue was full, by plugin
+	pluginCycles       = expvar.NewMap("plugin_cycles")        // events not dispatched because of a plugin cycle, by plugin
+	clientsDropped     = expvar.NewMap("ws_dropped")           // messages dropped because a client send queue was full, by room
+	slowConsumers      = expvar.NewMap("ws_slow_consumers")    // clients disconnected as slow consumers, by room
+)
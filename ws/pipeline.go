@@ -0,0 +1,88 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	// maxPluginChainLength is the maximum number of plugins an event may pass through, i.e. the output of a plugin
+	// which itself was triggered by the output of maxPluginChainLength-1 other plugins is not dispatched any further.
+	maxPluginChainLength = 4
+)
+
+// pluginJob is a batch of events queued for a single plugin.
+// chain lists the plugins which (in this order) produced the events, it is used for cycle detection.
+type pluginJob struct {
+	events []*types.Event
+	chain  []string
+}
+
+// pluginWorker processes the events for one plugin of one hub asynchronously. The queue is bounded, what happens
+// if it is full is defined by the plugin's overflow policy.
+type pluginWorker struct {
+	hub   *Hub
+	spec  plugins.PluginSpec
+	queue chan pluginJob
+}
+
+func newPluginWorker(hub *Hub, spec plugins.PluginSpec) *pluginWorker {
+	w := &pluginWorker{
+		hub:   hub,
+		spec:  spec,
+		queue: make(chan pluginJob, spec.GetQueueSize()),
+	}
+	for i := 0; i < spec.GetConcurrency(); i++ {
+		go w.run()
+	}
+	return w
+}
+
+// enqueue adds a job to the worker's queue. With the "drop" policy, the job is dropped right away if the queue is
+// full, with the "block" policy, enqueue waits for at most the plugin timeout (or until ctx is done) before dropping
+// the job. It returns false if the job was dropped.
+func (w *pluginWorker) enqueue(ctx context.Context, job pluginJob) bool {
+	select {
+	case w.queue <- job:
+		return true
+	default:
+	}
+	if w.spec.OverflowPolicy == plugins.OverflowPolicyBlock {
+		timer := time.NewTimer(w.spec.GetTimeout())
+		defer timer.Stop()
+		select {
+		case w.queue <- job:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	pluginQueueDropped.Add(w.spec.Name, int64(len(job.events)))
+	globals.AppLogger.Warn("plugin queue full, dropping events", "plugin", w.spec.Name, "room", w.hub.Room.Id, "events", len(job.events))
+	return false
+}
+
+func (w *pluginWorker) run() {
+	for job := range w.queue {
+		resEvents, err := w.spec.HandleEvents(context.Background(), job.events)
+		if err != nil {
+			globals.AppLogger.Error("could not call plugin to handle message", "plugin", w.spec.Name, "error", err)
+			continue
+		}
+		if len(resEvents) == 0 {
+			continue
+		}
+		globals.AppLogger.Info("plugin handled", "plugin", w.spec.Name, "resEvents", resEvents)
+		chain := make([]string, len(job.chain), len(job.chain)+1)
+		copy(chain, job.chain)
+		chain = append(chain, w.spec.Name)
+		err = w.hub.publishEvents(context.Background(), resEvents, chain)
+		if err != nil {
+			globals.AppLogger.Error("could not handle events", "error", err)
+		}
+	}
+}
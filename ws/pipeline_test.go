@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/plugins/sdk"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// droppedEvents returns the number of events dropped for the plugin so far.
+func droppedEvents(pluginName string) int64 {
+	if v, ok := pluginQueueDropped.Get(pluginName).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// newQueueTestWorker returns a worker with a queue of two jobs, which is not processed.
+func newQueueTestWorker(h *Hub, spec plugins.PluginSpec) *pluginWorker {
+	return &pluginWorker{hub: h, spec: spec, queue: make(chan pluginJob, 2)}
+}
+
+func testJob(h *Hub, message string, n int) pluginJob {
+	events := make([]*types.Event, 0, n)
+	for i := 0; i < n; i++ {
+		events = append(events, types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, map[string]string{"message": message}))
+	}
+	return pluginJob{events: events}
+}
+
+func queuedJobs(w *pluginWorker) []string {
+	res := make([]string, 0)
+	for len(w.queue) > 0 {
+		job := <-w.queue
+		res = append(res, job.events[0].Tags["message"])
+	}
+	return res
+}
+
+func TestPluginQueueDrop(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	w := newQueueTestWorker(h, plugins.PluginSpec{Name: "drop-test", OverflowPolicy: plugins.OverflowPolicyDrop})
+	dropped := droppedEvents("drop-test")
+
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "one", 1)))
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "two", 1)))
+	start := time.Now()
+	assert.False(t, w.enqueue(context.Background(), testJob(h, "three", 3)), "the queue is full")
+	assert.Less(t, int64(time.Since(start)), int64(time.Second), "the job is dropped right away")
+	assert.Equal(t, int64(3), droppedEvents("drop-test")-dropped, "the events of the job are counted")
+	assert.Equal(t, []string{"one", "two"}, queuedJobs(w), "the new job is dropped")
+
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "four", 1)), "there is room again")
+	assert.Equal(t, []string{"four"}, queuedJobs(w))
+}
+
+func TestPluginQueueBlock(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	w := newQueueTestWorker(h, plugins.PluginSpec{Name: "block-test", OverflowPolicy: plugins.OverflowPolicyBlock, Timeout: 50 * time.Millisecond})
+	dropped := droppedEvents("block-test")
+
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "one", 1)))
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "two", 1)))
+
+	// the job waits for room in the queue
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-w.queue
+	}()
+	assert.True(t, w.enqueue(context.Background(), testJob(h, "three", 1)))
+	assert.Equal(t, int64(0), droppedEvents("block-test")-dropped)
+
+	// the job is dropped after the plugin timeout
+	start := time.Now()
+	assert.False(t, w.enqueue(context.Background(), testJob(h, "four", 2)))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond), "enqueue waits for the plugin timeout")
+	assert.Equal(t, int64(2), droppedEvents("block-test")-dropped)
+
+	// or when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, w.enqueue(ctx, testJob(h, "five", 1)))
+	assert.Equal(t, int64(3), droppedEvents("block-test")-dropped)
+	assert.Equal(t, []string{"two", "three"}, queuedJobs(w), "the queued jobs are kept")
+}
+
+// relayPlugin answers each event it receives with an event of its own.
+type relayPlugin struct {
+	*sdk.Base
+	calls int32
+}
+
+func (r *relayPlugin) HandleEvents(ctx context.Context, events []*types.Event) ([]*types.Event, error) {
+	atomic.AddInt32(&r.calls, 1)
+	res := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		res = append(res, types.NewEvent(event.Room, &types.Source{PluginName: r.Name}, "", "en", types.EventTypeChat, map[string]string{"message": r.Name}))
+	}
+	return res, nil
+}
+
+func TestPluginCycle(t *testing.T) {
+	// the plugins form a ring, each one relays the events of its predecessor (the first one those of the users)
+	relays := make([]*relayPlugin, 5)
+	pluginMap := make(map[string]plugins.PluginSpec)
+	for i := range relays {
+		relays[i] = &relayPlugin{Base: sdk.NewBase(fmt.Sprintf("relay%d", i))}
+		predecessor := fmt.Sprintf("relay%d", (i+len(relays)-1)%len(relays))
+		filter := fmt.Sprintf(`Source.PluginName == %q`, predecessor)
+		if i == 0 {
+			filter = fmt.Sprintf(`Source.PluginName == "" || Source.PluginName == %q`, predecessor)
+		}
+		pluginMap[relays[i].Name] = plugins.PluginSpec{Name: relays[i].Name, Plugin: relays[i], EventFilter: filter}
+	}
+	room := &types.Room{Id: "cycle", Owner: &types.User{Id: "owner", Tags: make(map[string]string)}, Tags: make(map[string]string)}
+	h := NewHub(room, &config.Config{}, nil, nil, pluginMap)
+	cut := func() int64 {
+		if v, ok := pluginCycles.Get("relay3").(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	cutBefore := cut()
+
+	user := &types.User{Id: "alice", Tags: make(map[string]string)}
+	event := types.NewEvent(room, &types.Source{User: user}, "", "en", types.EventTypeChat, map[string]string{"message": "hello"})
+	assert.NoError(t, h.publishEvents(context.Background(), []*types.Event{event}, nil))
+
+	// the chain is cut off after maxPluginChainLength plugins
+	assert.Eventually(t, func() bool { return cut()-cutBefore == 1 }, 5*time.Second, time.Millisecond)
+	for i, relay := range relays[:maxPluginChainLength] {
+		assert.Equal(t, int32(1), atomic.LoadInt32(&relay.calls), "relay%d", i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&relays[4].calls), "the output of relay3 is not dispatched")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&relays[0].calls), "relay0 does not receive the events of the chain again")
+}
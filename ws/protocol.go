@@ -0,0 +1,303 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	// ProtocolV0 is the original protocol: events are sent in one message per event type ("<event name>s"), errors
+	// are sent as chat notices and a malformed message closes the connection.
+	ProtocolV0 = 0
+	// ProtocolV1 adds the hello, error, ack and batch messages and request ids, malformed messages are answered with
+	// an error.
+	ProtocolV1 = 1
+
+	// subprotocolPrefix is the prefix of the websocket subprotocols, followed by the protocol version.
+	subprotocolPrefix = "lightspeed-chat.v"
+
+	errorMalformed       = "malformed_message"
+	errorUnknownEvent    = "unknown_event"
+	errorUnauthenticated = "unauthenticated"
+	errorForbidden       = "forbidden"
+	errorLimit           = "limit_exceeded"
+	errorRejected        = "rejected"
+	errorMessageTooLarge = "message_too_large"
+)
+
+// ProtocolVersions are the supported protocol versions.
+var ProtocolVersions = []int{ProtocolV0, ProtocolV1}
+
+// reservedEvents are the messages sent by the server and the events created by the server and the plugins, which
+// cannot be sent by clients using protocol v1.
+var reservedEvents = map[string]struct{}{
+	types.EventTypeInfo:               {},
+	types.EventTypeUser:               {},
+	types.EventTypeTranslation:        {},
+	types.EventTypeTranslationRequest: {},
+	types.EventTypeCommand:            {},
+	types.WireMessageTypeSession:      {},
+	types.WireMessageTypeGap:          {},
+	types.WireMessageTypeConnection:   {},
+	types.WireMessageTypeError:        {},
+	types.WireMessageTypeAck:          {},
+	types.WireMessageTypeBatch:        {},
+	types.WireMessageTypeChats:        {},
+	types.WireMessageTypeTranslations: {},
+	types.WireMessageTypeUsers:        {},
+	types.WireMessageTypeCommands:     {},
+	types.WireMessageTypeGenerics:     {},
+}
+
+// Subprotocols returns the websocket subprotocols of the supported protocol versions, the latest first. Versions
+// supporting the protobuf encoding are offered with the binary encoding ("lightspeed-chat.v1+protobuf") first.
+func Subprotocols() []string {
+	subprotocols := make([]string, 0, 2*len(ProtocolVersions))
+	for i := len(ProtocolVersions) - 1; i >= 0; i-- {
+		subprotocol := subprotocolPrefix + strconv.Itoa(ProtocolVersions[i])
+		if ProtocolVersions[i] >= ProtocolV1 {
+			subprotocols = append(subprotocols, subprotocol+encodingSeparator+EncodingProtobuf)
+		}
+		subprotocols = append(subprotocols, subprotocol)
+	}
+	return subprotocols
+}
+
+// ProtocolFromSubprotocol returns the protocol version of the negotiated websocket subprotocol, ProtocolV0 if none was
+// negotiated.
+func ProtocolFromSubprotocol(subprotocol string) int {
+	if !strings.HasPrefix(subprotocol, subprotocolPrefix) {
+		return ProtocolV0
+	}
+	subprotocol = strings.SplitN(subprotocol, encodingSeparator, 2)[0]
+	version, err := strconv.Atoi(strings.TrimPrefix(subprotocol, subprotocolPrefix))
+	if err != nil || !supportedVersion(version) {
+		return ProtocolV0
+	}
+	return version
+}
+
+func supportedVersion(version int) bool {
+	for _, v := range ProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateVersion returns the latest supported version of versions, or ProtocolV0.
+func negotiateVersion(versions []int) int {
+	version := ProtocolV0
+	for _, v := range versions {
+		if v > version && supportedVersion(v) {
+			version = v
+		}
+	}
+	return version
+}
+
+// reservedEvent reports whether clients using protocol v1 must not send events with the name.
+func reservedEvent(name string) bool {
+	_, ok := reservedEvents[name]
+	return ok || name == "" || strings.HasPrefix(name, "_")
+}
+
+// Protocol returns the protocol version used by the client.
+func (c *Client) Protocol() int {
+	return int(atomic.LoadInt32(&c.protocol))
+}
+
+func (c *Client) setProtocol(version int) {
+	atomic.StoreInt32(&c.protocol, int32(version))
+}
+
+// hello negotiates the protocol version and the encoding requested by the hello message of the client. The client
+// always receives a hello message with the chosen version, which is ProtocolV0 if there is no common version, and the
+// chosen encoding (the encoding is kept if the hello message does not contain one). The answer is the first message
+// sent in the chosen encoding. A client which switches to protocol v1 receives its session afterwards.
+func (c *Client) hello(requestId string, data json.RawMessage) {
+	helloMsgMap := make(map[string]interface{})
+	_ = json.Unmarshal(data, &helloMsgMap)
+	helloMsg := types.HelloMessage{}
+	err := mapstructure.WeakDecode(helloMsgMap, &helloMsg)
+	if err != nil {
+		globals.AppLogger.Debug("could not decode hello message", "error", err)
+	}
+	versions := helloMsg.Versions
+	if len(versions) == 0 {
+		versions = []int{helloMsg.Version}
+	}
+	previous := c.Protocol()
+	c.setProtocol(negotiateVersion(versions))
+	encoding := helloMsg.Encoding
+	if encoding == "" {
+		encoding = c.Encoding()
+	}
+	c.setEncoding(encoding)
+	c.sendHello(requestId)
+	if previous < ProtocolV1 && c.Protocol() >= ProtocolV1 {
+		c.SendSession()
+	}
+}
+
+// SendHello sends the protocol version and encoding of the client and the supported versions and encodings to the
+// client.
+func (c *Client) SendHello() {
+	c.sendHello("")
+}
+
+func (c *Client) sendHello(requestId string) {
+	encodings := Encodings
+	if c.eventStream() {
+		encodings = []string{EncodingJSON}
+	}
+	c.sendReply(types.WireMessageTypeHello, requestId, types.HelloMessage{
+		Version:   c.Protocol(),
+		Versions:  ProtocolVersions,
+		Encoding:  c.Encoding(),
+		Encodings: encodings,
+	})
+}
+
+// sendError answers the message with the request id with an error. Clients using protocol v0 receive the message as a
+// notice instead.
+func (c *Client) sendError(requestId, code, message string) {
+	if c.Protocol() < ProtocolV1 {
+		c.sendNotice(message)
+		return
+	}
+	c.sendReply(types.WireMessageTypeError, requestId, types.ErrorMessage{Code: code, Message: message})
+}
+
+// malformed handles a malformed message of the client. It reports whether the connection is to be closed, which is
+// the case for protocol v0, clients using protocol v1 receive an error.
+func (c *Client) malformed(requestId string, err error) bool {
+	globals.AppLogger.Error("could not decode ws message", "error", err)
+	if c.Protocol() < ProtocolV1 {
+		return true
+	}
+	c.sendError(requestId, errorMalformed, fmt.Sprintf("Malformed message: %s", err))
+	return false
+}
+
+// ack acknowledges the message with the request id, event is the resulting event (may be nil). Acknowledgements are
+// only sent to clients using protocol v1 for messages with a request id.
+func (c *Client) ack(requestId string, event *types.Event) {
+	if requestId == "" || c.Protocol() < ProtocolV1 {
+		return
+	}
+	ack := types.AckMessage{}
+	if event != nil {
+		ack.EventId = event.Id
+		ack.Seq = event.Seq
+	}
+	c.sendReply(types.WireMessageTypeAck, requestId, ack)
+}
+
+// sendMessage sends a message of the server (which is not an event) to the client.
+func (c *Client) sendMessage(event string, data interface{}) {
+	c.sendReply(event, "", data)
+}
+
+// sendReply sends a message of the server answering the message with the request id to the client.
+func (c *Client) sendReply(event, requestId string, data interface{}) {
+	frame, err := c.encodeMessage(event, requestId, data)
+	if err != nil {
+		globals.AppLogger.Error("could not marshal message", "error", err)
+		return
+	}
+	c.send(frame)
+}
+
+// encodeMessage returns the websocket message containing a message of the server in the encoding of the client.
+// Messages without a protobuf representation are always sent as JSON.
+func (c *Client) encodeMessage(event, requestId string, data interface{}) (Frame, error) {
+	if c.Encoding() == EncodingProtobuf {
+		if msg := wireMessage(requestId, data); msg != nil {
+			w, err := protobuf.Marshal(msg)
+			return Frame{Binary: true, Data: w}, err
+		}
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Frame{}, err
+	}
+	w, err := json.Marshal(types.WebsocketMessage{Event: event, Id: requestId, Data: raw})
+	return Frame{Data: w}, err
+}
+
+// encodeEvents returns the messages sending the events which pass their target filters to the client. Clients using
+// protocol v1 receive one batch message containing the events in order, clients using protocol v0 one message per
+// event type ("<event name>s"). The events are serialized once and shared with the other clients (see encodingCache).
+func (c *Client) encodeEvents(events []*types.Event) []Frame {
+	encoded := make([]*encodedEvent, 0, len(events))
+	for _, event := range events {
+		if event.TargetFilter != "" {
+			if !c.EvaluateFilterEvent(event) {
+				continue
+			}
+		}
+		encoded = append(encoded, c.hub.encoded.get(event))
+	}
+	if len(encoded) == 0 {
+		return nil
+	}
+	if c.Encoding() == EncodingProtobuf {
+		return []Frame{{Binary: true, Data: protoBatch("", encoded)}}
+	}
+	batch := make([]json.RawMessage, 0, len(encoded))
+	eventsSlices := make(map[string][]json.RawMessage)
+	eventTypes := make([]string, 0)
+	for _, e := range encoded {
+		w := e.JSON()
+		if w == nil {
+			continue
+		}
+		batch = append(batch, w)
+		if _, ok := eventsSlices[e.event.Name]; !ok {
+			eventsSlices[e.event.Name] = make([]json.RawMessage, 0, len(encoded))
+			eventTypes = append(eventTypes, e.event.Name)
+		}
+		eventsSlices[e.event.Name] = append(eventsSlices[e.event.Name], w)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	messages := make([]types.WebsocketMessage, 0, len(eventTypes))
+	if c.Protocol() >= ProtocolV1 {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			globals.AppLogger.Error("could not marshal events", "error", err)
+			return nil
+		}
+		messages = append(messages, types.WebsocketMessage{Event: types.WireMessageTypeBatch, Data: data})
+	} else {
+		for _, eventType := range eventTypes {
+			data, err := json.Marshal(eventsSlices[eventType])
+			if err != nil {
+				globals.AppLogger.Error("could not marshal events", "error", err)
+				continue
+			}
+			messages = append(messages, types.WebsocketMessage{Event: eventType + "s", Data: data}) // make plural...
+		}
+	}
+	frames := make([]Frame, 0, len(messages))
+	for _, msg := range messages {
+		w, err := json.Marshal(msg)
+		if err != nil {
+			globals.AppLogger.Error("could not marshal events", "error", err)
+			continue
+		}
+		frames = append(frames, Frame{Data: w})
+	}
+	return frames
+}
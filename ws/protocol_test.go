@@ -0,0 +1,132 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestNegotiation(t *testing.T) {
+	assert.Equal(t, []string{"lightspeed-chat.v1+protobuf", "lightspeed-chat.v1", "lightspeed-chat.v0"}, Subprotocols())
+	assert.Equal(t, ProtocolV1, ProtocolFromSubprotocol("lightspeed-chat.v1"))
+	assert.Equal(t, ProtocolV1, ProtocolFromSubprotocol("lightspeed-chat.v1+protobuf"))
+	assert.Equal(t, ProtocolV0, ProtocolFromSubprotocol("lightspeed-chat.v0"))
+	assert.Equal(t, ProtocolV0, ProtocolFromSubprotocol("lightspeed-chat.v9"))
+	assert.Equal(t, ProtocolV0, ProtocolFromSubprotocol(""))
+
+	assert.Equal(t, ProtocolV1, negotiateVersion([]int{0, 1, 2}))
+	assert.Equal(t, ProtocolV0, negotiateVersion([]int{2}))
+	assert.Equal(t, ProtocolV0, negotiateVersion(nil))
+
+	assert.True(t, reservedEvent(types.EventTypeInfo))
+	assert.True(t, reservedEvent(types.WireMessageTypeAck))
+	assert.True(t, reservedEvent(types.EventTypeInternal))
+	assert.False(t, reservedEvent("reaction"))
+}
+
+// newProtocolTestClient returns a registered client using the protocol version.
+func newProtocolTestClient(version int) *Client {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	c := &Client{
+		hub:  h,
+		user: &types.User{Id: "alice", Nick: "alice", Tags: make(map[string]string)},
+		role: types.RoleMember,
+		Send: make(chan Frame, 10),
+	}
+	c.setProtocol(version)
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func TestEncodeEvents(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	events := []*types.Event{
+		types.NewEvent(room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "one"}),
+		types.NewEvent(room, nil, "", "en", types.EventTypeUser, nil),
+		types.NewEvent(room, nil, `Target.User.Id == "bob"`, "en", types.EventTypeChat, map[string]string{"message": "private"}),
+		types.NewEvent(room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "two"}),
+	}
+
+	c := newProtocolTestClient(ProtocolV0)
+	messages := c.encodeEvents(events)
+	if assert.Len(t, messages, 2) {
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, json.Unmarshal(messages[0].Data, &msg))
+		assert.Equal(t, types.WireMessageTypeChats, msg.Event)
+		batch := make([]types.WebsocketMessage, 0)
+		assert.NoError(t, json.Unmarshal(msg.Data, &batch))
+		assert.Len(t, batch, 2)
+		assert.NoError(t, json.Unmarshal(messages[1].Data, &msg))
+		assert.Equal(t, types.WireMessageTypeUsers, msg.Event)
+	}
+
+	c.setProtocol(ProtocolV1)
+	messages = c.encodeEvents(events)
+	if assert.Len(t, messages, 1) {
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, json.Unmarshal(messages[0].Data, &msg))
+		assert.Equal(t, types.WireMessageTypeBatch, msg.Event)
+		batch := make([]types.WebsocketMessage, 0)
+		assert.NoError(t, json.Unmarshal(msg.Data, &batch))
+		names := make([]string, 0)
+		for _, m := range batch {
+			names = append(names, m.Event)
+		}
+		assert.Equal(t, []string{types.EventTypeChat, types.EventTypeUser, types.EventTypeChat}, names, "the order is preserved")
+	}
+	assert.Empty(t, c.encodeEvents(events[2:3]))
+}
+
+func TestErrorsAndAcks(t *testing.T) {
+	event := &types.Event{Id: "event", Seq: 7}
+
+	c := newProtocolTestClient(ProtocolV0)
+	c.sendError("1", errorForbidden, "not allowed")
+	c.ack("1", event)
+	if assert.Len(t, c.Send, 1, "protocol v0 has no errors and acks") {
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeChats, msg.Event, "the error is sent as a notice")
+		assert.Contains(t, string(msg.Data), "not allowed")
+	}
+	assert.True(t, c.malformed("", assert.AnError), "protocol v0 closes the connection")
+
+	c = newProtocolTestClient(ProtocolV1)
+	c.sendError("1", errorForbidden, "not allowed")
+	c.ack("2", event)
+	c.ack("", event)
+	assert.False(t, c.malformed("3", assert.AnError))
+	if assert.Len(t, c.Send, 3) {
+		msg := types.WebsocketMessage{}
+		errorMsg := types.ErrorMessage{}
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeError, msg.Event)
+		assert.Equal(t, "1", msg.Id)
+		assert.NoError(t, json.Unmarshal(msg.Data, &errorMsg))
+		assert.Equal(t, types.ErrorMessage{Code: errorForbidden, Message: "not allowed"}, errorMsg)
+
+		ack := types.AckMessage{}
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeAck, msg.Event)
+		assert.Equal(t, "2", msg.Id)
+		assert.NoError(t, json.Unmarshal(msg.Data, &ack))
+		assert.Equal(t, types.AckMessage{EventId: "event", Seq: 7}, ack)
+
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeError, msg.Event)
+		assert.Equal(t, "3", msg.Id)
+	}
+
+	c = newProtocolTestClient(ProtocolV0)
+	c.hello("h", json.RawMessage(`{"versions":[0,1]}`))
+	assert.Equal(t, ProtocolV1, c.Protocol())
+	hello := types.HelloMessage{}
+	msg := types.WebsocketMessage{}
+	assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+	assert.Equal(t, "h", msg.Id)
+	assert.NoError(t, json.Unmarshal(msg.Data, &hello))
+	assert.Equal(t, types.HelloMessage{Version: ProtocolV1, Versions: ProtocolVersions, Encoding: EncodingJSON, Encodings: Encodings}, hello)
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// linkRegexp matches the links in a chat message (see types.PermissionPostLinks).
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// loadRoles loads the memberships of the room.
+func (h *Hub) loadRoles() {
+	if h.Persister == nil {
+		return
+	}
+	memberships, err := h.Persister.GetMemberships(h.Room)
+	if err != nil {
+		globals.AppLogger.Error("could not load memberships", "room", h.Room.Id, "error", err)
+		return
+	}
+	h.rolesLock.Lock()
+	defer h.rolesLock.Unlock()
+	for _, membership := range memberships {
+		if types.ValidRole(membership.Role) {
+			h.roles[membership.UserId] = membership.Role
+		}
+	}
+}
+
+// RoleOf returns the role of the user in the room: users who are not authenticated are guests, the owner of the room
+// is the owner, all other users have the role of their membership, RoleMember by default.
+func (h *Hub) RoleOf(user *types.User, authenticated bool) string {
+	if user == nil || user.Id == "" || !authenticated {
+		return types.RoleGuest
+	}
+	if h.Room.Owner != nil && h.Room.Owner.Id == user.Id {
+		return types.RoleOwner
+	}
+	h.rolesLock.RLock()
+	defer h.rolesLock.RUnlock()
+	if role, ok := h.roles[user.Id]; ok {
+		return role
+	}
+	return types.RoleMember
+}
+
+// allows reports whether the role has the permission in the room, with the current tags of the room (see
+// types.Room.Allows).
+func (h *Hub) allows(role, permission string) bool {
+	room := types.Room{Tags: h.roomTags()}
+	return room.Allows(role, permission)
+}
+
+// userRole returns the role of the user with the given id, preferring the role of a connected client.
+func (h *Hub) userRole(userId string) string {
+	h.RLock()
+	for c := range h.clients {
+		if c.user.Id == userId {
+			role := c.Role()
+			h.RUnlock()
+			return role
+		}
+	}
+	h.RUnlock()
+	return h.RoleOf(&types.User{Id: userId}, true)
+}
+
+// ChangeRole sets the role of the user in the room on behalf of the acting user, who needs the permission to
+// moderate. Only the owner may grant or revoke the moderator role, the role of the owner cannot be changed.
+// An empty actorId skips the checks of the acting user (the change is made by the server or a plugin itself).
+func (h *Hub) ChangeRole(actorId, userId, role string) error {
+	if !types.ValidRole(role) || role == types.RoleOwner || role == types.RoleGuest {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+	if userId == "" {
+		return fmt.Errorf("no user id")
+	}
+	if h.Room.Owner != nil && h.Room.Owner.Id == userId {
+		return fmt.Errorf("the role of the owner cannot be changed")
+	}
+	if actorId != "" {
+		actorRole := h.userRole(actorId)
+		if !h.allows(actorRole, types.PermissionModerate) {
+			return fmt.Errorf("user %s is not allowed to change roles", actorId)
+		}
+		currentRole := h.RoleOf(&types.User{Id: userId}, true)
+		if (role == types.RoleModerator || currentRole == types.RoleModerator) && actorRole != types.RoleOwner {
+			return fmt.Errorf("only the owner may change moderators")
+		}
+	}
+
+	if h.Persister != nil {
+		membership := types.Membership{RoomId: h.Room.Id, UserId: userId, Role: role}
+		var err error
+		if role == types.RoleMember {
+			err = h.Persister.DeleteMembership(&membership)
+			if err != nil {
+				// there may be no membership at all
+				globals.AppLogger.Debug("could not delete membership", "user", userId, "error", err)
+				err = nil
+			}
+		} else {
+			err = h.Persister.StoreMembership(membership)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	h.rolesLock.Lock()
+	if role == types.RoleMember {
+		delete(h.roles, userId)
+	} else {
+		h.roles[userId] = role
+	}
+	h.rolesLock.Unlock()
+
+	h.RLock()
+	for c := range h.clients {
+		c.updateRole(userId, role)
+	}
+	h.RUnlock()
+	return nil
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/persistence"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestRoomAllows(t *testing.T) {
+	room := &types.Room{Id: "room", Tags: map[string]string{}}
+	assert.True(t, room.Allows(types.RoleMember, types.PermissionPost))
+	assert.False(t, room.Allows(types.RoleMember, types.PermissionModerate))
+	assert.True(t, room.Allows(types.RoleGuest, types.PermissionRead))
+	assert.False(t, room.Allows(types.RoleGuest, types.PermissionPost))
+	assert.False(t, room.Allows(types.RoleBanned, types.PermissionRead))
+	assert.True(t, room.Allows(types.RoleOwner, types.PermissionManagePlugins))
+
+	room.Tags[types.RoomAllowGuestsTag] = "true"
+	assert.True(t, room.Allows(types.RoleGuest, types.PermissionPost), "the old tag is still understood")
+	room.Tags[types.PermissionTag(types.RoleGuest, types.PermissionPost)] = "false"
+	assert.False(t, room.Allows(types.RoleGuest, types.PermissionPost), "the permission tag takes precedence")
+	room.Tags[types.PermissionTag(types.RoleMember, types.PermissionPostLinks)] = "false"
+	assert.False(t, room.Allows(types.RoleMember, types.PermissionPostLinks))
+	room.Tags[types.PermissionTag(types.RoleOwner, types.PermissionRead)] = "false"
+	assert.True(t, room.Allows(types.RoleOwner, types.PermissionRead), "the owner cannot be restricted")
+}
+
+func TestRoles(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Room.Owner = &types.User{Id: "owner"}
+	owner := &types.User{Id: "owner"}
+	bob := &types.User{Id: "bob"}
+	alice := &types.User{Id: "alice"}
+
+	assert.Equal(t, types.RoleOwner, h.RoleOf(owner, true))
+	assert.Equal(t, types.RoleGuest, h.RoleOf(owner, false))
+	assert.Equal(t, types.RoleMember, h.RoleOf(bob, true))
+	assert.Equal(t, types.RoleGuest, h.RoleOf(&types.User{}, true))
+
+	assert.Error(t, h.ChangeRole("bob", "alice", types.RoleBanned), "members cannot moderate")
+	assert.NoError(t, h.ChangeRole("owner", "bob", types.RoleModerator))
+	assert.Equal(t, types.RoleModerator, h.RoleOf(bob, true))
+	assert.NoError(t, h.ChangeRole("bob", "alice", types.RoleBanned))
+	assert.Equal(t, types.RoleBanned, h.RoleOf(alice, true))
+	assert.Error(t, h.ChangeRole("bob", "alice", types.RoleModerator), "only the owner may appoint moderators")
+	assert.Error(t, h.ChangeRole("bob", "owner", types.RoleBanned))
+	assert.Error(t, h.ChangeRole("", "owner", types.RoleBanned), "the owner cannot be changed")
+	assert.Error(t, h.ChangeRole("", "alice", types.RoleOwner))
+	assert.Error(t, h.ChangeRole("", "alice", "admin"))
+	assert.NoError(t, h.ChangeRole("", "bob", types.RoleMember))
+	assert.Equal(t, types.RoleMember, h.RoleOf(bob, true))
+	assert.Error(t, h.ChangeRole("bob", "alice", types.RoleMember), "bob is no moderator any more")
+
+	c := &Client{hub: h, user: alice, authenticated: true, role: h.RoleOf(alice, true)}
+	h.clients[c] = struct{}{}
+	assert.False(t, c.Can(types.PermissionRead))
+	assert.NoError(t, h.ChangeRole("owner", "alice", types.RoleMember))
+	assert.Equal(t, types.RoleMember, c.Role(), "connected clients are updated")
+	assert.Equal(t, types.RoleMember, h.userRole("alice"))
+
+	assert.Equal(t, "", c.checkChatPermissions("see https://example.com"))
+	h.Room.Tags = map[string]string{types.PermissionTag(types.RoleMember, types.PermissionPostLinks): "false"}
+	assert.NotEqual(t, "", c.checkChatPermissions("see https://example.com"))
+	assert.Equal(t, "", c.checkChatPermissions("no links"))
+	c.setIdentity(nil)
+	assert.Equal(t, types.RoleGuest, c.Role())
+	assert.NotEqual(t, "", c.checkChatPermissions("hello"))
+	assert.NotEqual(t, "", c.checkChatPermissions("/help"))
+
+	c.setIdentity(&auth.Identity{UserId: "alice", Role: types.RoleModerator})
+	assert.Equal(t, types.RoleModerator, c.Role(), "the group role replaces the default role")
+	assert.NoError(t, h.ChangeRole("owner", "alice", types.RoleBanned))
+	assert.Equal(t, types.RoleBanned, c.Role(), "an explicit role takes precedence")
+	assert.NoError(t, h.ChangeRole("owner", "alice", types.RoleMember))
+	assert.Equal(t, types.RoleModerator, c.Role())
+}
+
+func TestHelperTagPermissions(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{}
+	cfg.PersistenceConfig.BuntDBConfig.GlobalName = filepath.Join(dir, "global.buntdb")
+	cfg.PersistenceConfig.BuntDBConfig.RoomNameTemplate = filepath.Join(dir, "room_{{ .RoomId }}.buntdb")
+	persister, err := persistence.NewBuntPersister(&cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer persister.Close()
+
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Room.Owner = &types.User{Id: "owner"}
+	h.Room.Tags = map[string]string{}
+	assert.NoError(t, persister.StoreRoom(*h.Room))
+	assert.NoError(t, persister.StoreUser(types.User{Id: "bob", Tags: map[string]string{}}))
+	h.Persister = persister
+	assert.NoError(t, h.ChangeRole("owner", "mod", types.RoleModerator))
+	eh := &emitEventsHelper{hub: h, pluginName: "test"}
+	ctx := context.Background()
+
+	muted := []*types.TagUpdate{{Name: "muted", Type: types.TagValueTypeString, Expression: `"yes"`}}
+	_, _, err = eh.ChangeUserTags(ctx, "alice", "bob", muted)
+	assert.Error(t, err, "members cannot moderate")
+	user, ok, err := eh.ChangeUserTags(ctx, "mod", "bob", muted)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []bool{true}, ok)
+		assert.Equal(t, "yes", user.Tags["muted"])
+	}
+	_, _, err = eh.ChangeUserTags(ctx, "", "bob", muted)
+	assert.NoError(t, err, "the plugin itself may change tags")
+
+	guestsPost := []*types.TagUpdate{{Name: types.PermissionTag(types.RoleGuest, types.PermissionPost), Type: types.TagValueTypeString, Expression: `"true"`}}
+	_, _, err = eh.ChangeRoomTags(ctx, "room", "mod", guestsPost)
+	assert.Error(t, err, "moderators cannot manage plugins")
+	_, _, err = eh.ChangeRoomTags(ctx, "other", "owner", guestsPost)
+	assert.Error(t, err, "the plugin can only change the tags of the room of the hub")
+	assert.False(t, h.allows(types.RoleGuest, types.PermissionPost))
+
+	room, ok, err := eh.ChangeRoomTags(ctx, "room", "owner", guestsPost)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []bool{true}, ok)
+		assert.Equal(t, "true", room.Tags[types.PermissionTag(types.RoleGuest, types.PermissionPost)])
+	}
+	assert.True(t, h.allows(types.RoleGuest, types.PermissionPost), "the changed tags apply right away")
+}
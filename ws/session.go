@@ -0,0 +1,252 @@
+package ws
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+const (
+	defaultSessionTTL = time.Hour
+	defaultMaxReplay  = 500
+)
+
+var (
+	randomSecretValue []byte
+	randomSecretOnce  sync.Once
+)
+
+// Session is the state of a connection which is restored when the client reconnects with its session token: the
+// identity of an authenticated user, or the guest identity.
+type Session struct {
+	RoomId    string         `json:"room"`
+	UserId    string         `json:"user"`
+	Nick      string         `json:"nick,omitempty"` // guests only
+	Identity  *auth.Identity `json:"identity,omitempty"`
+	ExpiresAt int64          `json:"exp"`
+}
+
+// Guest returns the guest user of the session, or nil if the session belongs to an authenticated user.
+func (s *Session) Guest() *types.User {
+	if s.Identity != nil || !strings.HasPrefix(s.UserId, guestIdPrefix) || s.Nick == "" {
+		return nil
+	}
+	return &types.User{
+		Id:      s.UserId,
+		Nick:    s.Nick,
+		Tags:    make(map[string]string),
+		IsGuest: true,
+	}
+}
+
+// ParseSession returns the session of the token, or nil if the token is invalid, expired or belongs to another room.
+// The identity of an authenticated user is checked again (see auth.Registry.CheckIdentity), so that a session cannot
+// outlive a revoked or expired token.
+func (h *Hub) ParseSession(token string) *Session {
+	session := &Session{}
+	if !decodeSigned(token, h.sessionSecret(), session) {
+		globals.AppLogger.Debug("ignoring invalid session token")
+		return nil
+	}
+	if session.RoomId != h.Room.Id || time.Now().Unix() >= session.ExpiresAt {
+		globals.AppLogger.Debug("ignoring session token", "room", session.RoomId, "expires", session.ExpiresAt)
+		return nil
+	}
+	if session.Identity == nil && session.Guest() == nil {
+		return nil
+	}
+	if session.Identity != nil {
+		if err := h.Auth.CheckIdentity(session.Identity); err != nil {
+			globals.AppLogger.Debug("ignoring session token", "user", session.UserId, "error", err)
+			return nil
+		}
+	}
+	return session
+}
+
+// sessionToken returns the session token for the user (identity is nil for guests) and its expiry. The session of an
+// authenticated user expires with the token it was authenticated with at the latest, also when it is renewed after a
+// reconnect.
+func (h *Hub) sessionToken(user *types.User, identity *auth.Identity) (string, time.Time) {
+	ttl := h.sessionConfig().TTL
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+	expires := time.Now().Add(ttl)
+	if identity != nil && identity.ExpiresAt.Before(expires) {
+		expires = identity.ExpiresAt
+	}
+	session := Session{
+		RoomId:    h.Room.Id,
+		UserId:    user.Id,
+		Identity:  identity,
+		ExpiresAt: expires.Unix(),
+	}
+	if identity == nil {
+		session.Nick = user.Nick
+	}
+	return encodeSigned(session, h.sessionSecret()), expires
+}
+
+// sessionSecret returns the configured secret for signing session tokens, or a random secret which is valid until the
+// process exits.
+func (h *Hub) sessionSecret() []byte {
+	if secret := h.sessionConfig().Secret; secret != "" {
+		return []byte(secret)
+	}
+	return randomSecret()
+}
+
+func (h *Hub) maxReplay() uint64 {
+	if maxReplay := h.sessionConfig().MaxReplay; maxReplay > 0 {
+		return uint64(maxReplay)
+	}
+	return defaultMaxReplay
+}
+
+func (h *Hub) sessionConfig() config.SessionConfig {
+	if h.Cfg == nil {
+		return config.SessionConfig{}
+	}
+	return h.Cfg.SessionConfig
+}
+
+// EventsAfter returns the events of the room with a sequence number greater than seq, i.e. the events missed by a
+// client which received the event seq last. The events are taken from the history if it reaches back far enough,
+// otherwise from the persister. It returns false if the events cannot be replayed: seq is unknown, there are more
+// missed events than the configured maximum, or they are not available any more.
+func (h *Hub) EventsAfter(seq uint64) ([]*types.Event, bool) {
+	h.seqLock.Lock()
+	last := h.seq
+	history := h.GetHistory()
+	h.seqLock.Unlock()
+	if seq > last || last-seq > h.maxReplay() {
+		return nil, false
+	}
+	missed := make([]*types.Event, 0, last-seq)
+	if seq == last {
+		return missed, true
+	}
+	for _, event := range history {
+		if event.Seq == 0 {
+			continue
+		}
+		if event.Seq <= seq+1 {
+			return append(missed, eventsAfter(history, seq)...), true
+		}
+		break
+	}
+	if h.Persister == nil {
+		return nil, false
+	}
+	// the history does not reach back far enough, the most recent events may not be persisted yet
+	stored, err := h.Persister.GetEventsAfterSeq(h.Room, seq, int(last-seq))
+	if err != nil {
+		globals.AppLogger.Error("could not load missed events", "error", err)
+		return nil, false
+	}
+	if len(stored) == 0 || stored[0].Seq != seq+1 {
+		return nil, false
+	}
+	missed = append(missed, stored...)
+	return append(missed, eventsAfter(history, stored[len(stored)-1].Seq)...), true
+}
+
+// eventsAfter returns the events with a sequence number greater than seq.
+func eventsAfter(events []*types.Event, seq uint64) []*types.Event {
+	after := make([]*types.Event, 0)
+	for _, event := range events {
+		if event.Seq > seq {
+			after = append(after, event)
+		}
+	}
+	return after
+}
+
+// SendSession sends a new session token for the current identity to the client. Only clients using protocol v1 receive
+// a session. Bots do not receive a session, they authenticate with their API key on each connection.
+func (c *Client) SendSession() {
+	if c.Protocol() < ProtocolV1 {
+		return
+	}
+	c.roleLock.RLock()
+	identity := c.identity
+	c.roleLock.RUnlock()
+	if identity != nil && identity.Bot != nil {
+		return
+	}
+	token, expires := c.hub.sessionToken(c.user, identity)
+	c.sendMessage(types.WireMessageTypeSession, types.SessionMessage{Token: token, Expires: expires})
+}
+
+// Resume sends the events the client missed after the event lastSeq. If they cannot be replayed, the client receives a
+// gap message followed by the history (see SendHistory).
+func (c *Client) Resume(lastSeq uint64, wg *sync.WaitGroup) {
+	events, ok := c.hub.EventsAfter(lastSeq)
+	if !ok {
+		globals.AppLogger.Debug("cannot replay missed events", "lastSeq", lastSeq)
+		c.sendMessage(types.WireMessageTypeGap, types.GapMessage{LastSeq: lastSeq, Seq: c.hub.LastSeq()})
+		c.SendHistory(c.hub.GetHistory(), wg)
+		return
+	}
+	if len(events) > 0 {
+		c.queueEvents(events)
+	}
+	if wg != nil {
+		wg.Done()
+	}
+}
+
+// randomSecret returns a random secret for signing guest cookies and session tokens if no secret is configured. It is
+// valid until the process exits.
+func randomSecret() []byte {
+	randomSecretOnce.Do(func() {
+		randomSecretValue = make([]byte, 32)
+		if _, err := rand.Read(randomSecretValue); err != nil {
+			globals.AppLogger.Error("could not generate secret", "error", err)
+		}
+	})
+	return randomSecretValue
+}
+
+// encodeSigned encodes v as "<payload>.<signature>": the JSON encoding of v and its HMAC-SHA256 signature, both
+// base64url encoded.
+func encodeSigned(v interface{}, secret []byte) string {
+	payload, _ := json.Marshal(v)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(encoded, secret))
+}
+
+// decodeSigned decodes the value encoded by encodeSigned into v. It reports whether the value is well-formed and the
+// signature matches.
+func decodeSigned(value string, secret []byte, v interface{}) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(parts[0], secret)) {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(payload, v) == nil
+}
+
+func sign(payload string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,221 @@
+package ws
+
+import (
+	"container/ring"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/persistence"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+func TestSessionToken(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &config.Config{SessionConfig: config.SessionConfig{Secret: "secret", TTL: time.Minute}}
+
+	guest := NewGuest()
+	token, expires := h.sessionToken(guest, nil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expires, time.Second)
+	session := h.ParseSession(token)
+	if assert.NotNil(t, session) {
+		assert.Nil(t, session.Identity)
+		assert.Equal(t, guest, session.Guest())
+	}
+
+	registry, err := auth.NewRegistry(&config.Config{JWTConfigs: []config.JWTConfig{{Name: "test", Secret: "jwt"}}}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	h.Auth = registry
+	identity := &auth.Identity{Provider: "test", UserId: "test:alice", Nick: "alice", RoomRoles: map[string]string{"room": types.RoleModerator},
+		TokenId: "1", ExpiresAt: time.Now().Add(time.Hour).UTC()}
+	token, _ = h.sessionToken(&types.User{Id: "test:alice", Nick: "alice"}, identity)
+	session = h.ParseSession(token)
+	if assert.NotNil(t, session) {
+		assert.Equal(t, identity, session.Identity)
+		assert.Nil(t, session.Guest(), "authenticated users are restored from the identity")
+	}
+	h.Auth = nil
+	assert.Nil(t, h.ParseSession(token), "the provider is not configured any more")
+	h.Auth = registry
+
+	other := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	other.Room = &types.Room{Id: "other"}
+	other.Cfg = h.Cfg
+	assert.Nil(t, other.ParseSession(token), "the session belongs to another room")
+	other.Room = h.Room
+	other.Cfg = &config.Config{SessionConfig: config.SessionConfig{Secret: "other"}}
+	assert.Nil(t, other.ParseSession(token), "the signature must match the secret")
+
+	expired := encodeSigned(Session{RoomId: "room", UserId: "test:alice", Identity: identity, ExpiresAt: time.Now().Unix() - 1}, []byte("secret"))
+	assert.Nil(t, h.ParseSession(expired))
+	expiredIdentity := *identity
+	expiredIdentity.ExpiresAt = time.Now().Add(-time.Second)
+	expired = encodeSigned(Session{RoomId: "room", UserId: "test:alice", Identity: &expiredIdentity, ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte("secret"))
+	assert.Nil(t, h.ParseSession(expired), "the token of the identity has expired")
+	forged := encodeSigned(Session{RoomId: "room", UserId: "admin", Nick: "admin", ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte("secret"))
+	assert.Nil(t, h.ParseSession(forged), "sessions without identity are guest sessions")
+	assert.Nil(t, h.ParseSession(token+"x"))
+}
+
+func TestSessionRevoked(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{
+		JWTConfigs:    []config.JWTConfig{{Name: "site", Secret: "jwt"}},
+		SessionConfig: config.SessionConfig{Secret: "secret", TTL: time.Hour},
+	}
+	cfg.PersistenceConfig.BuntDBConfig.GlobalName = filepath.Join(dir, "global.buntdb")
+	cfg.PersistenceConfig.BuntDBConfig.RoomNameTemplate = filepath.Join(dir, "room_{{ .RoomId }}.buntdb")
+	persister, err := persistence.NewBuntPersister(&cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer persister.Close()
+	registry, err := auth.NewRegistry(&cfg, persister)
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &cfg
+	h.Auth = registry
+
+	jwtToken, err := registry.JWTProvider("site").Mint("alice", auth.TokenClaims{Roles: map[string]string{"room": types.RoleModerator}}, 10*time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+	identity, err := registry.Authenticate(context.Background(), jwtToken, "site")
+	if !assert.NoError(t, err) {
+		return
+	}
+	token, expires := h.sessionToken(&types.User{Id: identity.UserId}, identity)
+	assert.Equal(t, identity.ExpiresAt, expires, "the session expires with the token")
+	session := h.ParseSession(token)
+	if assert.NotNil(t, session) {
+		// the renewed session of a resumed connection does not expire later either
+		_, renewed := h.sessionToken(&types.User{Id: identity.UserId}, session.Identity)
+		assert.Equal(t, identity.ExpiresAt.Unix(), renewed.Unix())
+	}
+
+	revoked, err := auth.RevokedToken(jwtToken)
+	assert.NoError(t, err)
+	assert.NoError(t, persister.RevokeToken(revoked))
+	assert.Nil(t, h.ParseSession(token), "the session of a revoked token cannot be resumed")
+}
+
+func TestSendSession(t *testing.T) {
+	c := newProtocolTestClient(ProtocolV0)
+	c.SendSession()
+	assert.Len(t, c.Send, 0, "protocol v0 has no sessions")
+
+	// a client switching to protocol v1 receives its session after the hello answer
+	c.hello("1", json.RawMessage(`{"versions": [0, 1]}`))
+	if assert.Len(t, c.Send, 2) {
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeHello, msg.Event)
+		assert.NoError(t, json.Unmarshal((<-c.Send).Data, &msg))
+		assert.Equal(t, types.WireMessageTypeSession, msg.Event)
+	}
+	c.hello("2", json.RawMessage(`{"version": 1}`))
+	assert.Len(t, c.Send, 1, "the session is only sent when the protocol changes")
+}
+
+// newSeqTestHub returns a test hub whose history keeps the last historySize events.
+func newSeqTestHub(historySize int) *Hub {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &config.Config{}
+	eventHistory := ring.New(historySize + 1)
+	h.eventHistoryStart = eventHistory
+	h.eventHistoryEnd = eventHistory
+	h.EventHistory = make(chan []*types.Event, defaultQueueSize)
+	h.BroadcastEvents = make(chan []*types.Event, defaultQueueSize)
+	return h
+}
+
+func seqs(events []*types.Event) []uint64 {
+	res := make([]uint64, 0, len(events))
+	for _, event := range events {
+		res = append(res, event.Seq)
+	}
+	return res
+}
+
+func TestEventsAfter(t *testing.T) {
+	h := newSeqTestHub(3)
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, h.handleEvents([]*types.Event{types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, nil)}))
+	}
+	assert.Equal(t, uint64(6), h.LastSeq())
+	assert.Equal(t, []uint64{4, 5, 6}, seqs(h.GetHistory()))
+
+	events, ok := h.EventsAfter(6)
+	assert.True(t, ok)
+	assert.Empty(t, events)
+	events, ok = h.EventsAfter(4)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{5, 6}, seqs(events))
+	events, ok = h.EventsAfter(3)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{4, 5, 6}, seqs(events))
+	_, ok = h.EventsAfter(2)
+	assert.False(t, ok, "the history does not reach back far enough")
+	_, ok = h.EventsAfter(7)
+	assert.False(t, ok, "unknown sequence number")
+	h.Cfg.SessionConfig.MaxReplay = 2
+	_, ok = h.EventsAfter(3)
+	assert.False(t, ok, "too many missed events")
+}
+
+func TestSeqNotBlockedBySends(t *testing.T) {
+	h := newSeqTestHub(3)
+	h.EventHistory = make(chan []*types.Event) // nobody stores the events
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, h.handleEvents([]*types.Event{types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, nil)}))
+	}()
+	assert.Eventually(t, func() bool { return h.LastSeq() == 1 }, 5*time.Second, time.Millisecond, "the sequence number is readable while the events are sent")
+	events, ok := h.EventsAfter(0)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{1}, seqs(events))
+	<-h.EventHistory
+	<-done
+}
+
+func TestEventsAfterPersisted(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{}
+	cfg.PersistenceConfig.BuntDBConfig.GlobalName = filepath.Join(dir, "global.buntdb")
+	cfg.PersistenceConfig.BuntDBConfig.RoomNameTemplate = filepath.Join(dir, "room_{{ .RoomId }}.buntdb")
+	persister, err := persistence.NewBuntPersister(&cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer persister.Close()
+
+	h := newSeqTestHub(2)
+	assert.NoError(t, persister.StoreRoom(*h.Room))
+	h.Persister = persister
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, h.handleEvents([]*types.Event{types.NewEvent(h.Room, nil, "", "en", types.EventTypeChat, nil)}))
+		// only the first three events are stored yet
+		if events := <-h.EventHistory; i < 3 {
+			assert.NoError(t, persister.StoreEvents(h.Room, events))
+		}
+	}
+
+	events, ok := h.EventsAfter(1)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{2, 3, 4, 5}, seqs(events), "stored events followed by the history")
+	assert.True(t, events[0].History)
+	events, ok = h.EventsAfter(0)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, seqs(events))
+}
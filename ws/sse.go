@@ -0,0 +1,195 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/tcriess/lightspeed-chat/globals"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+var (
+	// ErrStreamingUnsupported is returned by SSETransport if the response cannot be streamed.
+	ErrStreamingUnsupported = errors.New("streaming unsupported")
+	// ErrUnknownConnection is returned by PostMessage if there is no client with the connection id.
+	ErrUnknownConnection = errors.New("unknown connection")
+	// ErrMalformedMessage is returned by PostMessage if the message is no valid JSON.
+	ErrMalformedMessage = errors.New("malformed message")
+
+	errBinaryFrame  = errors.New("binary messages cannot be sent as Server-Sent Events")
+	errStreamClosed = errors.New("stream closed")
+)
+
+// sseTransport is the read-only Transport of a client receiving its messages as Server-Sent Events. Each message is
+// sent as the data of an event, the client posts its messages via HTTP (see Hub.PostMessage).
+type sseTransport struct {
+	// serializes the writes, the response must not be written after the handler returned (see Close)
+	sync.Mutex
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	subprotocol string
+	request     <-chan struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+	finished    bool
+}
+
+// SSETransport starts the stream of Server-Sent Events answering the request and returns its Transport. The protocol
+// version is taken from the "version" query parameter (protocol v0 by default), the encoding is always EncodingJSON.
+// Headers (like cookies) must be set before. The handler must not return before the read loop of the client has
+// ended, so that nothing is written to the response afterwards.
+func (h *Hub) SSETransport(w http.ResponseWriter, r *http.Request) (Transport, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	subprotocol := ""
+	if version, err := strconv.Atoi(r.URL.Query().Get("version")); err == nil && supportedVersion(version) {
+		subprotocol = subprotocolPrefix + strconv.Itoa(version)
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // disable the buffering of nginx
+	if origin := r.Header.Get("Origin"); origin != "" {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseTransport{
+		w:           w,
+		flusher:     flusher,
+		subprotocol: subprotocol,
+		request:     r.Context().Done(),
+		closed:      make(chan struct{}),
+	}, nil
+}
+
+// Subprotocol returns the websocket subprotocol of the requested protocol version.
+func (t *sseTransport) Subprotocol() string {
+	return t.subprotocol
+}
+
+// ReadMessage blocks until the stream is closed, the client does not send messages via the stream.
+func (t *sseTransport) ReadMessage() ([]byte, error) {
+	select {
+	case <-t.request:
+	case <-t.closed:
+	}
+	return nil, io.EOF
+}
+
+// WriteMessage sends the message as the data of an event. JSON messages do not contain line breaks, so each message
+// fits on a single data line.
+func (t *sseTransport) WriteMessage(frame Frame) error {
+	if frame.Binary {
+		return errBinaryFrame
+	}
+	return t.write("data: %s\n\n", frame.Data)
+}
+
+// Ping sends a comment, which keeps proxies from closing the idle stream and detects closed connections.
+func (t *sseTransport) Ping() error {
+	return t.write(": ping\n\n")
+}
+
+// Close ends the stream. Unless code is 0, the client receives a "close" event with the code and the reason, so that it
+// does not reconnect automatically. Close waits for a running write, nothing is written afterwards.
+func (t *sseTransport) Close(code int, reason string) error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	if code != 0 {
+		data, _ := json.Marshal(struct {
+			Code   int    `json:"code"`
+			Reason string `json:"reason"`
+		}{code, reason})
+		_ = t.write("event: close\ndata: %s\n\n", data)
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.finished = true
+	return nil
+}
+
+func (t *sseTransport) write(format string, args ...interface{}) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.finished {
+		return errStreamClosed
+	}
+	if _, err := fmt.Fprintf(t.w, format, args...); err != nil {
+		return err
+	}
+	t.flusher.Flush()
+	return nil
+}
+
+// eventStream reports whether the client is connected via Server-Sent Events.
+func (c *Client) eventStream() bool {
+	_, ok := c.conn.(*sseTransport)
+	return ok
+}
+
+// newConnectionId returns a random connection id, which is only known to the client of the connection.
+func newConnectionId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		globals.AppLogger.Error("could not generate connection id", "error", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// SendConnection sends the connection id to a client connected via Server-Sent Events, which needs it to post
+// messages. Other clients do not receive a connection message.
+func (c *Client) SendConnection() {
+	if !c.eventStream() {
+		return
+	}
+	c.sendMessage(types.WireMessageTypeConnection, types.ConnectionMessage{Id: c.id})
+}
+
+// connection returns the registered client with the connection id, nil if there is none.
+func (h *Hub) connection(id string) *Client {
+	if id == "" {
+		return nil
+	}
+	h.RLock()
+	defer h.RUnlock()
+	for client := range h.clients {
+		if client.id == id {
+			return client
+		}
+	}
+	return nil
+}
+
+// PostMessage handles a message posted via HTTP by the client with the connection id, as if the client sent it via
+// its connection: the answers (like acks and errors) are sent via the connection. The message is read from r, it is
+// limited to the maximum message size (see ErrMessageTooLarge). A malformed message closes the connection of a
+// client using protocol v0.
+func (h *Hub) PostMessage(connectionId string, r io.Reader) error {
+	c := h.connection(connectionId)
+	if c == nil {
+		return ErrUnknownConnection
+	}
+	raw, err := readLimited(r, c.cfg.MaxMessageSize)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(raw) {
+		return ErrMalformedMessage
+	}
+	if !c.handleMessage(raw) {
+		_ = c.conn.Close(websocket.CloseProtocolError, "malformed message")
+		return ErrMalformedMessage
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// newSSETestServer returns a server streaming the events of the hub, which registers the clients (without Hub.Run),
+// sends them the history and sends them to the returned channel.
+func newSSETestServer(h *Hub) (*httptest.Server, chan *Client) {
+	clients := make(chan *Client, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport, err := h.SSETransport(w, r)
+		if err != nil {
+			return
+		}
+		doneChan := make(chan struct{})
+		c := NewClient(h, transport, &types.User{Id: "test", Tags: make(map[string]string)}, nil, "en", doneChan)
+		h.Lock()
+		h.clients[c] = struct{}{}
+		h.Unlock()
+		c.Add(2)
+		go c.ReadLoop()
+		go c.WriteLoop()
+		if c.Protocol() >= ProtocolV1 {
+			c.SendHello()
+		}
+		c.SendConnection()
+		c.SendHistory(h.GetHistory(), nil)
+		clients <- c
+		<-doneChan
+	}))
+	return server, clients
+}
+
+// sseTestClient keeps a failing test from waiting for the stream forever.
+var sseTestClient = &http.Client{Timeout: 10 * time.Second}
+
+// sseEvent is an event of the stream, the comments are skipped.
+type sseEvent struct {
+	name string
+	data string
+}
+
+func readSSEEvent(r *bufio.Reader) (sseEvent, error) {
+	event := sseEvent{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event.data != "" {
+				return event, nil
+			}
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestSSETransport(t *testing.T) {
+	room := &types.Room{Id: "room"}
+	history := []*types.Event{types.NewEvent(room, nil, "", "en", types.EventTypeChat, map[string]string{"message": "earlier"})}
+	h := newTestHub(map[string]plugins.PluginSpec{}, history)
+	h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{MaxMessageSize: 128}}
+	server, clients := newSSETestServer(h)
+	defer server.Close()
+
+	resp, err := sseTestClient.Get(server.URL + "?version=1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	c := <-clients
+	stream := bufio.NewReader(resp.Body)
+	next := func() types.WebsocketMessage {
+		msg := types.WebsocketMessage{}
+		event, err := readSSEEvent(stream)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal([]byte(event.data), &msg))
+		return msg
+	}
+
+	// the history is sent independently of the other messages
+	received := make(map[string]json.RawMessage)
+	for i := 0; i < 3; i++ {
+		msg := next()
+		received[msg.Event] = msg.Data
+	}
+	assert.Contains(t, string(received[types.WireMessageTypeHello]), `"encodings":["json"]`)
+	connection := types.ConnectionMessage{}
+	assert.NoError(t, json.Unmarshal(received[types.WireMessageTypeConnection], &connection))
+	assert.Equal(t, c.id, connection.Id)
+	assert.Contains(t, string(received[types.WireMessageTypeBatch]), "earlier", "the history is replayed")
+
+	post := func(connectionId, message string) error {
+		return h.PostMessage(connectionId, strings.NewReader(message))
+	}
+	assert.NoError(t, post(connection.Id, `{"event":"hello","id":"1","data":{"versions":[1],"encoding":"protobuf"}}`))
+	msg := next()
+	assert.Equal(t, types.WireMessageTypeHello, msg.Event, "the answer is sent via the stream")
+	assert.Equal(t, "1", msg.Id)
+	assert.Equal(t, EncodingJSON, c.Encoding(), "binary messages cannot be streamed")
+
+	assert.Equal(t, ErrUnknownConnection, post("unknown", `{"event":"hello","data":{}}`))
+	assert.Equal(t, ErrMessageTooLarge, post(connection.Id, `{"event":"chat","data":{"message":"`+strings.Repeat("x", 128)+`"}}`))
+	assert.Equal(t, ErrMalformedMessage, post(connection.Id, `{"event":`))
+	assert.NoError(t, post(connection.Id, `{"event":"login","id":"2","data":[]}`))
+	msg = next()
+	assert.Equal(t, types.WireMessageTypeError, msg.Event, "protocol v1 clients receive an error")
+	assert.Equal(t, "2", msg.Id)
+
+	c.disconnectSlow()
+	event, err := readSSEEvent(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "close", event.name)
+	assert.Contains(t, event.data, `"code":4000`)
+	_, err = readSSEEvent(stream)
+	assert.Error(t, err, "the stream ends")
+}
+
+func TestSSETransportV0(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	server, clients := newSSETestServer(h)
+	defer server.Close()
+
+	resp, err := sseTestClient.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	c := <-clients
+	assert.Equal(t, ProtocolV0, c.Protocol())
+	stream := bufio.NewReader(resp.Body)
+	event, err := readSSEEvent(stream)
+	assert.NoError(t, err)
+	assert.Contains(t, event.data, types.WireMessageTypeConnection)
+
+	assert.Equal(t, ErrMalformedMessage, h.PostMessage(c.id, strings.NewReader(`{"event":"login","data":[]}`)))
+	event, err = readSSEEvent(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "close", event.name, "a malformed message closes the stream")
+	assert.Contains(t, event.data, fmt.Sprintf(`"code":%d`, websocket.CloseProtocolError))
+}
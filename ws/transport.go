@@ -0,0 +1,23 @@
+package ws
+
+// Transport is the connection of a client, a websocket connection (see Hub.WebsocketTransport) or a read-only stream
+// of Server-Sent Events (see Hub.SSETransport). ReadMessage is only called by ReadLoop, WriteMessage and Ping only by
+// WriteLoop, Close may be called concurrently and more than once.
+type Transport interface {
+	// ReadMessage blocks until the next message of the client arrives. It returns ErrMessageTooLarge if the message
+	// exceeds the maximum message size, the transport remains usable, any other error ends the connection.
+	ReadMessage() ([]byte, error)
+	// WriteMessage sends the message to the client.
+	WriteMessage(frame Frame) error
+	// Ping checks that the client is still connected.
+	Ping() error
+	// Close closes the connection, telling the client the reason unless code is 0 (the codes are websocket close
+	// codes).
+	Close(code int, reason string) error
+}
+
+// subprotocolTransport is implemented by transports which negotiate the protocol version and the encoding when the
+// client connects (see Subprotocols).
+type subprotocolTransport interface {
+	Subprotocol() string
+}
@@ -0,0 +1,204 @@
+package ws
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/globals"
+)
+
+const (
+	defaultMaxMessageSize   = 4096
+	defaultPongWait         = 2 * time.Minute
+	defaultPingPeriod       = time.Minute
+	defaultWriteWait        = 10 * time.Second
+	defaultQueueSize        = 1000
+	defaultCompressionLevel = 1
+
+	// readLimitFactor limits the messages which are discarded if they exceed the maximum message size to
+	// readLimitFactor times the maximum message size, the connection is closed if a message is even larger.
+	readLimitFactor = 16
+
+	// closeWait is the time to wait for sending the close message, the connection of a slow client is most likely
+	// congested.
+	closeWait = time.Second
+
+	// RoomAllowedOriginsTag is the room tag overriding the allowed origins of the configuration, a comma separated
+	// list.
+	RoomAllowedOriginsTag = "_allowed_origins"
+)
+
+// ErrMessageTooLarge is returned if a message of a client exceeds the maximum message size.
+var ErrMessageTooLarge = errors.New("message too large")
+
+// websocketConfig returns the websocket configuration, with the defaults for missing values.
+func (h *Hub) websocketConfig() config.WebsocketConfig {
+	cfg := config.WebsocketConfig{}
+	if h.Cfg != nil {
+		cfg = h.Cfg.WebsocketConfig
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = defaultPongWait
+	}
+	if cfg.PingPeriod <= 0 {
+		cfg.PingPeriod = defaultPingPeriod
+	}
+	if cfg.PingPeriod >= cfg.PongWait {
+		// the pong must arrive before the read deadline
+		cfg.PingPeriod = cfg.PongWait * 9 / 10
+	}
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = defaultWriteWait
+	}
+	for _, size := range []*int{&cfg.SendQueueSize, &cfg.PluginQueueSize, &cfg.BroadcastQueueSize, &cfg.HistoryQueueSize} {
+		if *size <= 0 {
+			*size = defaultQueueSize
+		}
+	}
+	if cfg.CompressionLevel == 0 {
+		cfg.CompressionLevel = defaultCompressionLevel
+	}
+	return cfg
+}
+
+// Upgrader returns the websocket upgrader for the room, which checks the origin of the request, negotiates the
+// protocol version (see Subprotocols) and enables compression if configured.
+func (h *Hub) Upgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		CheckOrigin:       func(r *http.Request) bool { return h.AllowsOrigin(r.Header.Get("Origin")) },
+		Subprotocols:      Subprotocols(),
+		EnableCompression: h.websocketConfig().Compression,
+	}
+}
+
+// AllowsOrigin reports whether a browser from the origin may connect to the room. The allowed origins are taken from
+// the room tag "_allowed_origins" or the configuration, if there are none, all origins are allowed. An allowed origin
+// is either "*", an origin like "https://example.com" or an origin with a wildcard subdomain like
+// "https://*.example.com". Requests without an origin (which are not sent by browsers) are always allowed.
+func (h *Hub) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	allowed := h.websocketConfig().AllowedOrigins
+	if h.Room != nil {
+		if v, ok := h.roomTags()[RoomAllowedOriginsTag]; ok {
+			allowed = strings.Split(v, ",")
+		}
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "*" || a == origin {
+			return true
+		}
+		if i := strings.Index(a, "://*."); i >= 0 {
+			scheme, domain := a[:i+3], a[i+4:]
+			if strings.HasPrefix(origin, scheme) && strings.HasSuffix(origin, domain) && len(origin) > len(scheme)+len(domain) {
+				return true
+			}
+		}
+	}
+	globals.AppLogger.Info("origin not allowed", "room", h.Room.Id, "origin", origin)
+	return false
+}
+
+// websocketTransport is the Transport of a websocket connection.
+type websocketTransport struct {
+	conn *websocket.Conn
+	cfg  config.WebsocketConfig
+}
+
+// WebsocketTransport returns the Transport of the websocket connection, which is configured for the room: the read
+// limit, the read deadline extended by the pong messages and the compression level.
+func (h *Hub) WebsocketTransport(conn *websocket.Conn) Transport {
+	cfg := h.websocketConfig()
+	conn.SetReadLimit(readLimitFactor * cfg.MaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(cfg.PongWait)) })
+	if cfg.Compression {
+		if err := conn.SetCompressionLevel(cfg.CompressionLevel); err != nil {
+			globals.AppLogger.Warn("invalid compression level", "level", cfg.CompressionLevel, "error", err)
+		}
+	}
+	return &websocketTransport{conn: conn, cfg: cfg}
+}
+
+// Subprotocol returns the negotiated websocket subprotocol.
+func (t *websocketTransport) Subprotocol() string {
+	return t.conn.Subprotocol()
+}
+
+// ReadMessage reads the next message from the websocket connection. A message exceeding the maximum message size is
+// discarded and ErrMessageTooLarge is returned, so that the client can be told about it. If a message exceeds the
+// read limit (see readLimitFactor), the connection is closed.
+func (t *websocketTransport) ReadMessage() ([]byte, error) {
+	_, r, err := t.conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+	return readLimited(r, t.cfg.MaxMessageSize)
+}
+
+// readLimited reads a message of at most max bytes. A larger message is discarded and ErrMessageTooLarge is returned.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	raw, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) > max {
+		_, err = io.Copy(ioutil.Discard, r)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrMessageTooLarge
+	}
+	return raw, nil
+}
+
+// WriteMessage writes the message as a text or binary message, messages of at least the compression threshold are
+// compressed if compression is enabled.
+func (t *websocketTransport) WriteMessage(frame Frame) error {
+	_ = t.conn.SetWriteDeadline(time.Now().Add(t.cfg.WriteWait))
+	t.conn.EnableWriteCompression(t.cfg.Compression && len(frame.Data) >= t.cfg.CompressionThreshold)
+	messageType := websocket.TextMessage
+	if frame.Binary {
+		messageType = websocket.BinaryMessage
+	}
+	w, err := t.conn.NextWriter(messageType)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(frame.Data)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Ping sends a ping message, the pong extends the read deadline.
+func (t *websocketTransport) Ping() error {
+	_ = t.conn.SetWriteDeadline(time.Now().Add(t.cfg.WriteWait))
+	return t.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
+// Close sends the close message (unless code is 0) and closes the connection.
+func (t *websocketTransport) Close(code int, reason string) error {
+	if code != 0 {
+		msg := websocket.FormatCloseMessage(code, reason)
+		_ = t.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeWait))
+	}
+	return t.conn.Close()
+}
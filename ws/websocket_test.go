@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/tcriess/lightspeed-chat/config"
+	"github.com/tcriess/lightspeed-chat/plugins"
+	"github.com/tcriess/lightspeed-chat/types"
+)
+
+// newTestServer returns a websocket server for the hub, which registers the clients (without Hub.Run) and sends them
+// to the returned channel.
+func newTestServer(h *Hub) (*httptest.Server, chan *Client) {
+	clients := make(chan *Client, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := h.Upgrader().Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		doneChan := make(chan struct{})
+		c := NewClient(h, h.WebsocketTransport(conn), &types.User{Id: "test", Tags: make(map[string]string)}, nil, "en", doneChan)
+		h.Lock()
+		h.clients[c] = struct{}{}
+		h.Unlock()
+		c.Add(2)
+		go c.ReadLoop()
+		go c.WriteLoop()
+		clients <- c
+		<-doneChan
+	}))
+	return server, clients
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/"
+}
+
+func TestWebsocketConfig(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	cfg := h.websocketConfig()
+	assert.Equal(t, int64(defaultMaxMessageSize), cfg.MaxMessageSize)
+	assert.Equal(t, defaultPingPeriod, cfg.PingPeriod)
+	assert.Equal(t, defaultQueueSize, cfg.SendQueueSize)
+
+	h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{PongWait: 10 * time.Second, SendQueueSize: 5}}
+	cfg = h.websocketConfig()
+	assert.Equal(t, 9*time.Second, cfg.PingPeriod, "the ping is sent before the pong is due")
+	assert.Equal(t, 5, cfg.SendQueueSize)
+	assert.Equal(t, defaultQueueSize, cfg.PluginQueueSize)
+}
+
+func TestAllowsOrigin(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	assert.True(t, h.AllowsOrigin("https://anywhere.com"), "all origins are allowed by default")
+
+	h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{AllowedOrigins: []string{"https://example.com", "https://*.example.org"}}}
+	assert.True(t, h.AllowsOrigin("https://EXAMPLE.com"))
+	assert.True(t, h.AllowsOrigin("https://chat.example.org"))
+	assert.True(t, h.AllowsOrigin(""), "requests without origin are allowed")
+	assert.False(t, h.AllowsOrigin("http://example.com"))
+	assert.False(t, h.AllowsOrigin("https://example.org"))
+	assert.False(t, h.AllowsOrigin("https://evilexample.org"))
+
+	h.Room.Tags = map[string]string{RoomAllowedOriginsTag: "https://stream.tv, https://obs.local"}
+	assert.True(t, h.AllowsOrigin("https://obs.local"))
+	assert.False(t, h.AllowsOrigin("https://example.com"), "the room overrides the configuration")
+
+	server, _ := newTestServer(h)
+	defer server.Close()
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL(server), http.Header{"Origin": []string{"https://example.com"}})
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	}
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{MaxMessageSize: 64}}
+	server, clients := newTestServer(h)
+	defer server.Close()
+	dialer := &websocket.Dialer{Subprotocols: []string{"lightspeed-chat.v1"}}
+	conn, _, err := dialer.Dial(wsURL(server), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	<-clients
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	large := `{"event":"chat","id":"1","data":{"message":"` + strings.Repeat("x", 100) + `"}}`
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(large)))
+	msg := types.WebsocketMessage{}
+	assert.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, types.WireMessageTypeError, msg.Event)
+	errorMsg := types.ErrorMessage{}
+	assert.NoError(t, json.Unmarshal(msg.Data, &errorMsg))
+	assert.Equal(t, errorMessageTooLarge, errorMsg.Code)
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"hello","id":"2","data":{"versions":[1]}}`)))
+	assert.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, types.WireMessageTypeHello, msg.Event, "the connection is still open")
+	assert.Equal(t, "2", msg.Id)
+
+	huge := strings.Repeat("x", readLimitFactor*64+1)
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(huge)))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "the connection is closed: %v", err)
+}
+
+func TestCompression(t *testing.T) {
+	h := newTestHub(map[string]plugins.PluginSpec{}, nil)
+	h.Cfg = &config.Config{WebsocketConfig: config.WebsocketConfig{Compression: true, CompressionThreshold: 100}}
+	server, clients := newTestServer(h)
+	defer server.Close()
+	dialer := &websocket.Dialer{EnableCompression: true}
+	conn, resp, err := dialer.Dial(wsURL(server), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Contains(t, resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+	c := <-clients
+
+	c.sendMessage(types.WireMessageTypeGap, types.GapMessage{LastSeq: 1, Seq: 2})
+	c.sendNotice(strings.Repeat("compressed ", 100))
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	// the messages and the events are sent independently
+	received := make(map[string]json.RawMessage)
+	for i := 0; i < 2; i++ {
+		msg := types.WebsocketMessage{}
+		assert.NoError(t, conn.ReadJSON(&msg))
+		received[msg.Event] = msg.Data
+	}
+	assert.Contains(t, received, types.WireMessageTypeGap)
+	assert.Contains(t, string(received[types.WireMessageTypeChats]), "compressed compressed")
+}
@@ -0,0 +1,151 @@
+package wstest
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tcriess/lightspeed-chat/auth"
+	"github.com/tcriess/lightspeed-chat/types"
+	"github.com/tcriess/lightspeed-chat/ws"
+)
+
+// ErrTimeout is returned if no message arrives in time.
+var ErrTimeout = errors.New("timeout")
+
+// Client is a client connected to a hub via a Transport.
+type Client struct {
+	*ws.Client
+	Transport *Transport
+
+	done chan struct{}
+}
+
+// Connect connects a client of the user to the hub, which must be running (see ws.Hub.Run), the way the chat server
+// connects a websocket client: the client is registered, receives the hello message (protocol v1) and the history.
+// identity is the result of the authentication, nil for guests. The client is unregistered once the transport is
+// closed (see Disconnect).
+func Connect(hub *ws.Hub, user *types.User, identity *auth.Identity, subprotocol string) *Client {
+	transport := NewTransport(subprotocol)
+	done := make(chan struct{})
+	c := &Client{
+		Client:    ws.NewClient(hub, transport, user, identity, user.Language, done),
+		Transport: transport,
+		done:      done,
+	}
+	go c.PluginLoop()
+	c.Add(1)
+	hub.Register <- c.Client
+	c.Wait()
+	c.Add(2)
+	go c.ReadLoop()
+	go c.WriteLoop()
+	if c.Protocol() >= ws.ProtocolV1 {
+		c.SendHello()
+	}
+	c.SendHistory(hub.GetHistory(), nil)
+	go func() {
+		<-done
+		hub.Unregister <- c.Client
+	}()
+	return c
+}
+
+// Disconnect closes the transport and waits until the read loop has ended, the hub unregisters the client.
+func (c *Client) Disconnect() {
+	_ = c.Transport.Close(0, "")
+	<-c.done
+}
+
+// SendMessage sends a message of the client, id is the request id (protocol v1).
+func (c *Client) SendMessage(event, id string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal(types.WebsocketMessage{Event: event, Id: id, Data: raw})
+	if err != nil {
+		return err
+	}
+	return c.Transport.Send(message)
+}
+
+// Chat sends a chat message (or command), filter is the target filter.
+func (c *Client) Chat(message, filter string) error {
+	return c.SendMessage(types.WireMessageTypeChat, "", types.ChatMessage{Message: message, Filter: filter})
+}
+
+// Next returns the next JSON message written to the client.
+func (c *Client) Next(timeout time.Duration) (types.WebsocketMessage, error) {
+	msg := types.WebsocketMessage{}
+	select {
+	case frame := <-c.Transport.Messages():
+		if frame.Binary {
+			return msg, errors.New("binary message")
+		}
+		err := json.Unmarshal(frame.Data, &msg)
+		return msg, err
+	case <-time.After(timeout):
+		return msg, ErrTimeout
+	}
+}
+
+// serverMessages are the messages of the server which do not contain events.
+var serverMessages = map[string]struct{}{
+	types.WireMessageTypeHello:      {},
+	types.WireMessageTypeError:      {},
+	types.WireMessageTypeAck:        {},
+	types.WireMessageTypeSession:    {},
+	types.WireMessageTypeGap:        {},
+	types.WireMessageTypeConnection: {},
+}
+
+// NextEvents returns the events of the next message containing events: a broadcast event, a batch (protocol v1) or a
+// message per event type like "chats" (protocol v0). Other messages are skipped.
+func (c *Client) NextEvents(timeout time.Duration) ([]*types.Event, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		msg, err := c.Next(time.Until(deadline))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := serverMessages[msg.Event]; ok {
+			continue
+		}
+		wireEvents := []types.WebsocketMessage{msg}
+		if strings.HasPrefix(string(msg.Data), "[") {
+			wireEvents = wireEvents[:0]
+			if err := json.Unmarshal(msg.Data, &wireEvents); err != nil {
+				return nil, err
+			}
+		}
+		events := make([]*types.Event, 0, len(wireEvents))
+		for _, wireEvent := range wireEvents {
+			event := &types.Event{}
+			if err := json.Unmarshal(wireEvent.Data, event); err != nil {
+				return nil, err
+			}
+			// the name is only sent as the event of the message
+			event.Name = wireEvent.Event
+			events = append(events, event)
+		}
+		return events, nil
+	}
+}
+
+// NextEvent returns the next event with the name (f.e. types.EventTypeChat), other events are skipped.
+func (c *Client) NextEvent(name string, timeout time.Duration) (*types.Event, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		events, err := c.NextEvents(time.Until(deadline))
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if event.Name == name {
+				return event, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package wstest connects clients to a hub in-process for tests: the clients use an in-memory Transport instead of a
+// websocket connection.
+package wstest
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/tcriess/lightspeed-chat/ws"
+)
+
+// messageQueueSize is the number of messages buffered in each direction, writing blocks once the queue is full (like
+// a congested connection).
+const messageQueueSize = 1000
+
+// ErrClosed is returned when sending via a closed Transport.
+var ErrClosed = errors.New("transport closed")
+
+// Transport is an in-memory ws.Transport. The test sends the messages of the client with Send and receives the
+// messages written to the client from Messages.
+type Transport struct {
+	subprotocol string
+	incoming    chan []byte
+	outgoing    chan ws.Frame
+	pings       chan struct{}
+	closed      chan struct{}
+
+	closeLock sync.Mutex
+	code      int
+	reason    string
+}
+
+// NewTransport creates a Transport, subprotocol is the negotiated websocket subprotocol (see ws.Subprotocols), the
+// empty string for protocol v0.
+func NewTransport(subprotocol string) *Transport {
+	return &Transport{
+		subprotocol: subprotocol,
+		incoming:    make(chan []byte, messageQueueSize),
+		outgoing:    make(chan ws.Frame, messageQueueSize),
+		pings:       make(chan struct{}, 1),
+		closed:      make(chan struct{}),
+	}
+}
+
+// Subprotocol returns the negotiated websocket subprotocol.
+func (t *Transport) Subprotocol() string {
+	return t.subprotocol
+}
+
+// ReadMessage returns the next message sent with Send, io.EOF once the transport is closed.
+func (t *Transport) ReadMessage() ([]byte, error) {
+	select {
+	case message := <-t.incoming:
+		return message, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+// WriteMessage passes the message on to Messages, it blocks while the queue is full.
+func (t *Transport) WriteMessage(frame ws.Frame) error {
+	select {
+	case <-t.closed:
+		return ErrClosed
+	default:
+	}
+	select {
+	case t.outgoing <- frame:
+		return nil
+	case <-t.closed:
+		return ErrClosed
+	}
+}
+
+// Ping records the ping (see Pinged).
+func (t *Transport) Ping() error {
+	select {
+	case <-t.closed:
+		return ErrClosed
+	case t.pings <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close closes the transport, the first close code and reason are kept (see CloseCode).
+func (t *Transport) Close(code int, reason string) error {
+	t.closeLock.Lock()
+	defer t.closeLock.Unlock()
+	select {
+	case <-t.closed:
+		return nil
+	default:
+	}
+	t.code = code
+	t.reason = reason
+	close(t.closed)
+	return nil
+}
+
+// Send passes a message of the client to the hub.
+func (t *Transport) Send(message []byte) error {
+	select {
+	case <-t.closed:
+		return ErrClosed
+	default:
+	}
+	select {
+	case t.incoming <- message:
+		return nil
+	case <-t.closed:
+		return ErrClosed
+	}
+}
+
+// Messages returns the messages written to the client.
+func (t *Transport) Messages() <-chan ws.Frame {
+	return t.outgoing
+}
+
+// Pinged is ready if the client was pinged since the last receive.
+func (t *Transport) Pinged() <-chan struct{} {
+	return t.pings
+}
+
+// Closed is closed once the transport is closed.
+func (t *Transport) Closed() <-chan struct{} {
+	return t.closed
+}
+
+// CloseCode returns the close code and reason passed to Close.
+func (t *Transport) CloseCode() (int, string) {
+	t.closeLock.Lock()
+	defer t.closeLock.Unlock()
+	return t.code, t.reason
+}
+
+var _ ws.Transport = &Transport{}